@@ -0,0 +1,162 @@
+package reporting
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/Stone-IT-Cloud/reporting/pkg/gitcontributors"
+	"github.com/Stone-IT-Cloud/reporting/pkg/gitlogs"
+)
+
+// BundleSchemaVersion is the value of Bundle.SchemaVersion. It is bumped whenever a field
+// is renamed, removed or changes meaning; new fields may be added without a bump.
+const BundleSchemaVersion = 1
+
+// Bundle is the full dataset for one repository in a single document, for loading into a
+// notebook or warehouse. Its JSON tags are part of the schema and stay stable.
+type Bundle struct {
+	SchemaVersion int                 `json:"schema_version"`
+	GeneratedAt   time.Time           `json:"generated_at"`
+	Repository    BundleRepository    `json:"repository"`
+	Contributors  []BundleContributor `json:"contributors"`
+	Commits       []gitlogs.LogEntry  `json:"commits"`
+	// PullRequests are the GitHub pull requests merged with a merge commit, as recorded in
+	// the git history. Squash and rebase merges leave no trace and are not listed.
+	PullRequests []BundlePullRequest `json:"pull_requests"`
+}
+
+// BundleRepository describes the exported repository and the date range of the export.
+type BundleRepository struct {
+	Name      string     `json:"name"` // "owner/repo" when known, see gitlogs.RepositoryName
+	Path      string     `json:"path"` // Absolute path of the working tree
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+}
+
+// BundleContributor is a gitcontributors.Contributor with snake_case JSON tags.
+type BundleContributor struct {
+	Name              string    `json:"name"`
+	Email             string    `json:"email"`
+	Commits           int       `json:"commits"`
+	FirstCommitDate   time.Time `json:"first_commit_date"`
+	LastCommitDate    time.Time `json:"last_commit_date"`
+	LinesAdded        int       `json:"lines_added"`
+	LinesDeleted      int       `json:"lines_deleted"`
+	LinesChanged      int       `json:"lines_changed"`
+	ContributionScore float64   `json:"contribution_score,omitempty"`
+}
+
+// BundlePullRequest is a merged pull request, parsed from its merge commit.
+type BundlePullRequest struct {
+	Number       int       `json:"number"`
+	Title        string    `json:"title"`
+	SourceBranch string    `json:"source_branch"`
+	MergedAt     time.Time `json:"merged_at"`
+	MergedBy     string    `json:"merged_by"` // Author email of the merge commit
+	Author       string    `json:"author"`    // Author email of the merged branch tip
+}
+
+// ExportBundle collects the repository metadata, contributors, commits and merged pull
+// requests of the repository at repoPath into one Bundle. logOpts selects the commits and
+// pull requests (its MergedPRsOnly is ignored) and contributorOpts the contributors; either
+// may be nil for the whole repository. GeneratedAt is taken from logOpts.Now when set.
+func ExportBundle(repoPath string, logOpts *gitlogs.Options, contributorOpts *gitcontributors.Options) (Bundle, error) {
+	if logOpts == nil {
+		logOpts = &gitlogs.Options{}
+	}
+	absRepoPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("bundle export failed to resolve repository path: %w", err)
+	}
+	name, err := gitlogs.RepositoryName(repoPath)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("bundle export failed during repository lookup: %w", err)
+	}
+	now := time.Now
+	if logOpts.Now != nil {
+		now = logOpts.Now
+	}
+	bundle := Bundle{
+		SchemaVersion: BundleSchemaVersion,
+		GeneratedAt:   now().UTC(),
+		Repository: BundleRepository{
+			Name:      name,
+			Path:      absRepoPath,
+			StartDate: logOpts.StartDate,
+			EndDate:   logOpts.EndDate,
+		},
+		Contributors: []BundleContributor{}, // "[]" rather than "null"
+		PullRequests: []BundlePullRequest{},
+	}
+
+	contributors, err := gitcontributors.GetContributors(repoPath, contributorOpts)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("bundle export failed during contributor retrieval: %w", err)
+	}
+	for _, c := range contributors {
+		bundle.Contributors = append(bundle.Contributors, BundleContributor{
+			Name:              c.Name,
+			Email:             c.Email,
+			Commits:           c.Commits,
+			FirstCommitDate:   c.FirstCommitDate,
+			LastCommitDate:    c.LastCommitDate,
+			LinesAdded:        c.LinesAdded,
+			LinesDeleted:      c.LinesDeleted,
+			LinesChanged:      c.LinesChanged,
+			ContributionScore: c.ContributionScore,
+		})
+	}
+
+	commitOpts := *logOpts
+	commitOpts.MergedPRsOnly = false
+	if bundle.Commits, err = bundleLogEntries(repoPath, &commitOpts); err != nil {
+		return Bundle{}, fmt.Errorf("bundle export failed during git log retrieval: %w", err)
+	}
+
+	prOpts := *logOpts
+	prOpts.MergedPRsOnly = true
+	merges, err := bundleLogEntries(repoPath, &prOpts)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("bundle export failed during pull request retrieval: %w", err)
+	}
+	for _, m := range merges {
+		if m.PullRequest == nil {
+			continue
+		}
+		bundle.PullRequests = append(bundle.PullRequests, BundlePullRequest{
+			Number:       m.PullRequest.Number,
+			Title:        m.PullRequest.Title,
+			SourceBranch: m.PullRequest.SourceBranch,
+			MergedAt:     m.CommitDateTime,
+			MergedBy:     m.AuthorEmail,
+			Author:       m.PullRequest.AuthorEmail,
+		})
+	}
+	return bundle, nil
+}
+
+// bundleLogEntries returns the entries of gitlogs.GetLogsJSON, never nil.
+func bundleLogEntries(repoPath string, opts *gitlogs.Options) ([]gitlogs.LogEntry, error) {
+	logsJSON, err := gitlogs.GetLogsJSON(repoPath, opts)
+	if err != nil {
+		return nil, err
+	}
+	entries := []gitlogs.LogEntry{}
+	if err := json.Unmarshal([]byte(logsJSON), &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse git logs: %w", err)
+	}
+	if entries == nil {
+		entries = []gitlogs.LogEntry{}
+	}
+	return entries, nil
+}
+
+// WriteBundleJSON writes bundle to w as one indented JSON document.
+func WriteBundleJSON(w io.Writer, bundle Bundle) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(bundle)
+}
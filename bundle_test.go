@@ -0,0 +1,171 @@
+package reporting_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Stone-IT-Cloud/reporting"
+	"github.com/Stone-IT-Cloud/reporting/pkg/gitlogs"
+)
+
+// git runs a git command in repo with the author and committer set to name, email and date.
+func git(t *testing.T, repo, name, email, date string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+name, "GIT_AUTHOR_EMAIL="+email, "GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_NAME="+name, "GIT_COMMITTER_EMAIL="+email, "GIT_COMMITTER_DATE="+date,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\nOutput:\n%s", args, err, output)
+	}
+}
+
+// commitFile writes content to file in repo and commits it.
+func commitFile(t *testing.T, repo, file, content, message, name, email, date string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(repo, file), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", file, err)
+	}
+	git(t, repo, name, email, date, "add", file)
+	git(t, repo, name, email, date, "commit", "-m", message)
+}
+
+// setupBundleRepo returns a repository with a commit on main and a pull request
+// merged from a feature branch, all in October 2023.
+func setupBundleRepo(t *testing.T) string {
+	t.Helper()
+	repo := t.TempDir()
+	const alice, aliceEmail, bob, bobEmail = "Alice", "alice@example.com", "Bob", "bob@example.com"
+	git(t, repo, alice, aliceEmail, "2023-10-01T10:00:00Z", "init", "-b", "main")
+	commitFile(t, repo, "main.txt", "one\ntwo\n", "Add main", alice, aliceEmail, "2023-10-01T10:00:00Z")
+	git(t, repo, bob, bobEmail, "2023-10-02T10:00:00Z", "checkout", "-b", "feature")
+	commitFile(t, repo, "feature.txt", "feature\n", "Add feature", bob, bobEmail, "2023-10-02T10:00:00Z")
+	git(t, repo, alice, aliceEmail, "2023-10-03T10:00:00Z", "checkout", "main")
+	git(t, repo, alice, aliceEmail, "2023-10-03T10:00:00Z", "merge", "--no-ff", "-m", "Merge pull request #5 from bob/feature\n\nAdd the feature", "feature")
+	return repo
+}
+
+func TestExportBundle(t *testing.T) {
+	repo := setupBundleRepo(t)
+	start := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	generated := time.Date(2024, 1, 15, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	now := func() time.Time { return generated }
+	bundle, err := reporting.ExportBundle(repo, &gitlogs.Options{StartDate: &start, LinkPullRequests: true, Now: now}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := reporting.WriteBundleJSON(&buf, bundle); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to unmarshal bundle JSON: %v", err)
+	}
+
+	var keys []string
+	for k := range doc {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	wantKeys := []string{"commits", "contributors", "generated_at", "pull_requests", "repository", "schema_version"}
+	if !reflect.DeepEqual(keys, wantKeys) {
+		t.Errorf("Expected top-level keys %v, got %v", wantKeys, keys)
+	}
+	if doc["generated_at"] != "2024-01-15T08:30:00Z" {
+		t.Errorf("Expected generated_at 2024-01-15T08:30:00Z from the injected clock, got %v", doc["generated_at"])
+	}
+	if doc["schema_version"] != float64(reporting.BundleSchemaVersion) {
+		t.Errorf("Expected schema_version %d, got %v", reporting.BundleSchemaVersion, doc["schema_version"])
+	}
+
+	repository, _ := doc["repository"].(map[string]interface{})
+	absRepo, _ := filepath.Abs(repo)
+	if repository["path"] != absRepo || repository["name"] != filepath.Base(repo) {
+		t.Errorf("Unexpected repository %v", repository)
+	}
+	if repository["start_date"] != "2023-10-01T00:00:00Z" {
+		t.Errorf("Expected start_date 2023-10-01T00:00:00Z, got %v", repository["start_date"])
+	}
+	if _, ok := repository["end_date"]; ok {
+		t.Errorf("Expected no end_date, got %v", repository["end_date"])
+	}
+
+	contributors, _ := doc["contributors"].([]interface{})
+	if len(contributors) != 2 {
+		t.Fatalf("Expected 2 contributors, got %d: %v", len(contributors), contributors)
+	}
+	for _, c := range contributors {
+		contributor, _ := c.(map[string]interface{})
+		for _, key := range []string{"name", "email", "commits", "first_commit_date", "last_commit_date", "lines_added", "lines_deleted", "lines_changed"} {
+			if _, ok := contributor[key]; !ok {
+				t.Errorf("Expected contributor key %q in %v", key, contributor)
+			}
+		}
+	}
+
+	commits, _ := doc["commits"].([]interface{})
+	if len(commits) != 2 { // Merge commits are excluded by default
+		t.Fatalf("Expected 2 commits, got %d: %v", len(commits), commits)
+	}
+	for _, c := range commits {
+		commit, _ := c.(map[string]interface{})
+		if hash, _ := commit["commit_hash"].(string); len(hash) != 40 {
+			t.Errorf("Expected a full commit_hash, got %v", commit["commit_hash"])
+		}
+	}
+	feature, _ := commits[1].(map[string]interface{})
+	if feature["commit_message"] != "Add feature" || feature["pull_request_number"] != float64(5) {
+		t.Errorf("Expected the feature commit linked to pull request 5, got %v", feature)
+	}
+
+	wantPRs := []interface{}{map[string]interface{}{
+		"number":        float64(5),
+		"title":         "Add the feature",
+		"source_branch": "bob/feature",
+		"merged_at":     "2023-10-03T10:00:00Z",
+		"merged_by":     "alice@example.com",
+		"author":        "bob@example.com",
+	}}
+	if !reflect.DeepEqual(doc["pull_requests"], wantPRs) {
+		t.Errorf("Mismatch:\nExpected: %v\nActual:   %v", wantPRs, doc["pull_requests"])
+	}
+}
+
+func TestExportBundleEmptyRange(t *testing.T) {
+	repo := setupBundleRepo(t)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bundle, err := reporting.ExportBundle(repo, &gitlogs.Options{StartDate: &start}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var buf bytes.Buffer
+	if err := reporting.WriteBundleJSON(&buf, bundle); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to unmarshal bundle JSON: %v", err)
+	}
+	for _, key := range []string{"commits", "pull_requests"} {
+		if string(doc[key]) != "[]" {
+			t.Errorf("Expected %s to be an empty array, got %s", key, doc[key])
+		}
+	}
+}
+
+func TestExportBundleInvalidRepository(t *testing.T) {
+	if _, err := reporting.ExportBundle(filepath.Join(t.TempDir(), "missing"), nil, nil); err == nil {
+		t.Fatal("Expected an error for a missing repository, got nil")
+	}
+}
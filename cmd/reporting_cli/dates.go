@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// now is the clock used to resolve relative dates; overridable for testing.
+var now = time.Now
+
+// weekdays maps lowercase weekday names to time.Weekday for "last-<weekday>" expressions.
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// parseDateFlag parses a -start/-end value, accepting either the strict dateLayout
+// or a relative expression understood by parseRelativeDate.
+func parseDateFlag(s string) (*time.Time, error) {
+	parsedDate, err := time.ParseInLocation(dateLayout, s, time.Local)
+	if err == nil {
+		return &parsedDate, nil
+	}
+	relative, relErr := parseRelativeDate(s)
+	if relErr != nil {
+		return nil, fmt.Errorf("expected %s or a relative date: %w", dateLayout, relErr)
+	}
+	return relative, nil
+}
+
+// parseRelativeDate resolves a relative date expression against the current day and
+// returns local midnight of the resulting day. Supported forms:
+//   - "today", "yesterday"
+//   - "<N>d", "<N>w", "<N>m", "<N>y": N days, weeks, months or years ago
+//   - "last-<weekday>": the most recent such weekday strictly before today, e.g. "last-monday"
+func parseRelativeDate(s string) (*time.Time, error) {
+	expr := strings.ToLower(strings.TrimSpace(s))
+	current := now().In(time.Local)
+	today := time.Date(current.Year(), current.Month(), current.Day(), 0, 0, 0, 0, time.Local)
+
+	var resolved time.Time
+	switch {
+	case expr == "today":
+		resolved = today
+	case expr == "yesterday":
+		resolved = today.AddDate(0, 0, -1)
+	case strings.HasPrefix(expr, "last-"):
+		weekday, ok := weekdays[strings.TrimPrefix(expr, "last-")]
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday in %q", s)
+		}
+		daysBack := (int(today.Weekday()) - int(weekday) + 7) % 7
+		if daysBack == 0 {
+			daysBack = 7
+		}
+		resolved = today.AddDate(0, 0, -daysBack)
+	case len(expr) >= 2:
+		amount, err := strconv.Atoi(expr[:len(expr)-1])
+		if err != nil || amount < 0 {
+			return nil, fmt.Errorf("invalid relative date %q", s)
+		}
+		switch expr[len(expr)-1] {
+		case 'd':
+			resolved = today.AddDate(0, 0, -amount)
+		case 'w':
+			resolved = today.AddDate(0, 0, -7*amount)
+		case 'm':
+			resolved = today.AddDate(0, -amount, 0)
+		case 'y':
+			resolved = today.AddDate(-amount, 0, 0)
+		default:
+			return nil, fmt.Errorf("unknown unit in relative date %q (use d, w, m or y)", s)
+		}
+	default:
+		return nil, fmt.Errorf("invalid relative date %q", s)
+	}
+	return &resolved, nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// setNow makes now return reference for the rest of the test.
+func setNow(t *testing.T, reference time.Time) {
+	t.Helper()
+	original := now
+	now = func() time.Time { return reference }
+	t.Cleanup(func() { now = original })
+}
+
+// day returns local midnight of the given day.
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.Local)
+}
+
+func TestParseRelativeDate(t *testing.T) {
+	setNow(t, time.Date(2024, 3, 13, 15, 30, 0, 0, time.Local)) // A Wednesday
+	testCases := []struct {
+		expr    string
+		want    time.Time
+		wantErr bool
+	}{
+		{expr: "today", want: day(2024, 3, 13)},
+		{expr: "yesterday", want: day(2024, 3, 12)},
+		{expr: "0d", want: day(2024, 3, 13)},
+		{expr: "7d", want: day(2024, 3, 6)},
+		{expr: "13d", want: day(2024, 2, 29)}, // Across a leap day
+		{expr: "2w", want: day(2024, 2, 28)},
+		{expr: "1m", want: day(2024, 2, 13)},
+		{expr: "12m", want: day(2023, 3, 13)},
+		{expr: "1y", want: day(2023, 3, 13)},
+		{expr: " 3D ", want: day(2024, 3, 10)}, // Case and surrounding spaces are ignored
+		{expr: "last-monday", want: day(2024, 3, 11)},
+		{expr: "last-tuesday", want: day(2024, 3, 12)},
+		{expr: "last-wednesday", want: day(2024, 3, 6)}, // Strictly before today
+		{expr: "last-thursday", want: day(2024, 3, 7)},
+		{expr: "Last-Sunday", want: day(2024, 3, 10)},
+		{expr: "", wantErr: true},
+		{expr: "d", wantErr: true},
+		{expr: "-1d", wantErr: true},
+		{expr: "1.5d", wantErr: true},
+		{expr: "5x", wantErr: true},
+		{expr: "tomorrow", wantErr: true},
+		{expr: "last-funday", wantErr: true},
+		{expr: "last-", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			got, err := parseRelativeDate(tc.expr)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error %t, got %v", tc.wantErr, err)
+			}
+			if err == nil && !got.Equal(tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseRelativeDateReferenceTime(t *testing.T) {
+	testCases := []struct {
+		name      string
+		reference time.Time
+		expr      string
+		want      time.Time
+	}{
+		{"start of the day", time.Date(2024, 3, 13, 0, 0, 0, 0, time.Local), "1d", day(2024, 3, 12)},
+		{"end of the day", time.Date(2024, 3, 13, 23, 59, 59, 0, time.Local), "1d", day(2024, 3, 12)},
+		{"across a year", time.Date(2024, 1, 2, 12, 0, 0, 0, time.Local), "1w", day(2023, 12, 26)},
+		{"weekday across a month", time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local), "last-monday", day(2024, 2, 26)},
+		{"month on the last day", time.Date(2024, 5, 31, 12, 0, 0, 0, time.Local), "1m", day(2024, 5, 1)}, // April 31 normalizes to May 1
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setNow(t, tc.reference)
+			got, err := parseRelativeDate(tc.expr)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !got.Equal(tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseDateFlag(t *testing.T) {
+	setNow(t, time.Date(2024, 3, 13, 15, 30, 0, 0, time.Local))
+	testCases := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2024-01-02", want: day(2024, 1, 2)},
+		{value: "7d", want: day(2024, 3, 6)},
+		{value: "2024-13-01", wantErr: true},
+		{value: "02/01/2024", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := parseDateFlag(tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error %t, got %v", tc.wantErr, err)
+			}
+			if err == nil && !got.Equal(tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -2,13 +2,21 @@ package main
 
 import (
 	"context" // Import context
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/Stone-IT-Cloud/reporting"
 	gc "github.com/Stone-IT-Cloud/reporting/pkg/gitcontributors"
 	gl "github.com/Stone-IT-Cloud/reporting/pkg/gitlogs"
 
@@ -18,30 +26,82 @@ import (
 
 const dateLayout = "2006-01-02"
 
+// Exit codes returned by run so wrapping scripts can tell failure types apart.
+const (
+	exitOK       = 0
+	exitUsage    = 2 // Invalid flags, arguments or configuration
+	exitGit      = 3 // Repository path or git command failures
+	exitProvider = 4 // Missing or rejected provider/AI credentials
+	exitLLM      = 5 // Failures while generating the AI report
+)
+
 func main() {
+	os.Exit(run(os.Args))
+}
+
+// run executes the CLI with args (program name first) and returns the process exit code.
+func run(args []string) int {
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
 	// --- Flags ---
 	// Existing flags
-	includeMerges := flag.Bool("m", false, "Contributor report: Include merge commits")
-	getLogsFlag := flag.Bool("log", false, "Generate git log JSON report") // Renamed for clarity
-	startDateStr := flag.String("start", "", fmt.Sprintf("Start date filter (inclusive), format %s", dateLayout))
-	endDateStr := flag.String("end", "", fmt.Sprintf("End date filter (inclusive), format %s", dateLayout))
+	includeMerges := flags.Bool("m", false, "Include merge commits (contributor, log and AI reports)")
+	getLogsFlag := flags.Bool("log", false, "Generate git log JSON report") // Renamed for clarity
+	startDateStr := flags.String("start", "", fmt.Sprintf("Start date filter (inclusive), format %s or relative (7d, 2w, 1m, last-monday)", dateLayout))
+	endDateStr := flags.String("end", "", fmt.Sprintf("End date filter (inclusive), format %s or relative (7d, 2w, 1m, last-monday)", dateLayout))
+	gzipOutput := flags.Bool("gzip", false, "Log report: Write the JSON to stdout as a gzip stream")
+	cacheDir := flags.String("cache-dir", "", "Directory to cache parsed git logs between runs (disabled if empty)")
+	fullNames := flags.Bool("full", false, "Contributor report: Do not truncate long names/emails to the terminal width")
+	formatFlag := flags.String("format", formatTable, "Contributor report: Output format: table, json or csv")
+	redactEmails := flags.Bool("redact-emails", false, "Mask email addresses (a***@example.com) in all output, including data sent to the AI model")
+	pathsFlag := flags.String("paths", "", "Comma-separated paths to scope commits to (e.g. web/,docs/)")
+	excludePathsFlag := flags.String("exclude-paths", "", "Comma-separated paths to leave out")
+	authorsFlag := flags.String("authors", "", "Log/AI report: Comma-separated author names or emails to scope commits to (case-insensitive)")
+	excludeAuthorsFlag := flags.String("exclude-authors", "", "Log/AI report: Comma-separated author names or emails to leave out (e.g. dependabot[bot])")
+	bundleFlag := flags.Bool("bundle", false, "Export repository metadata, contributors, commits and merged pull requests as one JSON document")
+	feedAddr := flags.String("feed", "", "Serve new commits as a live NDJSON/server-sent-events feed on this address (host:port, or unix:/path/to.sock)")
+	feedInterval := flags.Duration("feed-interval", 5*time.Second, "Feed: How often to poll the repository for new commits")
+	versionFlag := flags.Bool("version", false, "Print the version, commit and build date, then exit")
 
 	// --- ★★★ New flag for Activity Report ★★★ ---
-	generateReportFlag := flag.Bool("generate-report", false, "Generate AI activity report from git logs")
-	configPath := flag.String("config", "configs/activity_report_config.yaml", "Path to activity report config file")
-	reportPath := flag.String("report-path", "", "Path to save the generated AI activity report")
+	generateReportFlag := flags.Bool("generate-report", false, "Generate AI activity report from git logs")
+	configPath := flags.String("config", "configs/activity_report_config.yaml", "Path to activity report config file")
+	reportPath := flags.String("report-path", "", "Path to save the generated AI activity report; supports {repo}, {start}, {end} and {date} placeholders")
 
-	flag.Parse()
+	if err := flags.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage // The flag package has printed the error and usage
+	}
+
+	if *versionFlag {
+		fmt.Println(versionString())
+		return exitOK
+	}
 
 	// --- Validate Arguments ---
-	if flag.NArg() != 1 {
+	if flags.NArg() > 1 {
 		// ... (Usage info identical to before, potentially mention new flags) ...
-		fmt.Fprintf(os.Stderr, "Usage: %s [options] <path-to-git-repo>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] [path-to-git-repo]\n", args[0])
+		fmt.Fprintf(os.Stderr, "The repository defaults to the current directory.\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
-		flag.PrintDefaults()
-		os.Exit(1)
+		flags.PrintDefaults()
+		return exitUsage
+	}
+	repoPath := flags.Arg(0)
+	if repoPath == "" {
+		root, ok := workTreeRoot(".")
+		if !ok {
+			log.Print("Error: the current directory is not inside a git repository; run from a repository or pass its path as an argument.")
+			return exitGit
+		}
+		repoPath = root // The libraries expect the top level, not a subdirectory
+	}
+
+	if err := validateFormat(*formatFlag); err != nil {
+		log.Printf("Error: %v", err)
+		return exitUsage
 	}
-	repoPath := flag.Arg(0)
 
 	// Determine mutually exclusive actions
 	actionCount := 0
@@ -51,29 +111,37 @@ func main() {
 	if *generateReportFlag {
 		actionCount++
 	}
-	// If neither log nor generate-report is specified, default to contributors
+	if *feedAddr != "" {
+		actionCount++
+	}
+	if *bundleFlag {
+		actionCount++
+	}
+	// If neither log, generate-report, feed nor bundle is specified, default to contributors
 	isContributorReport := actionCount == 0
 	if actionCount > 1 {
-		log.Fatal("Error: -log and -generate-report flags are mutually exclusive.")
+		log.Print("Error: -log, -generate-report, -feed and -bundle flags are mutually exclusive.")
+		return exitUsage
 	}
 
 	// --- Parse Dates ---
 	var startDate, endDate *time.Time
 	// ... (Date parsing identical to before) ...
 	if *startDateStr != "" {
-		parsedDate, err := time.ParseInLocation(dateLayout, *startDateStr, time.Local)
+		parsedDate, err := parseDateFlag(*startDateStr)
 		if err != nil {
-			log.Fatalf("Error parsing start date %q: %v", *startDateStr, err)
+			log.Printf("Error parsing start date %q: %v", *startDateStr, err)
+			return exitUsage
 		}
-		startDate = &parsedDate
+		startDate = parsedDate
 	}
 	if *endDateStr != "" {
-		parsedDate, err := time.ParseInLocation(dateLayout, *endDateStr, time.Local)
+		parsedDate, err := parseDateFlag(*endDateStr)
 		if err != nil {
-			log.Fatalf("Error parsing end date %q: %v", *endDateStr, err)
+			log.Printf("Error parsing end date %q: %v", *endDateStr, err)
+			return exitUsage
 		}
-		endOfDay := parsedDate.Add(24*time.Hour - time.Nanosecond)
-		endDate = &endOfDay
+		endDate = parsedDate // The libraries extend it to end of day via InclusiveEndDate
 	}
 
 	// --- Execute requested action ---
@@ -82,42 +150,76 @@ func main() {
 	switch {
 	case *getLogsFlag:
 		// --- Generate Log Report (JSON) ---
-		logOpts := &gl.Options{StartDate: startDate, EndDate: endDate}
-		fmt.Printf("Generating Git Log JSON for %s", repoPath)
-		if logOpts.StartDate != nil {
-			fmt.Printf(" from %s", logOpts.StartDate.Format(dateLayout))
+		logOpts := &gl.Options{IncludeMergeCommits: *includeMerges, StartDate: startDate, EndDate: endDate, InclusiveEndDate: true, CacheDir: *cacheDir, Paths: splitList(*pathsFlag), ExcludePaths: splitList(*excludePathsFlag), Authors: splitList(*authorsFlag), ExcludeAuthors: splitList(*excludeAuthorsFlag), RedactEmails: *redactEmails}
+		// With -gzip, stdout carries only the compressed stream, so the header goes to stderr.
+		header := os.Stdout
+		if *gzipOutput {
+			header = os.Stderr
 		}
-		if logOpts.EndDate != nil {
-			fmt.Printf(" until %s", *endDateStr)
+		fmt.Fprintln(header, logHeader(repoPath, logOpts))
+		if *gzipOutput {
+			if err := gl.GetLogsJSONGzip(repoPath, logOpts, os.Stdout); err != nil {
+				log.Printf("Error getting git logs: %v", err)
+				return exitGit
+			}
+			break
 		}
-		fmt.Println(" (excluding merges, all branches, chronological):")
 		logJSON, err := gl.GetLogsJSON(repoPath, logOpts) // Renamed logJson to logJSON
 		if err != nil {
-			log.Fatalf("Error getting git logs: %v", err)
+			log.Printf("Error getting git logs: %v", err)
+			return exitGit
 		}
 		fmt.Println(logJSON) // Use logJSON
 
 	case *generateReportFlag:
 		// --- ★★★ Generate AI Activity Report ★★★ ---
 		log.Println("Step 1: Fetching Git Logs for AI Report...")
-		logOpts := &gl.Options{StartDate: startDate, EndDate: endDate}
+		logOpts := &gl.Options{IncludeMergeCommits: *includeMerges, StartDate: startDate, EndDate: endDate, InclusiveEndDate: true, CacheDir: *cacheDir, Paths: splitList(*pathsFlag), ExcludePaths: splitList(*excludePathsFlag), Authors: splitList(*authorsFlag), ExcludeAuthors: splitList(*excludeAuthorsFlag), RedactEmails: *redactEmails, LinkPullRequests: true}
 		gitLogsJSON, err := gl.GetLogsJSON(repoPath, logOpts)
 		if err != nil {
-			log.Fatalf("Error getting git logs for AI report generation: %v", err)
+			log.Printf("Error getting git logs for AI report generation: %v", err)
+			return exitGit
 		}
 		log.Println("Step 1: Git Logs Fetched.")
+		warnHistoryRewrites(repoPath, logOpts)
 
 		log.Println("Step 2: Generating AI Activity Report...")
 
-		err = ar.GenerateReport(ctx, gitLogsJSON, *configPath, *reportPath)
+		resolvedReportPath := expandReportPath(*reportPath, repoPath, startDate, endDate)
+		result, err := ar.GenerateReport(ctx, gitLogsJSON, *configPath, resolvedReportPath, repoPath)
 		if err != nil {
-			log.Fatalf("Error generating AI activity report: %v", err)
+			log.Printf("Error generating AI activity report: %v", err)
+			return reportExitCode(err)
 		}
+		log.Printf("Step 2: %d of %d commits sent to the model.", result.SentCommits, result.TotalCommits)
+		log.Printf("Step 2: Inputs fingerprint %s.", result.InputsFingerprint)
 		log.Println("Step 2: AI Activity Report Generation Finished.")
 
+	case *feedAddr != "":
+		// --- Serve Live Commit Feed ---
+		logOpts := &gl.Options{IncludeMergeCommits: *includeMerges, StartDate: startDate, EndDate: endDate, InclusiveEndDate: true, Paths: splitList(*pathsFlag), ExcludePaths: splitList(*excludePathsFlag), Authors: splitList(*authorsFlag), ExcludeAuthors: splitList(*excludeAuthorsFlag), RedactEmails: *redactEmails}
+		if err := serveFeed(ctx, *feedAddr, gl.FeedHandler(repoPath, logOpts, *feedInterval)); err != nil {
+			log.Printf("Error serving commit feed: %v", err)
+			return exitGit
+		}
+
+	case *bundleFlag:
+		// --- Export Full Dataset (JSON) ---
+		logOpts := &gl.Options{IncludeMergeCommits: *includeMerges, StartDate: startDate, EndDate: endDate, InclusiveEndDate: true, CacheDir: *cacheDir, Paths: splitList(*pathsFlag), ExcludePaths: splitList(*excludePathsFlag), Authors: splitList(*authorsFlag), ExcludeAuthors: splitList(*excludeAuthorsFlag), RedactEmails: *redactEmails, LinkPullRequests: true, Now: now}
+		contributorOpts := &gc.Options{IncludeMergeCommits: *includeMerges, StartDate: startDate, EndDate: endDate, InclusiveEndDate: true, Paths: splitList(*pathsFlag), ExcludePaths: splitList(*excludePathsFlag), RedactEmails: *redactEmails}
+		bundle, err := reporting.ExportBundle(repoPath, logOpts, contributorOpts)
+		if err != nil {
+			log.Printf("Error exporting bundle: %v", err)
+			return exitGit
+		}
+		if err := reporting.WriteBundleJSON(os.Stdout, bundle); err != nil {
+			log.Printf("Error writing bundle: %v", err)
+			return exitGit
+		}
+
 	case isContributorReport: // Default case when no other flag is set
 		// --- Generate Contributor Report (Default Action) ---
-		contributorOpts := &gc.Options{IncludeMergeCommits: *includeMerges, StartDate: startDate, EndDate: endDate}
+		contributorOpts := &gc.Options{IncludeMergeCommits: *includeMerges, StartDate: startDate, EndDate: endDate, InclusiveEndDate: true, Paths: splitList(*pathsFlag), ExcludePaths: splitList(*excludePathsFlag), RedactEmails: *redactEmails}
 		var filterDesc []string
 		if contributorOpts.IncludeMergeCommits {
 			filterDesc = append(filterDesc, "Including Merges")
@@ -128,40 +230,186 @@ func main() {
 			filterDesc = append(filterDesc, fmt.Sprintf("From %s", contributorOpts.StartDate.Format(dateLayout)))
 		}
 		if contributorOpts.EndDate != nil {
-			filterDesc = append(filterDesc, fmt.Sprintf("Until %s", *endDateStr))
+			filterDesc = append(filterDesc, fmt.Sprintf("Until %s", contributorOpts.EndDate.Format(dateLayout)))
 		}
 		filterDesc = append(filterDesc, "Sorted by Name/Email")
-		fmt.Printf("Contributors for %s (%s):\n", repoPath, strings.Join(filterDesc, ", "))
+		if *formatFlag == formatTable { // JSON and CSV output is kept machine-readable
+			fmt.Printf("Contributors for %s (%s):\n", repoPath, strings.Join(filterDesc, ", "))
+		}
 		contributors, err := gc.GetContributors(repoPath, contributorOpts)
 		if err != nil {
-			log.Fatalf("Error getting contributors: %v", err)
+			log.Printf("Error getting contributors: %v", err)
+			return exitGit
+		}
+		switch *formatFlag {
+		case formatJSON:
+			err = writeContributorsJSON(os.Stdout, contributors)
+		case formatCSV:
+			err = writeContributorsCSV(os.Stdout, contributors)
+		default:
+			printContributors(contributors, *fullNames)
+		}
+		if err != nil {
+			log.Printf("Error writing contributors: %v", err)
+			return exitGit
+		}
+	}
+	return exitOK
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
-		printContributors(contributors)
 	}
+	return items
 }
 
-// printContributors helper function (using gc.Contributor type)
-func printContributors(contributors []gc.Contributor) {
+// logHeader describes the log -log is about to print, as selected by opts.
+func logHeader(repoPath string, opts *gl.Options) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Generating Git Log JSON for %s", repoPath)
+	if opts.StartDate != nil {
+		fmt.Fprintf(&b, " from %s", opts.StartDate.Format(dateLayout))
+	}
+	if opts.EndDate != nil {
+		fmt.Fprintf(&b, " until %s", opts.EndDate.Format(dateLayout))
+	}
+	var details []string
+	if opts.IncludeMergeCommits {
+		details = append(details, "including merges")
+	} else {
+		details = append(details, "excluding merges")
+	}
+	if opts.NotOnBranch != "" {
+		details = append(details, "HEAD not on "+opts.NotOnBranch)
+	} else {
+		details = append(details, "all branches")
+	}
+	if opts.Order == gl.OrderReverseChronological {
+		details = append(details, "newest first")
+	} else {
+		details = append(details, "chronological")
+	}
+	if len(opts.Paths) > 0 {
+		details = append(details, "paths "+strings.Join(opts.Paths, " "))
+	}
+	if len(opts.ExcludePaths) > 0 {
+		details = append(details, "excluding paths "+strings.Join(opts.ExcludePaths, " "))
+	}
+	if len(opts.Authors) > 0 {
+		details = append(details, "authors "+strings.Join(opts.Authors, " "))
+	}
+	if len(opts.ExcludeAuthors) > 0 {
+		details = append(details, "excluding authors "+strings.Join(opts.ExcludeAuthors, " "))
+	}
+	if opts.RedactEmails {
+		details = append(details, "emails redacted")
+	}
+	fmt.Fprintf(&b, " (%s):", strings.Join(details, ", "))
+	return b.String()
+}
+
+// reportExitCode maps an activity report error to the matching exit code.
+func reportExitCode(err error) int {
+	switch {
+	case errors.Is(err, ar.ErrConfig):
+		return exitUsage
+	case errors.Is(err, ar.ErrNoCredentials), errors.Is(err, ar.ErrAuthRejected):
+		return exitProvider
+	default:
+		return exitLLM
+	}
+}
+
+// printContributors helper function (using gc.Contributor type).
+// Unless full is set, the name/email column is ellipsized to fit the terminal width.
+func printContributors(contributors []gc.Contributor, full bool) {
 	// ... (implementation identical to previous version) ...
 	if len(contributors) == 0 {
 		fmt.Println("  No contributors found (or repository is empty/filtered out).")
 		return
 	}
-	maxWidth := 0
+	maxWidth, addedWidth, deletedWidth := len("Commits"), len("Added"), len("Deleted")
 	for _, c := range contributors {
-		lenStr := fmt.Sprintf("%d", c.Commits)
-		if len(lenStr) > maxWidth {
-			maxWidth = len(lenStr)
-		}
+		maxWidth = max(maxWidth, len(strconv.Itoa(c.Commits)))
+		addedWidth = max(addedWidth, len("+"+strconv.Itoa(c.LinesAdded)))
+		deletedWidth = max(deletedWidth, len("-"+strconv.Itoa(c.LinesDeleted)))
 	}
-	headerWidth := len("Commits")
-	if maxWidth < headerWidth {
-		maxWidth = headerWidth
+	// "  " + count + " | " + added + " | " + deleted + " | " + date + " | " + date + " | " precedes the name column.
+	nameWidth := terminalWidth() - (2 + maxWidth + 3 + addedWidth + 3 + deletedWidth + 3 + 12 + 3 + 12 + 3)
+	if nameWidth < minNameColumnWidth {
+		nameWidth = minNameColumnWidth
 	}
-	countFormat := fmt.Sprintf("%%%dd", maxWidth)
-	fmt.Println("  Commits | First Commit | Last Commit  | Name & Email")
-	fmt.Printf("  %-"+fmt.Sprintf("%d", maxWidth)+"s | %-10s | %-10s | %s\n", strings.Repeat("-", maxWidth), strings.Repeat("-", 10), strings.Repeat("-", 10), strings.Repeat("-", 20))
+	fmt.Printf("  %*s | %*s | %*s | First Commit | Last Commit  | Name & Email\n", maxWidth, "Commits", addedWidth, "Added", deletedWidth, "Deleted")
+	fmt.Printf("  %s | %s | %s | %s | %s | %s\n", strings.Repeat("-", maxWidth), strings.Repeat("-", addedWidth), strings.Repeat("-", deletedWidth), strings.Repeat("-", 12), strings.Repeat("-", 12), strings.Repeat("-", 20))
 	for _, c := range contributors {
-		fmt.Printf("  "+countFormat+" | %s | %s | %s <%s>\n", c.Commits, c.FirstCommitDate.Format(dateLayout), c.LastCommitDate.Format(dateLayout), c.Name, c.Email)
+		identity := fmt.Sprintf("%s <%s>", c.Name, c.Email)
+		if !full {
+			identity = truncateToWidth(identity, nameWidth)
+		}
+		fmt.Printf("  %*d | %*s | %*s | %-12s | %-12s | %s\n", maxWidth, c.Commits,
+			addedWidth, "+"+strconv.Itoa(c.LinesAdded), deletedWidth, "-"+strconv.Itoa(c.LinesDeleted),
+			c.FirstCommitDate.Format(dateLayout), c.LastCommitDate.Format(dateLayout), identity)
+	}
+}
+
+// serveFeed serves handler on addr (host:port, or unix:<socket path>) until an interrupt
+// or termination signal arrives, then ends the open streams and shuts down.
+func serveFeed(ctx context.Context, addr string, handler http.Handler) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	network := "tcp"
+	if socketPath, ok := strings.CutPrefix(addr, "unix:"); ok {
+		network, addr = "unix", socketPath
+	}
+	listener, err := net.Listen(network, addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	// Request contexts derive from ctx, so the signal also ends every open stream.
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- server.Serve(listener) }()
+	log.Printf("Serving commit feed on %s %s (Ctrl+C to stop)", network, addr)
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// workTreeRoot returns the top-level directory of the git working tree containing path,
+// and false if path is not inside one.
+func workTreeRoot(path string) (string, bool) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = path
+	out, err := cmd.Output()
+	root := strings.TrimSpace(string(out))
+	return root, err == nil && root != ""
+}
+
+// warnHistoryRewrites logs an advisory warning when the reflog shows rewritten history
+// inside the report window, since that can make commit counts differ between runs.
+func warnHistoryRewrites(repoPath string, logOpts *gl.Options) {
+	rewrites, err := gl.DetectHistoryRewrites(repoPath, logOpts)
+	if err != nil {
+		log.Printf("Warning: could not inspect reflog for history rewrites: %v", err)
+		return
+	}
+	for _, rw := range rewrites {
+		log.Printf("Warning: history of %s was rewritten at %s (%s); report may not match earlier runs.", rw.Ref, rw.Time.Format(time.RFC3339), rw.Action)
 	}
 }
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	ar "github.com/Stone-IT-Cloud/reporting/internal/activityreport"
+	gl "github.com/Stone-IT-Cloud/reporting/pkg/gitlogs"
+)
+
+// setupRepo returns a git repository holding one commit that adds a file.
+func setupRepo(t *testing.T) string {
+	t.Helper()
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("# Test\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"add", "README.md"},
+		{"-c", "user.name=Alice", "-c", "user.email=alice@example.com", "commit", "-m", "Initial commit"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\nOutput:\n%s", args, err, output)
+		}
+	}
+	return repo
+}
+
+// writeConfig writes an activity report configuration file and returns its path.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	repo := setupRepo(t)
+	t.Setenv("OPENAI_API_KEY", "")
+	openAIConfig := writeConfig(t, "provider: openai\nmodel: gpt-test\nchunk_size: 10\n")
+	reportPath := filepath.Join(t.TempDir(), "report.md")
+
+	testCases := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"version", []string{"-version"}, exitOK},
+		{"help", []string{"-h"}, exitOK},
+		{"contributors", []string{repo}, exitOK},
+		{"contributors as json", []string{"-format", "json", repo}, exitOK},
+		{"log", []string{"-log", repo}, exitOK},
+		{"unknown flag", []string{"-no-such-flag", repo}, exitUsage},
+		{"two repositories", []string{repo, repo}, exitUsage},
+		{"exclusive actions", []string{"-log", "-bundle", repo}, exitUsage},
+		{"invalid format", []string{"-format", "xml", repo}, exitUsage},
+		{"invalid start date", []string{"-start", "someday", repo}, exitUsage},
+		{"missing repository", []string{"-log", filepath.Join(t.TempDir(), "missing")}, exitGit},
+		{"missing config", []string{"-generate-report", "-config", filepath.Join(t.TempDir(), "missing.yaml"), "-report-path", reportPath, repo}, exitUsage},
+		{"missing credentials", []string{"-generate-report", "-config", openAIConfig, "-report-path", reportPath, repo}, exitProvider},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := run(append([]string{"reporting_cli"}, tc.args...)); got != tc.want {
+				t.Errorf("Expected exit code %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRunDefaultRepository(t *testing.T) {
+	repo := setupRepo(t)
+	sub := filepath.Join(repo, "docs", "guides")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	testCases := []struct {
+		name string
+		dir  string
+		args []string
+		want int
+	}{
+		{"top level", repo, nil, exitOK},
+		{"subdirectory", sub, nil, exitOK},
+		{"log from a subdirectory", sub, []string{"-log"}, exitOK},
+		{"outside a repository", t.TempDir(), []string{"-log"}, exitGit},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Chdir(tc.dir)
+			if got := run(append([]string{"reporting_cli"}, tc.args...)); got != tc.want {
+				t.Errorf("Expected exit code %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestReportExitCode(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"invalid configuration", fmt.Errorf("%w: chunk_size must be positive", ar.ErrConfig), exitUsage},
+		{"missing credentials", fmt.Errorf("%w: OPENAI_API_KEY env var not set", ar.ErrNoCredentials), exitProvider},
+		{"rejected credentials", fmt.Errorf("failed to send chunk 1/1 to OpenAI: %w", ar.ErrAuthRejected), exitProvider},
+		{"rate limited", fmt.Errorf("failed to send chunk 1/1 to OpenAI: %w", ar.ErrRateLimited), exitLLM},
+		{"provider unavailable", fmt.Errorf("failed to send chunk 1/1 to OpenAI: %w", ar.ErrProviderUnavailable), exitLLM},
+		{"other failure", errors.New("failed to write report"), exitLLM},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := reportExitCode(tc.err); got != tc.want {
+				t.Errorf("Expected exit code %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLogHeader(t *testing.T) {
+	start, end := day(2024, 3, 1), day(2024, 3, 8)
+	testCases := []struct {
+		name string
+		opts *gl.Options
+		want string
+	}{
+		{"defaults", &gl.Options{}, "Generating Git Log JSON for repo (excluding merges, all branches, chronological):"},
+		{"date range", &gl.Options{StartDate: &start, EndDate: &end}, "Generating Git Log JSON for repo from 2024-03-01 until 2024-03-08 (excluding merges, all branches, chronological):"},
+		{"merges", &gl.Options{IncludeMergeCommits: true}, "Generating Git Log JSON for repo (including merges, all branches, chronological):"},
+		{"branch and order", &gl.Options{NotOnBranch: "main", Order: gl.OrderReverseChronological}, "Generating Git Log JSON for repo (excluding merges, HEAD not on main, newest first):"},
+		{
+			"filters",
+			&gl.Options{Paths: []string{"web/", "api/"}, ExcludePaths: []string{"web/dist/"}, Authors: []string{"alice"}, ExcludeAuthors: []string{"dependabot[bot]"}, RedactEmails: true},
+			"Generating Git Log JSON for repo (excluding merges, all branches, chronological, paths web/ api/, excluding paths web/dist/, authors alice, excluding authors dependabot[bot], emails redacted):",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := logHeader("repo", tc.opts); got != tc.want {
+				t.Errorf("Mismatch:\nExpected: %q\nActual:   %q", tc.want, got)
+			}
+		})
+	}
+}
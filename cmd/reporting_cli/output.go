@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	gc "github.com/Stone-IT-Cloud/reporting/pkg/gitcontributors"
+)
+
+// Contributor report formats accepted by -format.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// validateFormat checks the -format flag value.
+func validateFormat(format string) error {
+	switch format {
+	case formatTable, formatJSON, formatCSV:
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q (supported: %s, %s, %s)", format, formatTable, formatJSON, formatCSV)
+	}
+}
+
+// writeContributorsJSON writes contributors as an indented JSON array.
+func writeContributorsJSON(w io.Writer, contributors []gc.Contributor) error {
+	if contributors == nil {
+		contributors = []gc.Contributor{} // "[]" rather than "null"
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(contributors)
+}
+
+// writeContributorsCSV writes a header row and one row per contributor, with dates as
+// YYYY-MM-DD.
+func writeContributorsCSV(w io.Writer, contributors []gc.Contributor) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"name", "email", "commits", "lines_added", "lines_deleted", "first_commit", "last_commit"}); err != nil {
+		return err
+	}
+	for _, c := range contributors {
+		row := []string{
+			c.Name,
+			c.Email,
+			strconv.Itoa(c.Commits),
+			strconv.Itoa(c.LinesAdded),
+			strconv.Itoa(c.LinesDeleted),
+			c.FirstCommitDate.Format(dateLayout),
+			c.LastCommitDate.Format(dateLayout),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gc "github.com/Stone-IT-Cloud/reporting/pkg/gitcontributors"
+)
+
+// outputTestContributors returns contributors whose names need quoting in CSV.
+func outputTestContributors() []gc.Contributor {
+	return []gc.Contributor{
+		{
+			Name: "Doe, Jane", Email: "jane@example.com", Commits: 3, LinesAdded: 120, LinesDeleted: 7, LinesChanged: 127,
+			FirstCommitDate: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC),
+			LastCommitDate:  time.Date(2024, 3, 8, 23, 0, 0, 0, time.FixedZone("EST", -5*3600)), // March 9 in UTC
+		},
+		{
+			Name: `Bob "The Builder" Smith`, Email: "bob@example.com", Commits: 1, LinesAdded: 2, LinesChanged: 2,
+			FirstCommitDate: time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC),
+			LastCommitDate:  time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+func TestWriteContributorsJSON(t *testing.T) {
+	contributors := outputTestContributors()
+	var buf bytes.Buffer
+	if err := writeContributorsJSON(&buf, contributors); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "[\n  {\n    \"Name\": \"Doe, Jane\",") || !strings.HasSuffix(buf.String(), "]\n") {
+		t.Errorf("Expected an indented JSON array, got:\n%s", buf.String())
+	}
+	var decoded []gc.Contributor
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal output: %v", err)
+	}
+	if len(decoded) != len(contributors) {
+		t.Fatalf("Expected %d contributors, got %d", len(contributors), len(decoded))
+	}
+	for i := range contributors {
+		want, got := contributors[i], decoded[i]
+		if got.Name != want.Name || got.Email != want.Email || got.Commits != want.Commits || got.LinesChanged != want.LinesChanged ||
+			!got.FirstCommitDate.Equal(want.FirstCommitDate) || !got.LastCommitDate.Equal(want.LastCommitDate) {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	}
+}
+
+func TestWriteContributorsJSONEmpty(t *testing.T) {
+	for _, contributors := range [][]gc.Contributor{nil, {}} {
+		var buf bytes.Buffer
+		if err := writeContributorsJSON(&buf, contributors); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if buf.String() != "[]\n" {
+			t.Errorf("Expected an empty array for %#v, got %q", contributors, buf.String())
+		}
+	}
+}
+
+func TestWriteContributorsCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeContributorsCSV(&buf, outputTestContributors()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	wantOutput := "name,email,commits,lines_added,lines_deleted,first_commit,last_commit\n" +
+		`"Doe, Jane",jane@example.com,3,120,7,2024-03-01,2024-03-08` + "\n" +
+		`"Bob ""The Builder"" Smith",bob@example.com,1,2,0,2024-03-05,2024-03-05` + "\n"
+	if buf.String() != wantOutput {
+		t.Errorf("Expected:\n%s\nGot:\n%s", wantOutput, buf.String())
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	names := []string{records[1][0], records[2][0]}
+	if want := []string{"Doe, Jane", `Bob "The Builder" Smith`}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected names %q to round-trip, got %q", want, names)
+	}
+}
+
+func TestWriteContributorsCSVEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeContributorsCSV(&buf, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if want := "name,email,commits,lines_added,lines_deleted,first_commit,last_commit\n"; buf.String() != want {
+		t.Errorf("Expected only the header, got %q", buf.String())
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestWriteContributorsWriteError(t *testing.T) {
+	if err := writeContributorsJSON(failingWriter{}, outputTestContributors()); err == nil {
+		t.Error("Expected writeContributorsJSON to report the write error")
+	}
+	if err := writeContributorsCSV(failingWriter{}, outputTestContributors()); err == nil {
+		t.Error("Expected writeContributorsCSV to report the write error")
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// unsafePathChars matches characters that are replaced when a placeholder value is
+// substituted into a report path, so values cannot introduce separators or traversal.
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// expandReportPath fills the placeholders of a -report-path template:
+//   - {repo}: base name of the repository directory
+//   - {start}, {end}: the date filters as YYYY-MM-DD, or "all" when unset
+//   - {date}: today's date as YYYY-MM-DD
+//
+// Substituted values are sanitized to a single safe path component. Paths without
+// placeholders are returned unchanged.
+func expandReportPath(template, repoPath string, startDate, endDate *time.Time) string {
+	if !strings.Contains(template, "{") {
+		return template
+	}
+	repoName := filepath.Base(repoPath)
+	if absRepoPath, err := filepath.Abs(repoPath); err == nil {
+		repoName = filepath.Base(absRepoPath)
+	}
+	replacer := strings.NewReplacer(
+		"{repo}", sanitizePathComponent(repoName),
+		"{start}", sanitizePathComponent(formatDateOrAll(startDate)),
+		"{end}", sanitizePathComponent(formatDateOrAll(endDate)),
+		"{date}", now().Format(dateLayout),
+	)
+	return replacer.Replace(template)
+}
+
+// formatDateOrAll renders an optional date filter for use in a file name.
+func formatDateOrAll(t *time.Time) string {
+	if t == nil {
+		return "all"
+	}
+	return t.Format(dateLayout)
+}
+
+// sanitizePathComponent collapses unsafe characters into "-" and rejects dot-only names.
+func sanitizePathComponent(s string) string {
+	s = strings.Trim(unsafePathChars.ReplaceAllString(s, "-"), "-")
+	if strings.Trim(s, ".") == "" {
+		return "unnamed"
+	}
+	return s
+}
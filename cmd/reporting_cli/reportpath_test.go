@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExpandReportPath(t *testing.T) {
+	setNow(t, time.Date(2024, 3, 13, 15, 30, 0, 0, time.Local))
+	start, end := day(2024, 3, 1), day(2024, 3, 8)
+	repo := filepath.Join(t.TempDir(), "acme-api")
+	testCases := []struct {
+		name      string
+		template  string
+		repoPath  string
+		startDate *time.Time
+		endDate   *time.Time
+		want      string
+	}{
+		{"no placeholders", "reports/report.md", repo, &start, &end, "reports/report.md"},
+		{"empty", "", repo, nil, nil, ""},
+		{"all placeholders", "reports/{repo}/{start}_{end}-{date}.md", repo, &start, &end, "reports/acme-api/2024-03-01_2024-03-08-2024-03-13.md"},
+		{"unset dates", "{repo}-{start}-{end}.md", repo, nil, nil, "acme-api-all-all.md"},
+		{"repeated placeholder", "{repo}/{repo}.md", repo, nil, nil, "acme-api/acme-api.md"},
+		{"unknown token", "{branch}/{repo}-{Date}.md", repo, nil, nil, "{branch}/acme-api-{Date}.md"},
+		{"unclosed brace", "{repo-{date}.md", repo, nil, nil, "{repo-2024-03-13.md"},
+		{"trailing separator in repository path", "{repo}.md", repo + string(filepath.Separator), nil, nil, "acme-api.md"},
+		{"unsafe repository name", "{repo}.md", filepath.Join(t.TempDir(), "my repo!"), nil, nil, "my-repo.md"},
+		{"dot-only repository name", "{repo}.md", filepath.Join(t.TempDir(), "..."), nil, nil, "unnamed.md"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := expandReportPath(tc.template, tc.repoPath, tc.startDate, tc.endDate); got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestExpandReportPathCurrentDirectory(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := sanitizePathComponent(filepath.Base(wd)) + ".md"
+	if got := expandReportPath("{repo}.md", ".", nil, nil); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+// defaultTerminalWidth is used when the terminal size cannot be determined.
+const defaultTerminalWidth = 80
+
+// minNameColumnWidth keeps the name/email column legible on very narrow terminals.
+const minNameColumnWidth = 20
+
+// terminalWidth returns the width of the terminal attached to stdout. The COLUMNS
+// environment variable takes precedence; otherwise the size is queried from the
+// terminal, falling back to defaultTerminalWidth when stdout is not a terminal.
+func terminalWidth() int {
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		return cols
+	}
+	if cols := queryTerminalWidth(); cols > 0 {
+		return cols
+	}
+	return defaultTerminalWidth
+}
+
+// runeWidth returns the number of terminal columns a rune occupies: zero for
+// combining and enclosing marks, format characters such as the zero-width joiner
+// and control characters, two for East Asian wide and fullwidth characters (which
+// include most emoji), one otherwise.
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) || unicode.IsControl(r) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// displayWidth returns the number of terminal columns needed to print s.
+func displayWidth(s string) int {
+	total := 0
+	for _, r := range s {
+		total += runeWidth(r)
+	}
+	return total
+}
+
+// truncateToWidth shortens s to at most maxWidth terminal columns, replacing the
+// removed tail with an ellipsis. Truncation happens on rune boundaries.
+func truncateToWidth(s string, maxWidth int) string {
+	if displayWidth(s) <= maxWidth {
+		return s
+	}
+	const ellipsis = "…"
+	if maxWidth < 1 {
+		return ""
+	}
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		w := runeWidth(r)
+		if used+w > maxWidth-1 { // Reserve one column for the ellipsis
+			break
+		}
+		b.WriteRune(r)
+		used += w
+	}
+	b.WriteString(ellipsis)
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package main
+
+import "testing"
+
+func TestRuneWidth(t *testing.T) {
+	testCases := []struct {
+		name string
+		r    rune
+		want int
+	}{
+		{"ascii", 'a', 1},
+		{"precomposed latin", 'ë', 1},
+		{"cjk ideograph", '日', 2},
+		{"hangul syllable", '가', 2},
+		{"fullwidth letter", 'Ａ', 2},
+		{"halfwidth katakana", 'ｱ', 1},
+		{"emoji", '😀', 2},
+		{"emoji skin tone modifier", '🏽', 2},
+		{"combining acute accent", '\u0301', 0},
+		{"combining enclosing circle", '\u20dd', 0},
+		{"variation selector", '\ufe0f', 0},
+		{"zero-width joiner", '\u200d', 0},
+		{"control character", '\t', 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := runeWidth(tc.r); got != tc.want {
+				t.Errorf("Expected %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTruncateToWidth(t *testing.T) {
+	testCases := []struct {
+		name     string
+		s        string
+		maxWidth int
+		want     string
+	}{
+		{"fits", "Alice", 5, "Alice"},
+		{"ascii", "Alice Alpha", 6, "Alice…"},
+		{"cjk fits", "山田太郎", 8, "山田太郎"},
+		{"cjk", "山田太郎", 7, "山田太…"},
+		{"cjk with a column left over", "山田太郎", 6, "山田…"},
+		{"mixed ascii and cjk", "Bob 山田", 5, "Bob …"},
+		{"emoji fits", "🎉 party", 8, "🎉 party"},
+		{"emoji", "🎉🎉🎉", 5, "🎉🎉…"},
+		{"emoji with variation selector", "❤\ufe0f love", 6, "❤\ufe0f love"},
+		{"zero-width joiner takes no column", "👩\u200d💻 dev", 8, "👩\u200d💻 dev"},
+		{"combining characters fit", "Zoe\u0308 Zulu", 8, "Zoe\u0308 Zulu"},
+		{"combining character kept with its base", "Zoe\u0308 Zulu", 4, "Zoe\u0308…"},
+		{"combining characters only count their base", "e\u0301e\u0301e\u0301e\u0301", 3, "e\u0301e\u0301…"},
+		{"width one", "Alice", 1, "…"},
+		{"width zero", "Alice", 0, ""},
+		{"negative width", "Alice", -1, ""},
+		{"empty", "", 0, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := truncateToWidth(tc.s, tc.maxWidth)
+			if got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+			if w := displayWidth(got); w > max(tc.maxWidth, 0) {
+				t.Errorf("Expected at most %d columns, got %d", tc.maxWidth, w)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+// queryTerminalWidth is not implemented on this platform; callers fall back to
+// the COLUMNS environment variable or the default width.
+func queryTerminalWidth() int {
+	return 0
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// Build information, stamped by CI with
+//
+//	go build -ldflags "-X main.version=v1.2.3 -X main.commit=$(git rev-parse HEAD) -X main.date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version = "dev"
+	commit  = ""
+	date    = ""
+)
+
+// readBuildInfo returns the build information embedded in the binary; overridable for testing.
+var readBuildInfo = debug.ReadBuildInfo
+
+// versionString describes the running build. Values not stamped at build time fall back
+// to the information Go embeds: the module version when installed with go install
+// module@version, and the VCS revision and time when building from a checkout.
+func versionString() string {
+	v, c, d := version, commit, date
+	if info, ok := readBuildInfo(); ok {
+		if v == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			v = info.Main.Version
+		}
+		for _, s := range info.Settings {
+			switch {
+			case s.Key == "vcs.revision" && c == "":
+				c = s.Value
+			case s.Key == "vcs.time" && d == "":
+				d = s.Value
+			}
+		}
+	}
+	if c == "" {
+		c = "unknown"
+	}
+	if d == "" {
+		d = "unknown"
+	}
+	return fmt.Sprintf("reporting_cli %s (commit %s, built %s)", v, c, d)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+// setBuildInfo sets the ldflags-stamped variables and what readBuildInfo returns for the
+// rest of the test; a nil info makes build information unavailable.
+func setBuildInfo(t *testing.T, v, c, d string, info *debug.BuildInfo) {
+	t.Helper()
+	originalVersion, originalCommit, originalDate, originalRead := version, commit, date, readBuildInfo
+	version, commit, date = v, c, d
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return info, info != nil }
+	t.Cleanup(func() {
+		version, commit, date, readBuildInfo = originalVersion, originalCommit, originalDate, originalRead
+	})
+}
+
+func TestVersionString(t *testing.T) {
+	checkout := &debug.BuildInfo{
+		Main: debug.Module{Path: "github.com/Stone-IT-Cloud/reporting", Version: "(devel)"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "0123456789abcdef"},
+			{Key: "vcs.time", Value: "2024-03-01T10:00:00Z"},
+		},
+	}
+	installed := &debug.BuildInfo{Main: debug.Module{Path: "github.com/Stone-IT-Cloud/reporting", Version: "v1.4.0"}}
+	testCases := []struct {
+		name    string
+		version string
+		commit  string
+		date    string
+		info    *debug.BuildInfo
+		want    string
+	}{
+		{"ldflags", "v1.2.3", "fedcba9876543210", "2024-04-01T00:00:00Z", checkout, "reporting_cli v1.2.3 (commit fedcba9876543210, built 2024-04-01T00:00:00Z)"},
+		{"ldflags version only", "v1.2.3", "", "", checkout, "reporting_cli v1.2.3 (commit 0123456789abcdef, built 2024-03-01T10:00:00Z)"},
+		{"build from a checkout", "dev", "", "", checkout, "reporting_cli dev (commit 0123456789abcdef, built 2024-03-01T10:00:00Z)"},
+		{"go install of a tagged version", "dev", "", "", installed, "reporting_cli v1.4.0 (commit unknown, built unknown)"},
+		{"ldflags version over module version", "v1.2.3", "", "", installed, "reporting_cli v1.2.3 (commit unknown, built unknown)"},
+		{"no build information", "dev", "", "", nil, "reporting_cli dev (commit unknown, built unknown)"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setBuildInfo(t, tc.version, tc.commit, tc.date, tc.info)
+			if got := versionString(); got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
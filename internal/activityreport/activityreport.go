@@ -3,17 +3,29 @@ package activityreport
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"math"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
+	"github.com/Stone-IT-Cloud/reporting/pkg/gitlogs"
 	"gopkg.in/yaml.v3"
 )
 
+// ErrConfig is wrapped by errors caused by a missing or invalid configuration file.
+var ErrConfig = errors.New("failed to load configuration")
+
+// ErrNoCredentials is wrapped by errors caused by missing AI platform credentials.
+var ErrNoCredentials = errors.New("no authentication method available")
+
 // Config contains the configuration parameters for the activity report generation.
 type Config struct {
 	ChunkSize       int    `yaml:"chunk_size"`
@@ -21,9 +33,104 @@ type Config struct {
 	Location        string `yaml:"location"`
 	GeminiModel     string `yaml:"gemini_model"`
 	CredentialsFile string `yaml:"credentials_file"`
+	// Provider selects the LLM backend: "gemini" (the default), "openai" or "anthropic".
+	// Gemini uses project_id, location, gemini_model and credentials_file (or the
+	// GOOGLE_APPLICATION_CREDENTIALS / VERTEX_AI_API_KEY environment variables); OpenAI and
+	// Anthropic use Model and the OPENAI_API_KEY / ANTHROPIC_API_KEY environment variables.
+	Provider string `yaml:"provider"`
+	// Model is the model name for the openai and anthropic providers, e.g. "gpt-4o".
+	Model string `yaml:"model"`
+	// AllowUnknownModel silences the warning logged when GeminiModel is not in the list of
+	// known models, for models released after this version.
+	AllowUnknownModel bool `yaml:"allow_unknown_model"`
+	// ProjectName is the authoritative project name given to the AI for the report title.
+	// When empty, GenerateReport derives it from the repository (origin remote or directory name).
+	ProjectName string `yaml:"project_name"`
+	// TranscriptPath, when set, receives every prompt sent to the model and every
+	// response received, in order. Email addresses are masked when RedactEmails is set.
+	// Written even if generation fails.
+	TranscriptPath string `yaml:"transcript_path"`
+	// FrontMatter, when present (even empty), prepends a YAML front-matter block for
+	// static-site generators. title, date and period are filled in automatically unless
+	// overridden here; any other keys (e.g. tags) are copied as given.
+	FrontMatter map[string]string `yaml:"front_matter"`
+	// APIEndpoint overrides the Gemini API endpoint, e.g. to keep data in a region.
+	// Accepts "https://host[:port]" or "host:port"; the default endpoint is used when empty.
+	APIEndpoint string `yaml:"api_endpoint"`
+	// OutputFormats lists the formats ("md", "html") to save the report in. Each is
+	// written next to the output path with the format as extension, e.g. report.md and
+	// report.html, from a single model run. When empty, only the output path is written, as Markdown.
+	OutputFormats []string `yaml:"output_formats"`
+	// CollapseTrivialCommits merges runs of consecutive commits by the same author with
+	// near-identical trivial messages ("fix", "wip", "update", ...) into one entry before
+	// they are sent to the model, to save tokens and reduce noise.
+	CollapseTrivialCommits bool `yaml:"collapse_trivial_commits"`
+	// TrivialMessagePatterns overrides the regular expressions that mark a commit subject
+	// as trivial. Each must match the whole lowercased subject, without trailing
+	// punctuation or numbers. Only used with CollapseTrivialCommits.
+	TrivialMessagePatterns []string `yaml:"trivial_message_patterns"`
+	// MaxReportBytes caps the size of the generated report text, excluding front matter,
+	// to protect downstream integrations (chat messages, email) from oversized payloads.
+	// Zero disables the limit.
+	MaxReportBytes int `yaml:"max_report_bytes"`
+	// OnOverflow selects what happens when the report exceeds MaxReportBytes: "truncate"
+	// (the default) cuts it at a section boundary and appends a note; "error" fails.
+	OnOverflow string `yaml:"on_overflow"`
+	// ReportLanguage is the language to write the report in: a name ("Spanish"), an
+	// ISO 639-1 code ("es"), or "auto" to match the language most commit messages are
+	// written in. When empty, the model picks (usually English).
+	ReportLanguage string `yaml:"report_language"`
+	// RedactEmails masks author emails and any email address in commit messages (e.g.
+	// "a***@example.com") before the logs are sent to the model or written to the report.
+	RedactEmails bool `yaml:"redact_emails"`
+	// PromptTemplatePath, when set, is a text file whose contents replace the built-in
+	// report instructions, e.g. to change the report structure for a client. It is a
+	// text/template that may use {{.ProjectName}} and {{.ReportLanguage}}. The project
+	// name, language and collapsing instructions are still appended after it.
+	PromptTemplatePath string `yaml:"prompt_template_path"`
+	// MaxRetries is how many times a request to the model is retried after a transient
+	// error (rate limiting, 5xx, timeouts), so one hiccup does not abort a long run.
+	// Defaults to 3; a negative value disables retries.
+	MaxRetries int `yaml:"max_retries"`
+	// RetryBackoff is the delay before the first retry, e.g. "2s" (the default). It
+	// doubles on every further retry, up to a minute, with random jitter.
+	RetryBackoff time.Duration `yaml:"retry_backoff"`
+	// HotspotFiles, when positive, lists that many of the files changed most often in the
+	// logs, with their commit and distinct-author counts, in the prompt, and asks for a
+	// risks section flagging those changed by several authors.
+	HotspotFiles int `yaml:"hotspot_files"`
+
+	// Logger receives structured diagnostics emitted during report generation.
+	// It is not read from YAML; if nil, a stderr text handler is used.
+	Logger *slog.Logger `yaml:"-"`
+	// HTTPClient sends the requests of the openai and anthropic providers, e.g. through
+	// a proxy. It is not read from YAML; if nil, a client with a five-minute timeout is used.
+	HTTPClient *http.Client `yaml:"-"`
+	// Now returns the current time, used for the front-matter date and the transcript
+	// timestamps. It is not read from YAML; if nil, time.Now is used.
+	Now func() time.Time `yaml:"-"`
+}
+
+// logger returns the configured logger or a stderr text logger when none is set.
+func (c *Config) logger() *slog.Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, nil))
 }
 
-// LoadConfig reads and parses the YAML configuration file.
+// now returns the current time according to the configured clock.
+func (c *Config) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// LoadConfig reads and parses the YAML configuration file, then applies environment
+// variable overrides: every field can be set with REPORTING_ and its upper-cased YAML key
+// (e.g. REPORTING_CHUNK_SIZE, REPORTING_GEMINI_MODEL). Non-empty environment variables win
+// over the file, which wins over the built-in defaults. Validation runs on the result.
 func LoadConfig(configPath string) (*Config, error) {
 	if configPath == "" {
 		return nil, fmt.Errorf("config path cannot be empty")
@@ -48,19 +155,30 @@ func LoadConfig(configPath string) (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config YAML from %s: %w", cleanedPath, err)
 	}
+	if err := applyEnvOverrides(&cfg); err != nil {
+		return nil, err
+	}
 
 	// Basic validation
 	if cfg.ChunkSize <= 0 {
 		return nil, fmt.Errorf("chunk_size must be positive in config")
 	}
-	if cfg.ProjectID == "" {
-		return nil, fmt.Errorf("project_id cannot be empty in config")
+	if err := validateProvider(&cfg); err != nil {
+		return nil, err
+	}
+	if cfg.APIEndpoint != "" {
+		if _, err := normalizeEndpoint(cfg.APIEndpoint); err != nil {
+			return nil, err
+		}
 	}
-	if cfg.Location == "" {
-		return nil, fmt.Errorf("location cannot be empty in config")
+	if err := validateOutputFormats(cfg.OutputFormats); err != nil {
+		return nil, err
 	}
-	if cfg.GeminiModel == "" {
-		return nil, fmt.Errorf("gemini_model cannot be empty in config")
+	if _, err := compileTrivialPatterns(cfg.TrivialMessagePatterns); err != nil {
+		return nil, err
+	}
+	if err := validateOverflow(cfg.MaxReportBytes, cfg.OnOverflow); err != nil {
+		return nil, err
 	}
 
 	return &cfg, nil
@@ -70,70 +188,103 @@ func LoadConfig(configPath string) (*Config, error) {
 // Using map[string]interface{} for flexibility from gitlogs output.
 type CommitLog map[string]interface{}
 
-// #nosec G101 -- This is the name of an environment variable, not a credential itself.
-const apiKeyEnvVar = "VERTEX_AI_API_KEY" // Environment variable for the API key
+// Placeholder reports saved when there is nothing to summarize.
+const (
+	noActivityReport = "# Activity Report\n\nNo activity found in the provided logs.\n"
+	noResponseReport = "# Activity Report\n\nNo response generated by AI.\n"
+)
 
-// #nosec G101 -- This is the name of an environment variable, not a credential itself.
-const credentialsFileEnvVar = "GOOGLE_APPLICATION_CREDENTIALS" // Environment variable for credentials file
+// commitHashKey is the CommitLog field holding the commit hash, when the input provides one.
+const commitHashKey = "commit_hash"
+
+// ReportResult describes what a report generation run consumed.
+type ReportResult struct {
+	// TotalCommits is the number of commits parsed from the input JSON.
+	TotalCommits int
+	// SentCommits is the number of commits in the prompt chunks the model replied to.
+	// It equals TotalCommits unless data was dropped or a chunk failed to send.
+	SentCommits int
+	// CoveredCommits lists, in send order, the hashes of the commits in the prompt
+	// chunks the model replied to. Commits whose log entry carries no commit_hash are
+	// counted in SentCommits but not listed here.
+	CoveredCommits []string
+	// InputsFingerprint identifies the inputs of the run; see InputsFingerprint.
+	InputsFingerprint string
+}
+
+// initialPromptBase opens every chat with the model unless PromptTemplatePath replaces it;
+// GenerateReportWithConfig appends the instructions that depend on the configuration.
+const initialPromptBase = `
+act as a project manager, expert on IT. 
+After this prompt you will receive one or more json lists of objects with the commits sent to a git repository in separate prompts. 
+Read each json and prepare a weekly activity report that will be sent to the client and other stackholders. 
+Some of them are not technical persons, so keep a formal tone avoiding jargons. 
+Please write the report in markdown format. 
+Only return the report without any other text or explanation
+Commits that have a pull_request_number belong to that pull request: group related work by pull request or feature instead of listing individual commits.
+`
 
 // GenerateReport takes JSON commit logs, processes them in chunks, generates an AI report,
 // saves it to a file, and prints it to stdout.
 
 // GenerateReport generates a weekly activity report based on provided Git commit logs.
-// The report is generated using a Gemini AI model and saved in Markdown format.
+// The report is generated with the model of the configured provider (Gemini by default,
+// or OpenAI or Anthropic) and saved in Markdown format.
 //
 // Parameters:
 //   - ctx: The context for managing request deadlines and cancellations.
 //   - gitLogsJSON: A JSON string containing a list of Git commit logs.
 //   - configPath: The file path to the configuration file containing settings for the report generation.
-//   - outputPath: The file path where the generated report will be saved.
+//   - outputPath: Where the generated report will be saved: a local file path, "-" for
+//     standard output, or gs://bucket/object to upload it to Google Cloud Storage.
+//   - repoPath: The repository the logs come from, used to derive the project name
+//     when the configuration does not set project_name. May be empty.
 //
 // Behavior:
 //  1. Loads the configuration from the specified configPath.
-//  2. Sets up authentication using either a credentials file or an API key.
-//  3. Parses the provided gitLogsJSON into a list of commit logs.
-//  4. Initializes a Gemini AI client using the generative-ai-go library.
-//  5. Sends an initial prompt to the Gemini AI model to set the context for report generation.
-//  6. Processes the commit logs in chunks, sending them to the AI model for report generation.
-//  7. Extracts the final AI-generated response and formats it as a Markdown report.
-//  8. Saves the generated report to the specified outputPath and prints it to the console.
+//  2. Parses the provided gitLogsJSON into a list of commit logs.
+//  3. Builds the initial prompt and splits the commit logs into JSON chunks.
+//  4. Sends them to the provider's Generator, which authenticates with the provider's
+//     credentials, and takes its reply to the last chunk as the report.
+//  5. Saves the generated report to the specified outputPath and prints it to the console.
 //
 // Returns:
+//   - A ReportResult recording which commits the model received, so callers can
+//     verify that no input was dropped. When sending a chunk fails, it is returned
+//     along with the error and covers the chunks sent before.
 //   - An error if any step in the process fails, or nil if the report is successfully generated.
 //
 // Notes:
 //   - If no commit logs are provided or the AI model does not generate a response, an empty report is created.
 //   - The function ensures that non-technical stakeholders can understand the report by avoiding technical jargon.
-func GenerateReport(ctx context.Context, gitLogsJSON string, configPath string, outputPath string) error {
+func GenerateReport(ctx context.Context, gitLogsJSON string, configPath string, outputPath string, repoPath string) (*ReportResult, error) {
 	// --- 1. Load Configuration ---
 	cfg, err := LoadConfig(configPath)
 	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
-	}
-
-	// --- 2. Setup Authentication ---
-	var clientOpts []option.ClientOption
-
-	// Check for credentials file in config
-	if cfg.CredentialsFile != "" {
-		// Use credentials file from config
-		clientOpts = append(clientOpts, option.WithCredentialsFile(cfg.CredentialsFile))
-	} else {
-		// Check for credentials file in environment variable
-		credentialsPath := os.Getenv(credentialsFileEnvVar)
-		if credentialsPath != "" {
-			clientOpts = append(clientOpts, option.WithCredentialsFile(credentialsPath))
-		} else {
-			// Fall back to API key as last resort
-			apiKey := os.Getenv(apiKeyEnvVar)
-			if apiKey == "" {
-				return fmt.Errorf("no authentication method available: neither credentials file specified in config/environment nor %s env var set", apiKeyEnvVar)
-			}
-			clientOpts = append(clientOpts, option.WithAPIKey(apiKey))
+		return nil, fmt.Errorf("%w: %w", ErrConfig, err)
+	}
+	if cfg.ProjectName == "" && repoPath != "" {
+		name, err := gitlogs.RepositoryName(repoPath)
+		if err != nil {
+			cfg.logger().Warn("could not determine project name from repository", "repo", repoPath, "error", err)
 		}
+		cfg.ProjectName = name
+	}
+
+	return GenerateReportWithConfig(ctx, gitLogsJSON, cfg, outputPath)
+}
+
+// GenerateReportWithConfig behaves like GenerateReport but takes an already loaded
+// configuration. Library consumers use it to inject settings that cannot be expressed
+// in YAML, such as a custom Logger.
+func GenerateReportWithConfig(ctx context.Context, gitLogsJSON string, cfg *Config, outputPath string) (*ReportResult, error) {
+	template, err := promptTemplate(cfg)
+	if err != nil {
+		return nil, err
 	}
+	result := &ReportResult{InputsFingerprint: InputsFingerprint(gitLogsJSON, cfg)}
 
-	// --- 3. Parse Input JSON ---
+	// --- 2. Parse Input JSON ---
 	var logs []CommitLog
 	// Use json.Unmarshal directly on the string converted to bytes
 	if err := json.Unmarshal([]byte(gitLogsJSON), &logs); err != nil {
@@ -142,138 +293,190 @@ func GenerateReport(ctx context.Context, gitLogsJSON string, configPath string,
 			fmt.Println("No commit logs provided or found in the input JSON. Skipping report generation.")
 			if outputPath != "" {
 				// Optionally write an empty report file or do nothing
-				if err := os.WriteFile(outputPath, []byte("# Activity Report\n\nNo activity found in the provided logs.\n"), 0o600); err != nil {
-					return fmt.Errorf("failed to write empty report file %s: %w", outputPath, err)
+				if err := writeReportFormats(ctx, cfg, outputPath, noActivityReport, noActivityReport); err != nil {
+					return nil, fmt.Errorf("failed to write empty report: %w", err)
 				}
 				fmt.Println("Generated empty report file:", outputPath)
-				return nil
+				return result, nil
 			}
 		}
-		return fmt.Errorf("failed to unmarshal git logs JSON: %w", err)
+		return nil, fmt.Errorf("failed to unmarshal git logs JSON: %w", err)
+	}
+	result.TotalCommits = len(logs)
+	if cfg.RedactEmails {
+		redactCommitLogs(logs)
+	}
+
+	// Entries sent to the model; logs keeps every commit for the front matter.
+	promptLogs := logs
+	if cfg.CollapseTrivialCommits {
+		trivial, err := compileTrivialPatterns(cfg.TrivialMessagePatterns)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrConfig, err)
+		}
+		promptLogs = collapseTrivialCommits(logs, trivial)
+		cfg.logger().Info("collapsed trivial commits", "commits", len(logs), "entries", len(promptLogs))
 	}
 
 	if len(logs) == 0 {
 		fmt.Println("No commit logs found after parsing. Skipping report generation.")
 		if outputPath != "" {
-			_ = os.WriteFile(outputPath, []byte("# Activity Report\n\nNo activity found in the provided logs.\n"), 0o600)
+			_ = writeReportFormats(ctx, cfg, outputPath, noActivityReport, noActivityReport)
 			fmt.Println("Generated empty report file:", outputPath)
-			return nil
+			return result, nil
 		}
 	}
 
-	// --- 4. Initialize Gemini Client ---
-	// Creating a new client with the generative-ai-go library
-	client, err := genai.NewClient(ctx, clientOpts...)
-	if err != nil {
-		return fmt.Errorf("failed to initialize Gemini AI client: %w", err)
+	// --- 3. Build the Prompt and Chunks ---
+	initialPrompt := template
+	if cfg.ProjectName != "" {
+		initialPrompt += fmt.Sprintf("The project name is %q. Use exactly this name as the project name and in the report title.\n", cfg.ProjectName)
 	}
-	defer client.Close()
 
-	// Get the model
-	model := client.GenerativeModel(cfg.GeminiModel)
-
-	fmt.Printf("Initialized Gemini model %s\n", cfg.GeminiModel)
-
-	// --- 5. Start Chat Session & Send Initial Prompt ---
-	// Create a new chat session
-	cs := model.StartChat()
-
-	initialPrompt := `
-act as a project manager, expert on IT. 
-After this prompt you will receive one or more json lists of objects with the commits sent to a git repository in separate prompts. 
-Read each json and prepare a weekly activity report that will be sent to the client and other stackholders. 
-Some of them are not technical persons, so keep a formal tone avoiding jargons. 
-Please write the report in markdown format. 
-Only return the report without any other text or explanation
-`
+	language, err := reportLanguage(cfg, logs)
+	if err != nil {
+		return nil, err
+	}
+	if language != "" {
+		initialPrompt += fmt.Sprintf("Write the report in %s.\n", language)
+	}
 
-	fmt.Println("Sending initial prompt to Gemini...")
+	initialPrompt += hotspotPrompt(cfg, logs)
 
-	// Send the initial prompt
-	if _, err := cs.SendMessage(ctx, genai.Text(initialPrompt)); err != nil {
-		return fmt.Errorf("failed to send initial prompt to Gemini: %w", err)
+	if cfg.CollapseTrivialCommits {
+		initialPrompt += fmt.Sprintf("Objects with a %q field stand for that many consecutive commits by the same author with similar minor messages; %q is the date of the last of them.\n", collapsedCountKey, lastCommitDateKey)
 	}
 
-	// --- 6. Chunk Data and Send Prompts ---
-	fmt.Printf("Processing %d logs in chunks of %d...\n", len(logs), cfg.ChunkSize)
-	totalChunks := int(math.Ceil(float64(len(logs)) / float64(cfg.ChunkSize)))
-
-	var finalResp *genai.GenerateContentResponse
-	for i := 0; i < len(logs); i += cfg.ChunkSize {
+	fmt.Printf("Processing %d logs in chunks of %d...\n", len(promptLogs), cfg.ChunkSize)
+	totalChunks := int(math.Ceil(float64(len(promptLogs)) / float64(cfg.ChunkSize)))
+	chunks := make([]string, 0, totalChunks)
+	chunkLogs := make([][]CommitLog, 0, totalChunks) // Commits of each chunk, recorded once sent
+	for i := 0; i < len(promptLogs); i += cfg.ChunkSize {
 		end := i + cfg.ChunkSize
-		if end > len(logs) {
-			end = len(logs)
+		if end > len(promptLogs) {
+			end = len(promptLogs)
 		}
-		chunk := logs[i:end]
+		chunk := promptLogs[i:end]
 
 		// Marshal chunk back to JSON
 		chunkJSONBytes, err := json.MarshalIndent(chunk, "", "  ")
 		if err != nil {
-			return fmt.Errorf("failed to marshal commit chunk %d/%d to JSON: %w", (i/cfg.ChunkSize)+1, totalChunks, err)
+			return nil, fmt.Errorf("failed to marshal commit chunk %d/%d to JSON: %w", (i/cfg.ChunkSize)+1, totalChunks, err)
 		}
-		chunkJSONString := string(chunkJSONBytes)
-
-		fmt.Printf("Sending chunk %d/%d (%d commits) to Gemini...\n", (i/cfg.ChunkSize)+1, totalChunks, len(chunk))
-
-		// Send chunk JSON as the next prompt in the chat session
-		tempResp, err := cs.SendMessage(ctx, genai.Text(chunkJSONString))
-		if err != nil {
-			return fmt.Errorf("failed to send chunk %d/%d to Gemini: %w", (i/cfg.ChunkSize)+1, totalChunks, err)
-		}
-		finalResp = tempResp // Store the last response
+		chunks = append(chunks, string(chunkJSONBytes))
+		chunkLogs = append(chunkLogs, chunk)
 	}
-
-	// --- 7. Extract Final AI Response ---
-	if finalResp == nil {
-		fmt.Println("No response received from Gemini after sending chunks (logs might have been empty initially).")
+	if len(chunks) == 0 {
+		fmt.Println("No response received from the model after sending chunks (logs might have been empty initially).")
 		if outputPath != "" {
-			_ = os.WriteFile(outputPath, []byte("# Activity Report\n\nNo response generated by AI.\n"), 0o600)
+			_ = writeReportFormats(ctx, cfg, outputPath, noResponseReport, noResponseReport)
 			fmt.Println("Generated empty report file:", outputPath)
-			return nil
 		}
+		return result, nil
 	}
 
-	// Extract text content from the final response
-	reportContent := extractTextFromResponse(finalResp)
+	// --- 4. Generate the Report with the Configured Model ---
+	tr := newTranscript(cfg.TranscriptPath, cfg.RedactEmails, cfg.now)
+	defer func() {
+		if err := tr.write(); err != nil {
+			cfg.logger().Warn("could not save transcript", "error", err)
+		}
+	}()
+	gen, err := newGenerator(ctx, cfg, tr, func(chunk int) { result.recordChunk(chunkLogs[chunk]) })
+	if err != nil {
+		return nil, err
+	}
+	if closer, ok := gen.(io.Closer); ok {
+		defer closer.Close()
+	}
+	reportContent, err := gen.Generate(ctx, initialPrompt, chunks)
+	if result.SentCommits != result.TotalCommits {
+		cfg.logger().Warn("not every commit was sent to the model", "sent", result.SentCommits, "total", result.TotalCommits)
+	}
+	if err != nil {
+		return result, err
+	}
 	if reportContent == "" {
-		fmt.Println("Warning: Received response from Gemini, but could not extract text content.")
+		cfg.logger().Warn("received response from the model, but could not extract text content")
 		reportContent = "# Activity Report\n\nError: Could not extract text content from AI response.\n"
 	}
+	reportContent, err = enforceMaxReportBytes(cfg, reportContent)
+	if err != nil {
+		return nil, err
+	}
+	markdownContent, err := addFrontMatter(cfg, logs, reportContent, cfg.now())
+	if err != nil {
+		return nil, err
+	}
 
-	// --- 8. Save and Print Report ---
+	// --- 5. Save and Print Report ---
+	if outputPath == stdoutPath {
+		// The sink prints the report itself; echoing it as well would print it twice.
+		if err := writeReportFormats(ctx, cfg, outputPath, markdownContent, reportContent); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
 	if outputPath != "" {
 		fmt.Printf("Saving report to %s...\n", outputPath)
-		err = os.WriteFile(outputPath, []byte(reportContent), 0o600)
-		if err != nil {
-			return fmt.Errorf("failed to write report file %s: %w", outputPath, err)
+		if err := writeReportFormats(ctx, cfg, outputPath, markdownContent, reportContent); err != nil {
+			return nil, err
+		}
+		if len(cfg.OutputFormats) == 0 { // Otherwise each format reports its own path
+			fmt.Printf("Report successfully saved to %s\n", outputPath)
 		}
-		fmt.Printf("Report successfully saved to %s\n", outputPath)
 	}
 
 	fmt.Println("--- Generated Report ---")
-	fmt.Println(reportContent)
+	fmt.Println(markdownContent)
 	fmt.Println("--- End Report ---")
 
-	return nil
+	return result, nil
 }
 
-// extractTextFromResponse safely extracts the text content from the Gemini response.
-func extractTextFromResponse(resp *genai.GenerateContentResponse) string {
-	var builder strings.Builder
-	if resp == nil {
-		return ""
-	}
-
-	// Extract text from the response
-	for _, cand := range resp.Candidates {
-		if cand.Content != nil {
-			for _, part := range cand.Content.Parts {
-				if textPart, ok := part.(genai.Text); ok {
-					builder.WriteString(string(textPart))
-				}
+// recordChunk adds the commits of a chunk the model replied to to the result.
+// An entry standing for collapsed trivial commits counts as all of them.
+func (r *ReportResult) recordChunk(chunk []CommitLog) {
+	for _, entry := range chunk {
+		if count, ok := entry[collapsedCountKey].(int); ok {
+			r.SentCommits += count
+			if hashes, ok := entry[collapsedHashesKey].([]string); ok {
+				r.CoveredCommits = append(r.CoveredCommits, hashes...)
 			}
+			continue
+		}
+		r.SentCommits++
+		if hash, ok := entry[commitHashKey].(string); ok && hash != "" {
+			r.CoveredCommits = append(r.CoveredCommits, hash)
 		}
 	}
+}
 
-	return builder.String()
+// normalizeEndpoint validates an api_endpoint value and converts it to the host:port
+// form expected by the gRPC client. URLs must use https and carry no path or query;
+// the port defaults to 443.
+func normalizeEndpoint(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Opaque != "" {
+		// Not a URL with a scheme; treat it as host:port.
+		u, err = url.Parse("https://" + raw)
+		if err != nil {
+			return "", fmt.Errorf("invalid api_endpoint %q: %w", raw, err)
+		}
+	}
+	if u.Scheme != "https" {
+		return "", fmt.Errorf("invalid api_endpoint %q: scheme must be https", raw)
+	}
+	if u.User != nil {
+		// Errors end up in logs: show the value without its password.
+		return "", fmt.Errorf("invalid api_endpoint %q: credentials are not allowed", u.Redacted())
+	}
+	if u.Hostname() == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		return "", fmt.Errorf("invalid api_endpoint %q: expected https://host[:port] or host:port", raw)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+	}
+	return net.JoinHostPort(u.Hostname(), port), nil
 }
@@ -0,0 +1,109 @@
+package activityreport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// #nosec G101 -- This is the name of an environment variable, not a credential itself.
+const anthropicKeyEnvVar = "ANTHROPIC_API_KEY"
+
+const (
+	anthropicDefaultURL = "https://api.anthropic.com"
+	anthropicAPIVersion = "2023-06-01"
+	// anthropicMaxTokens caps each reply; the API requires a limit and reports fit well
+	// within it.
+	anthropicMaxTokens = 8192
+)
+
+// AnthropicGenerator generates reports with the Anthropic Messages API. The system prompt
+// is sent as the system parameter and each chunk as a user turn, keeping the whole
+// conversation, so the reply to the last chunk is the report.
+type AnthropicGenerator struct {
+	model      string
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	config     *Config
+	transcript *transcript
+	ack        chunkAck
+}
+
+// NewAnthropicGenerator returns a generator for cfg.Model using the ANTHROPIC_API_KEY
+// key; an error wrapping ErrNoCredentials is returned if it is not set. cfg.APIEndpoint,
+// when set, replaces api.anthropic.com.
+func NewAnthropicGenerator(cfg *Config) (*AnthropicGenerator, error) {
+	apiKey := os.Getenv(anthropicKeyEnvVar)
+	if apiKey == "" {
+		return nil, fmt.Errorf("%w: %s env var not set", ErrNoCredentials, anthropicKeyEnvVar)
+	}
+	baseURL, err := providerBaseURL(cfg, anthropicDefaultURL)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Initialized Anthropic model %s\n", cfg.Model)
+	return &AnthropicGenerator{
+		model:      cfg.Model,
+		apiKey:     apiKey,
+		baseURL:    baseURL,
+		httpClient: cfg.httpClient(),
+		config:     cfg,
+	}, nil
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+// Generate implements Generator.
+func (g *AnthropicGenerator) Generate(ctx context.Context, systemPrompt string, chunks []string) (string, error) {
+	var messages []anthropicMessage
+	g.transcript.record("system prompt", systemPrompt, "", nil) // Sent along with every chunk
+	reply := ""
+	for i, chunk := range chunks {
+		fmt.Printf("Sending chunk %d/%d to Anthropic...\n", i+1, len(chunks))
+		messages = append(messages, anthropicMessage{Role: "user", Content: chunk})
+		label := fmt.Sprintf("chunk %d/%d", i+1, len(chunks))
+		var resp anthropicResponse
+		err := sendWithRetry(ctx, g.config, label, func() error {
+			resp = anthropicResponse{}
+			return postJSON(ctx, g.httpClient, g.baseURL+"/v1/messages",
+				map[string]string{"x-api-key": g.apiKey, "anthropic-version": anthropicAPIVersion},
+				anthropicRequest{Model: g.model, MaxTokens: anthropicMaxTokens, System: systemPrompt, Messages: messages}, &resp)
+		})
+		var text strings.Builder
+		if err == nil {
+			for _, block := range resp.Content {
+				if block.Type == "text" {
+					text.WriteString(block.Text)
+				}
+			}
+		}
+		reply = text.String()
+		g.transcript.record(label, chunk, reply, err)
+		if err != nil {
+			return "", fmt.Errorf("failed to send chunk %d/%d to Anthropic: %w", i+1, len(chunks), err)
+		}
+		g.ack.sent(i)
+		messages = append(messages, anthropicMessage{Role: "assistant", Content: reply})
+	}
+	return reply, nil
+}
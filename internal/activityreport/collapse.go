@@ -0,0 +1,139 @@
+package activityreport
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// defaultTrivialMessagePatterns match commit subjects that carry no information on their
+// own. They are used when collapse_trivial_commits is set without trivial_message_patterns.
+var defaultTrivialMessagePatterns = []string{
+	`fix(es|ed)?`,
+	`(small |minor |quick )?fix(es)?`,
+	`wip`,
+	`updated?|updates`,
+	`changes?`,
+	`typo`,
+	`cleanup|clean up`,
+	`tmp|temp|test`,
+	`\.+`,
+}
+
+// CommitLog keys written on entries produced by collapseTrivialCommits.
+const (
+	collapsedCountKey  = "collapsed_commits"
+	collapsedHashesKey = "collapsed_commit_hashes"
+	lastCommitDateKey  = "last_commit_date_time"
+)
+
+// compileTrivialPatterns compiles patterns (or the defaults, when empty) into a single
+// case-insensitive regexp that must match a whole normalized subject.
+func compileTrivialPatterns(patterns []string) (*regexp.Regexp, error) {
+	if len(patterns) == 0 {
+		patterns = defaultTrivialMessagePatterns
+	}
+	for _, p := range patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return nil, fmt.Errorf("invalid trivial_message_patterns entry %q: %w", p, err)
+		}
+	}
+	return regexp.Compile(`(?i)^(?:` + strings.Join(patterns, "|") + `)$`)
+}
+
+// trivialSubject returns the normalized subject of message and whether it matches
+// trivial. Normalization lowercases the first line, collapses whitespace and drops
+// trailing punctuation and counters ("WIP!!", "fix 2" -> "wip", "fix"), so that
+// near-identical messages compare equal.
+func trivialSubject(message string, trivial *regexp.Regexp) (string, bool) {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	subject = strings.ToLower(strings.Join(strings.Fields(subject), " "))
+	if stripped := strings.TrimRight(subject, " .!?:;,#0123456789"); stripped != "" {
+		subject = stripped
+	}
+	return subject, subject != "" && trivial.MatchString(subject)
+}
+
+// collapseTrivialCommits merges runs of consecutive commits by the same author whose
+// messages are trivial (per trivial) and near-identical into a single entry. The entry
+// keeps the first commit's fields and adds the number of commits it stands for, their
+// hashes (when known), the date of the last one, and the union of modified files.
+// Other commits are returned unchanged and in order.
+func collapseTrivialCommits(logs []CommitLog, trivial *regexp.Regexp) []CommitLog {
+	result := make([]CommitLog, 0, len(logs))
+	var group []CommitLog
+	groupKey := ""
+
+	flush := func() {
+		if len(group) == 1 {
+			result = append(result, group[0])
+		} else if len(group) > 1 {
+			result = append(result, mergeCommitGroup(group))
+		}
+		group = nil
+		groupKey = ""
+	}
+
+	for _, entry := range logs {
+		message, _ := entry["commit_message"].(string)
+		subject, ok := trivialSubject(message, trivial)
+		if !ok {
+			flush()
+			result = append(result, entry)
+			continue
+		}
+		author, _ := entry["author_email"].(string)
+		key := strings.ToLower(author) + "\x00" + subject
+		if key != groupKey {
+			flush()
+			groupKey = key
+		}
+		group = append(group, entry)
+	}
+	flush()
+	return result
+}
+
+// mergeCommitGroup builds the single entry standing for a run of collapsed commits.
+func mergeCommitGroup(group []CommitLog) CommitLog {
+	merged := make(CommitLog, len(group[0])+3)
+	for k, v := range group[0] {
+		merged[k] = v
+	}
+	delete(merged, commitHashKey) // Listed with the others below
+
+	files := make(map[string]struct{})
+	var hashes []string
+	for _, entry := range group {
+		if hash, ok := entry[commitHashKey].(string); ok && hash != "" {
+			hashes = append(hashes, hash)
+		}
+		if list, ok := entry["modified_files"].([]interface{}); ok {
+			for _, f := range list {
+				if name, ok := f.(string); ok {
+					files[name] = struct{}{}
+				}
+			}
+		}
+	}
+	fileList := make([]interface{}, 0, len(files))
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fileList = append(fileList, name)
+	}
+
+	merged["modified_files"] = fileList
+	merged[collapsedCountKey] = len(group)
+	if len(hashes) > 0 {
+		merged[collapsedHashesKey] = hashes
+	}
+	if last, ok := group[len(group)-1]["commit_date_time"]; ok {
+		merged[lastCommitDateKey] = last
+	}
+	return merged
+}
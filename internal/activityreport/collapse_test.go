@@ -0,0 +1,161 @@
+package activityreport
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrivialSubject(t *testing.T) {
+	trivial, err := compileTrivialPatterns(nil)
+	if err != nil {
+		t.Fatalf("Expected the default patterns to compile, got %v", err)
+	}
+	testCases := []struct {
+		message     string
+		wantSubject string
+		wantTrivial bool
+	}{
+		{"fix", "fix", true},
+		{"Fixed", "fixed", true},
+		{"Minor fixes", "minor fixes", true},
+		{"WIP!!", "wip", true},
+		{"fix 2", "fix", true},
+		{"update #3", "update", true},
+		{"  Clean   up  ", "clean up", true},
+		{"typo.\n\nIn the README", "typo", true},
+		{"...", "...", true},
+		{"Fix login redirect", "fix login redirect", false},
+		{"Update dependencies", "update dependencies", false},
+		{"testing", "testing", false},
+		{"2024", "2024", false},
+		{"", "", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			subject, ok := trivialSubject(tc.message, trivial)
+			if subject != tc.wantSubject || ok != tc.wantTrivial {
+				t.Errorf("Expected (%q, %t), got (%q, %t)", tc.wantSubject, tc.wantTrivial, subject, ok)
+			}
+		})
+	}
+}
+
+func TestCompileTrivialPatterns(t *testing.T) {
+	trivial, err := compileTrivialPatterns([]string{"chore", "bump( version)?"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for subject, want := range map[string]bool{"chore": true, "bump version": true, "fix": false, "chore up": false} {
+		if got := trivial.MatchString(subject); got != want {
+			t.Errorf("Expected %q to match %t, got %t", subject, want, got)
+		}
+	}
+	if _, err := compileTrivialPatterns([]string{"fix", "(unclosed"}); err == nil {
+		t.Error("Expected an error for an invalid pattern, got nil")
+	}
+}
+
+// collapseTestCommit returns a commit log entry as GenerateReport parses it.
+func collapseTestCommit(hash, email, message, date string, files ...string) CommitLog {
+	list := make([]interface{}, len(files))
+	for i, f := range files {
+		list[i] = f
+	}
+	return CommitLog{
+		"commit_hash":      hash,
+		"author_email":     email,
+		"commit_message":   message,
+		"commit_date_time": date,
+		"modified_files":   list,
+	}
+}
+
+func TestCollapseTrivialCommits(t *testing.T) {
+	trivial, err := compileTrivialPatterns(nil)
+	if err != nil {
+		t.Fatalf("Expected the default patterns to compile, got %v", err)
+	}
+	feature := collapseTestCommit("h1", "alice@example.com", "Add login page", "2024-03-01T10:00:00Z", "login.go")
+	fix1 := collapseTestCommit("h2", "alice@example.com", "fix", "2024-03-01T11:00:00Z", "login.go")
+	fix2 := collapseTestCommit("h3", "Alice@Example.com", "Fix!", "2024-03-01T12:00:00Z", "login_test.go", "login.go")
+	fix3 := collapseTestCommit("h4", "alice@example.com", "fix 3", "2024-03-01T13:00:00Z", "auth.go")
+	bobFix := collapseTestCommit("h5", "bob@example.com", "fix", "2024-03-01T14:00:00Z", "db.go")
+	wip := collapseTestCommit("h6", "bob@example.com", "wip", "2024-03-01T15:00:00Z", "db.go")
+	refactor := collapseTestCommit("h7", "bob@example.com", "Refactor database layer", "2024-03-01T16:00:00Z", "db.go")
+	lateFix := collapseTestCommit("h8", "alice@example.com", "fix", "2024-03-01T17:00:00Z", "login.go")
+
+	collapsedFixes := CommitLog{
+		"author_email":     "alice@example.com",
+		"commit_message":   "fix",
+		"commit_date_time": "2024-03-01T11:00:00Z",
+		"modified_files":   []interface{}{"auth.go", "login.go", "login_test.go"},
+		collapsedCountKey:  3,
+		collapsedHashesKey: []string{"h2", "h3", "h4"},
+		lastCommitDateKey:  "2024-03-01T13:00:00Z",
+	}
+
+	testCases := []struct {
+		name string
+		logs []CommitLog
+		want []CommitLog
+	}{
+		{
+			name: "no trivial commits",
+			logs: []CommitLog{feature, refactor},
+			want: []CommitLog{feature, refactor},
+		},
+		{
+			name: "single trivial commit is kept",
+			logs: []CommitLog{feature, fix1, refactor},
+			want: []CommitLog{feature, fix1, refactor},
+		},
+		{
+			name: "run by the same author collapses",
+			logs: []CommitLog{feature, fix1, fix2, fix3, bobFix, wip, refactor, lateFix},
+			// Bob's fix and wip differ, and the late fix is not consecutive with the others
+			want: []CommitLog{feature, collapsedFixes, bobFix, wip, refactor, lateFix},
+		},
+		{
+			name: "other authors break a run",
+			logs: []CommitLog{fix1, bobFix, fix3},
+			want: []CommitLog{fix1, bobFix, fix3},
+		},
+		{
+			name: "non-trivial commit breaks a run",
+			logs: []CommitLog{fix1, feature, fix3},
+			want: []CommitLog{fix1, feature, fix3},
+		},
+		{
+			name: "empty",
+			logs: nil,
+			want: []CommitLog{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := collapseTrivialCommits(tc.logs, trivial)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Mismatch:\nExpected: %v\nActual:   %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCollapseTrivialCommitsWithoutHashes(t *testing.T) {
+	trivial, err := compileTrivialPatterns(nil)
+	if err != nil {
+		t.Fatalf("Expected the default patterns to compile, got %v", err)
+	}
+	first := collapseTestCommit("", "alice@example.com", "wip", "2024-03-01T10:00:00Z", "a.go")
+	second := collapseTestCommit("", "alice@example.com", "WIP", "2024-03-01T11:00:00Z", "a.go")
+	got := collapseTrivialCommits([]CommitLog{first, second}, trivial)
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 entry, got %d: %v", len(got), got)
+	}
+	if _, ok := got[0][collapsedHashesKey]; ok {
+		t.Errorf("Expected no %s without commit hashes, got %v", collapsedHashesKey, got[0][collapsedHashesKey])
+	}
+	if got[0][collapsedCountKey] != 2 {
+		t.Errorf("Expected %s 2, got %v", collapsedCountKey, got[0][collapsedCountKey])
+	}
+}
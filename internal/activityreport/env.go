@@ -0,0 +1,98 @@
+package activityreport
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envPrefix starts the name of every environment variable that overrides a Config field.
+const envPrefix = "REPORTING_"
+
+// durationType is the reflect type of time.Duration fields, which are parsed with
+// time.ParseDuration rather than as plain integers.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// envVarName returns the environment variable overriding the field with the given YAML
+// key, e.g. REPORTING_CHUNK_SIZE for chunk_size.
+func envVarName(yamlKey string) string {
+	return envPrefix + strings.ToUpper(yamlKey)
+}
+
+// applyEnvOverrides sets every Config field whose REPORTING_<YAML KEY> environment variable
+// is set and not empty, so values from the environment win over the file. Lists are
+// comma-separated ("md,html"), maps are comma-separated key=value pairs ("tags=a,draft=b")
+// and durations use time.ParseDuration syntax ("2s").
+func applyEnvOverrides(cfg *Config) error {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+		name := envVarName(key)
+		value := strings.TrimSpace(os.Getenv(name))
+		if value == "" {
+			continue
+		}
+		if err := setFromEnv(v.Field(i), value); err != nil {
+			return fmt.Errorf("invalid value %q for %s: %w", value, name, err)
+		}
+	}
+	return nil
+}
+
+// setFromEnv parses value into field according to the field's type.
+func setFromEnv(field reflect.Value, value string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+	switch kind := field.Kind(); {
+	case (kind == reflect.Slice || kind == reflect.Map) && field.Type().Elem().Kind() != reflect.String:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	case kind == reflect.String:
+		field.SetString(value)
+	case kind == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case kind == reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	case kind == reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	case kind == reflect.Map:
+		m := make(map[string]string)
+		for _, pair := range strings.Split(value, ",") {
+			k, val, ok := strings.Cut(pair, "=")
+			if !ok || strings.TrimSpace(k) == "" {
+				return fmt.Errorf("expected key=value pairs, got %q", pair)
+			}
+			m[strings.TrimSpace(k)] = strings.TrimSpace(val)
+		}
+		field.Set(reflect.ValueOf(m))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
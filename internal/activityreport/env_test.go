@@ -0,0 +1,109 @@
+package activityreport
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestConfig writes content to a configuration file and returns its path.
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+const envTestConfig = `
+provider: openai
+model: gpt-file
+chunk_size: 10
+redact_emails: false
+retry_backoff: 1s
+output_formats: [md]
+project_name: From file
+`
+
+func TestLoadConfigEnvOverrides(t *testing.T) {
+	path := writeTestConfig(t, envTestConfig)
+	t.Setenv("REPORTING_MODEL", "gpt-env")                        // string
+	t.Setenv("REPORTING_CHUNK_SIZE", " 25 ")                      // int, surrounding spaces ignored
+	t.Setenv("REPORTING_REDACT_EMAILS", "true")                   // bool
+	t.Setenv("REPORTING_RETRY_BACKOFF", "500ms")                  // duration
+	t.Setenv("REPORTING_OUTPUT_FORMATS", "md, html,")             // slice
+	t.Setenv("REPORTING_FRONT_MATTER", "tags=weekly, draft=true") // map
+	t.Setenv("REPORTING_PROJECT_NAME", "")                        // empty values are ignored
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.Model != "gpt-env" {
+		t.Errorf("Expected model gpt-env, got %q", cfg.Model)
+	}
+	if cfg.ChunkSize != 25 {
+		t.Errorf("Expected chunk size 25, got %d", cfg.ChunkSize)
+	}
+	if !cfg.RedactEmails {
+		t.Error("Expected RedactEmails to be set")
+	}
+	if cfg.RetryBackoff != 500*time.Millisecond {
+		t.Errorf("Expected retry backoff 500ms, got %v", cfg.RetryBackoff)
+	}
+	if want := []string{"md", "html"}; !reflect.DeepEqual(cfg.OutputFormats, want) {
+		t.Errorf("Expected output formats %v, got %v", want, cfg.OutputFormats)
+	}
+	if want := map[string]string{"tags": "weekly", "draft": "true"}; !reflect.DeepEqual(cfg.FrontMatter, want) {
+		t.Errorf("Expected front matter %v, got %v", want, cfg.FrontMatter)
+	}
+	if cfg.ProjectName != "From file" {
+		t.Errorf("Expected the file value when the variable is empty, got %q", cfg.ProjectName)
+	}
+	if cfg.Provider != "openai" {
+		t.Errorf("Expected the file value when no variable is set, got %q", cfg.Provider)
+	}
+}
+
+func TestLoadConfigEnvOverridesInvalid(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value string
+	}{
+		{"REPORTING_CHUNK_SIZE", "ten"},
+		{"REPORTING_REDACT_EMAILS", "maybe"},
+		{"REPORTING_RETRY_BACKOFF", "5 parsecs"},
+		{"REPORTING_FRONT_MATTER", "tags"},
+		{"REPORTING_FRONT_MATTER", "=weekly"},
+	}
+	path := writeTestConfig(t, envTestConfig)
+	for _, tc := range testCases {
+		t.Run(tc.name+"="+tc.value, func(t *testing.T) {
+			t.Setenv(tc.name, tc.value)
+			_, err := LoadConfig(path)
+			if err == nil {
+				t.Fatal("Expected an error, got nil")
+			}
+			if !strings.Contains(err.Error(), tc.name) {
+				t.Errorf("Expected the error to name %s, got %v", tc.name, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigEnvOverridesValidated(t *testing.T) {
+	t.Setenv("REPORTING_CHUNK_SIZE", "-1")
+	if _, err := LoadConfig(writeTestConfig(t, envTestConfig)); err == nil {
+		t.Fatal("Expected the overridden chunk size to be validated, got no error")
+	}
+}
+
+func TestEnvVarName(t *testing.T) {
+	if got := envVarName("gemini_model"); got != "REPORTING_GEMINI_MODEL" {
+		t.Errorf("Expected REPORTING_GEMINI_MODEL, got %q", got)
+	}
+}
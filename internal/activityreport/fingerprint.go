@@ -0,0 +1,53 @@
+package activityreport
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// fingerprintVersion is bumped whenever the fingerprinted fields change, so fingerprints
+// from different versions never match by accident.
+const fingerprintVersion = "2"
+
+// InputsFingerprint returns a hex SHA-256 over the commit logs and the configuration that
+// shapes what is sent to the model: the model, chunk size, prompt (the contents of
+// PromptTemplatePath when set), project name, report language, the collapsing and
+// redaction settings and the number of hotspot files. Two runs with the same fingerprint
+// sent the model identical data, so differing reports are down to model nondeterminism.
+//
+// gitLogsJSON is normalized first (whitespace and object key order are ignored); input
+// that is not valid JSON is hashed as given. Credentials, endpoints and output settings
+// do not affect the fingerprint.
+func InputsFingerprint(gitLogsJSON string, cfg *Config) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "version=%s\n", fingerprintVersion)
+
+	logs := []byte(strings.TrimSpace(gitLogsJSON))
+	var parsed interface{}
+	dec := json.NewDecoder(bytes.NewReader(logs))
+	dec.UseNumber() // Keep large integers exact
+	if err := dec.Decode(&parsed); err == nil && !dec.More() {
+		if normalized, err := json.Marshal(parsed); err == nil { // Map keys are sorted
+			logs = normalized
+		}
+	}
+	fmt.Fprintf(h, "logs=%d:%s\n", len(logs), logs)
+
+	fmt.Fprintf(h, "provider=%q model=%q\n", cfg.Provider, cfg.modelName())
+	fmt.Fprintf(h, "chunk-size=%d\n", cfg.ChunkSize)
+	prompt, err := promptTemplate(cfg)
+	if err != nil {
+		prompt = "invalid template " + cfg.PromptTemplatePath
+	}
+	fmt.Fprintf(h, "prompt=%q\n", prompt)
+	fmt.Fprintf(h, "project-name=%q\n", cfg.ProjectName)
+	fmt.Fprintf(h, "report-language=%q\n", cfg.ReportLanguage)
+	fmt.Fprintf(h, "collapse-trivial-commits=%t trivial-message-patterns=%q\n", cfg.CollapseTrivialCommits, cfg.TrivialMessagePatterns)
+	fmt.Fprintf(h, "redact-emails=%t\n", cfg.RedactEmails)
+	fmt.Fprintf(h, "hotspot-files=%d\n", cfg.HotspotFiles)
+	return hex.EncodeToString(h.Sum(nil))
+}
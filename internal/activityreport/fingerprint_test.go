@@ -0,0 +1,104 @@
+package activityreport
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const fingerprintTestLogs = `[{"commit_hash":"h1","author_email":"alice@example.com","commit_message":"Add login","lines_added":12345678901234567890}]`
+
+// fingerprintTestConfig returns the configuration the mutations in TestInputsFingerprint
+// start from.
+func fingerprintTestConfig() *Config {
+	return &Config{
+		Provider:    ProviderOpenAI,
+		Model:       "gpt-test",
+		ChunkSize:   10,
+		ProjectName: "Acme",
+	}
+}
+
+func TestInputsFingerprintStable(t *testing.T) {
+	want := InputsFingerprint(fingerprintTestLogs, fingerprintTestConfig())
+	if len(want) != 64 {
+		t.Fatalf("Expected a hex SHA-256, got %q", want)
+	}
+	if got := InputsFingerprint(fingerprintTestLogs, fingerprintTestConfig()); got != want {
+		t.Errorf("Expected identical input to give %s, got %s", want, got)
+	}
+	reformatted := "\n[ {\"lines_added\": 12345678901234567890, \"commit_message\": \"Add login\",\n  \"author_email\": \"alice@example.com\", \"commit_hash\": \"h1\"} ]\n"
+	if got := InputsFingerprint(reformatted, fingerprintTestConfig()); got != want {
+		t.Errorf("Expected whitespace and key order to be ignored, got %s, want %s", got, want)
+	}
+
+	ignored := map[string]func(*Config){
+		"transcript path": func(c *Config) { c.TranscriptPath = "transcript.txt" },
+		"api endpoint":    func(c *Config) { c.APIEndpoint = "https://proxy.example.com" },
+		"output formats":  func(c *Config) { c.OutputFormats = []string{FormatHTML} },
+		"max retries":     func(c *Config) { c.MaxRetries = 5 },
+	}
+	for name, mutate := range ignored {
+		cfg := fingerprintTestConfig()
+		mutate(cfg)
+		if got := InputsFingerprint(fingerprintTestLogs, cfg); got != want {
+			t.Errorf("Expected %s not to change the fingerprint", name)
+		}
+	}
+}
+
+func TestInputsFingerprintChanges(t *testing.T) {
+	templatePath := filepath.Join(t.TempDir(), "prompt.txt")
+	if err := os.WriteFile(templatePath, []byte("Summarize the work."), 0o600); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	testCases := []struct {
+		name   string
+		logs   string
+		mutate func(*Config)
+	}{
+		{"logs", `[{"commit_hash":"h2"}]`, nil},
+		{"large number in logs", `[{"commit_hash":"h1","author_email":"alice@example.com","commit_message":"Add login","lines_added":12345678901234567891}]`, nil},
+		{"invalid logs", `not json`, nil},
+		{"provider", "", func(c *Config) { c.Provider = ProviderAnthropic }},
+		{"model", "", func(c *Config) { c.Model = "gpt-other" }},
+		{"chunk size", "", func(c *Config) { c.ChunkSize = 20 }},
+		{"prompt template", "", func(c *Config) { c.PromptTemplatePath = templatePath }},
+		{"project name", "", func(c *Config) { c.ProjectName = "Other" }},
+		{"report language", "", func(c *Config) { c.ReportLanguage = "es" }},
+		{"collapse trivial commits", "", func(c *Config) { c.CollapseTrivialCommits = true }},
+		{"trivial message patterns", "", func(c *Config) { c.TrivialMessagePatterns = []string{"chore"} }},
+		{"redact emails", "", func(c *Config) { c.RedactEmails = true }},
+		{"hotspot files", "", func(c *Config) { c.HotspotFiles = 5 }},
+	}
+	seen := map[string]string{InputsFingerprint(fingerprintTestLogs, fingerprintTestConfig()): "base"}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logs := fingerprintTestLogs
+			if tc.logs != "" {
+				logs = tc.logs
+			}
+			cfg := fingerprintTestConfig()
+			if tc.mutate != nil {
+				tc.mutate(cfg)
+			}
+			got := InputsFingerprint(logs, cfg)
+			if other, ok := seen[got]; ok {
+				t.Errorf("Expected a new fingerprint, got the one of %s", other)
+			}
+			seen[got] = tc.name
+		})
+	}
+
+	t.Run("prompt template contents", func(t *testing.T) {
+		cfg := fingerprintTestConfig()
+		cfg.PromptTemplatePath = templatePath
+		before := InputsFingerprint(fingerprintTestLogs, cfg)
+		if err := os.WriteFile(templatePath, []byte("Summarize the work in detail."), 0o600); err != nil {
+			t.Fatalf("Failed to write template: %v", err)
+		}
+		if after := InputsFingerprint(fingerprintTestLogs, cfg); after == before {
+			t.Error("Expected editing the prompt template to change the fingerprint")
+		}
+	})
+}
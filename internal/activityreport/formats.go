@@ -0,0 +1,233 @@
+package activityreport
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Output formats accepted by output_formats.
+const (
+	FormatMarkdown = "md"
+	FormatHTML     = "html"
+)
+
+// validateOutputFormats checks every entry of output_formats against the known formats.
+func validateOutputFormats(formats []string) error {
+	for _, format := range formats {
+		switch format {
+		case FormatMarkdown, FormatHTML:
+		default:
+			return fmt.Errorf("unsupported output format %q in output_formats (supported: %s, %s)", format, FormatMarkdown, FormatHTML)
+		}
+	}
+	return nil
+}
+
+// formatPath derives the path of the report in format from outputPath by replacing
+// its extension, e.g. report.md -> report.html. Every format of a report written to
+// standard output goes there.
+func formatPath(outputPath, format string) string {
+	if outputPath == stdoutPath {
+		return stdoutPath
+	}
+	return strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + "." + format
+}
+
+// writeReportFormats saves the report at outputPath, or, when cfg.OutputFormats is set,
+// once per requested format at a path derived from outputPath. markdown is the full
+// report as saved in Markdown (including any front matter); body is the report text
+// rendered to the other formats.
+func writeReportFormats(ctx context.Context, cfg *Config, outputPath, markdown, body string) error {
+	if len(cfg.OutputFormats) == 0 {
+		return writeReport(ctx, cfg, outputPath, []byte(markdown))
+	}
+	for _, format := range cfg.OutputFormats {
+		var content string
+		switch format {
+		case FormatMarkdown:
+			content = markdown
+		case FormatHTML:
+			content = renderHTMLDocument(body)
+		default:
+			return fmt.Errorf("unsupported output format %q", format)
+		}
+		path := formatPath(outputPath, format)
+		if err := writeReport(ctx, cfg, path, []byte(content)); err != nil {
+			return err
+		}
+		if path != stdoutPath {
+			fmt.Printf("Report saved as %s to %s\n", format, path)
+		}
+	}
+	return nil
+}
+
+// renderHTMLDocument wraps the HTML rendering of markdown in a standalone page titled
+// after its first heading.
+func renderHTMLDocument(markdown string) string {
+	title := "Activity Report"
+	for _, line := range strings.Split(markdown, "\n") {
+		if level, text := headingLevel(line); level > 0 {
+			title = text
+			break
+		}
+	}
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(stripInlineMarkdown(title)))
+	b.WriteString("</head>\n<body>\n")
+	b.WriteString(markdownToHTML(markdown))
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+var (
+	orderedItemPattern   = regexp.MustCompile(`^\d+[.)]\s+`)
+	unorderedItemPattern = regexp.MustCompile(`^[-*+]\s+`)
+	ruleLinePattern      = regexp.MustCompile(`^(\*\s*){3,}$|^(-\s*){3,}$|^(_\s*){3,}$`)
+)
+
+// markdownToHTML converts the Markdown subset produced by the model (headings,
+// paragraphs, flat lists, fenced code, block quotes, rules and inline emphasis, code
+// and links) to HTML. Anything else is kept as escaped text.
+func markdownToHTML(markdown string) string {
+	var b strings.Builder
+	var paragraph []string
+	listTag := "" // "ul" or "ol" while inside a list
+	inCode := false
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			fmt.Fprintf(&b, "<p>%s</p>\n", renderInline(strings.Join(paragraph, " ")))
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if listTag != "" {
+			fmt.Fprintf(&b, "</%s>\n", listTag)
+			listTag = ""
+		}
+	}
+	openList := func(tag string) {
+		if listTag != tag {
+			closeList()
+			fmt.Fprintf(&b, "<%s>\n", tag)
+			listTag = tag
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			if inCode {
+				b.WriteString("</code></pre>\n")
+			} else {
+				flushParagraph()
+				closeList()
+				b.WriteString("<pre><code>")
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			b.WriteString(html.EscapeString(line) + "\n")
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flushParagraph()
+			closeList()
+		case ruleLinePattern.MatchString(trimmed):
+			flushParagraph()
+			closeList()
+			b.WriteString("<hr>\n")
+		case strings.HasPrefix(trimmed, "#"):
+			level, text := headingLevel(trimmed)
+			if level == 0 {
+				paragraph = append(paragraph, trimmed)
+				continue
+			}
+			flushParagraph()
+			closeList()
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", level, renderInline(text), level)
+		case unorderedItemPattern.MatchString(trimmed):
+			flushParagraph()
+			openList("ul")
+			fmt.Fprintf(&b, "<li>%s</li>\n", renderInline(unorderedItemPattern.ReplaceAllString(trimmed, "")))
+		case orderedItemPattern.MatchString(trimmed):
+			flushParagraph()
+			openList("ol")
+			fmt.Fprintf(&b, "<li>%s</li>\n", renderInline(orderedItemPattern.ReplaceAllString(trimmed, "")))
+		case strings.HasPrefix(trimmed, ">"):
+			flushParagraph()
+			closeList()
+			fmt.Fprintf(&b, "<blockquote><p>%s</p></blockquote>\n", renderInline(strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))))
+		default:
+			closeList()
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	if inCode {
+		b.WriteString("</code></pre>\n")
+	}
+	flushParagraph()
+	closeList()
+	return b.String()
+}
+
+// headingLevel returns the level (1-6) and text of an ATX heading line, or 0 if line
+// is not a heading.
+func headingLevel(line string) (int, string) {
+	trimmed := strings.TrimSpace(line)
+	level := 0
+	for level < len(trimmed) && trimmed[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(trimmed) && trimmed[level] != ' ') {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(strings.TrimRight(trimmed[level:], "#"))
+}
+
+var (
+	inlineCodePattern = regexp.MustCompile("`([^`]+)`")
+	linkPattern       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	boldPattern       = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	italicPattern     = regexp.MustCompile(`\*([^*]+)\*|\b_([^_]+)_\b`)
+)
+
+// renderInline escapes text and converts inline code, links, bold and italics.
+// Code spans are rendered first so their content is not formatted.
+func renderInline(text string) string {
+	var codeSpans []string
+	text = inlineCodePattern.ReplaceAllStringFunc(text, func(m string) string {
+		codeSpans = append(codeSpans, "<code>"+html.EscapeString(m[1:len(m)-1])+"</code>")
+		return fmt.Sprintf("\x00%d\x00", len(codeSpans)-1)
+	})
+	text = html.EscapeString(text)
+	text = linkPattern.ReplaceAllStringFunc(text, func(m string) string {
+		parts := linkPattern.FindStringSubmatch(m)
+		href := parts[2]
+		if !strings.HasPrefix(href, "http://") && !strings.HasPrefix(href, "https://") && !strings.HasPrefix(href, "mailto:") {
+			return parts[1] // Drop javascript: and other unsafe or relative targets
+		}
+		return fmt.Sprintf(`<a href="%s">%s</a>`, href, parts[1])
+	})
+	text = boldPattern.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = italicPattern.ReplaceAllString(text, "<em>$1$2</em>")
+	for i, span := range codeSpans {
+		text = strings.Replace(text, fmt.Sprintf("\x00%d\x00", i), span, 1)
+	}
+	return text
+}
+
+// stripInlineMarkdown removes emphasis and code markers from text, for plain-text uses
+// such as the HTML title.
+func stripInlineMarkdown(text string) string {
+	return strings.NewReplacer("**", "", "__", "", "`", "", "*", "").Replace(text)
+}
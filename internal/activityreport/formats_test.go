@@ -0,0 +1,127 @@
+package activityreport
+
+import "testing"
+
+func TestHeadingLevel(t *testing.T) {
+	testCases := []struct {
+		line      string
+		wantLevel int
+		wantText  string
+	}{
+		{"# Title", 1, "Title"},
+		{"### Section ###", 3, "Section"},
+		{"  ## Indented  ", 2, "Indented"},
+		{"###### Six", 6, "Six"},
+		{"####### Seven", 0, ""},
+		{"#NoSpace", 0, ""},
+		{"#", 1, ""},
+		{"Plain text", 0, ""},
+		{"", 0, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.line, func(t *testing.T) {
+			level, text := headingLevel(tc.line)
+			if level != tc.wantLevel || text != tc.wantText {
+				t.Errorf("Expected (%d, %q), got (%d, %q)", tc.wantLevel, tc.wantText, level, text)
+			}
+		})
+	}
+}
+
+func TestRenderInline(t *testing.T) {
+	testCases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"plain", "Nothing special", "Nothing special"},
+		{"bold", "**strong** and __also__", "<strong>strong</strong> and <strong>also</strong>"},
+		{"italic", "*one* and _two_", "<em>one</em> and <em>two</em>"},
+		{"code span", "run `go test *all*`", "run <code>go test *all*</code>"},
+		{"code span escaped", "`<b>`", "<code>&lt;b&gt;</code>"},
+		{"https link", "[docs](https://example.com/a)", `<a href="https://example.com/a">docs</a>`},
+		{"mailto link", "[mail](mailto:a@example.com)", `<a href="mailto:a@example.com">mail</a>`},
+		{"javascript link", "[click](javascript:alert%281%29)", "click"},
+		{"relative link", "[file](../secret)", "file"},
+		{"html escaped", `<script>alert("x")</script> & more`, "&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; &amp; more"},
+		{"quote in href escaped", `[x](https://e.com/"onclick=)`, `<a href="https://e.com/&#34;onclick=">x</a>`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := renderInline(tc.text); got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMarkdownToHTML(t *testing.T) {
+	testCases := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "headings and paragraphs",
+			markdown: "# Report\n\nFirst line\nsecond line\n\n## Details",
+			want:     "<h1>Report</h1>\n<p>First line second line</p>\n<h2>Details</h2>\n",
+		},
+		{
+			name:     "unordered list",
+			markdown: "- one\n* two\n+ **three**",
+			want:     "<ul>\n<li>one</li>\n<li>two</li>\n<li><strong>three</strong></li>\n</ul>\n",
+		},
+		{
+			name:     "ordered list after unordered list",
+			markdown: "- a\n1. b\n2) c",
+			want:     "<ul>\n<li>a</li>\n</ul>\n<ol>\n<li>b</li>\n<li>c</li>\n</ol>\n",
+		},
+		{
+			name:     "paragraph closes list",
+			markdown: "- a\ntext",
+			want:     "<ul>\n<li>a</li>\n</ul>\n<p>text</p>\n",
+		},
+		{
+			name:     "fenced code",
+			markdown: "Intro\n```go\n# not a heading\nif a < b && *c* {}\n```\nAfter",
+			want:     "<p>Intro</p>\n<pre><code># not a heading\nif a &lt; b &amp;&amp; *c* {}\n</code></pre>\n<p>After</p>\n",
+		},
+		{
+			name:     "unterminated fence",
+			markdown: "```\ncode",
+			want:     "<pre><code>code\n</code></pre>\n",
+		},
+		{
+			name:     "block quote and rule",
+			markdown: "> quoted _text_\n\n---",
+			want:     "<blockquote><p>quoted <em>text</em></p></blockquote>\n<hr>\n",
+		},
+		{
+			name:     "hash without space is text",
+			markdown: "#42 was merged",
+			want:     "<p>#42 was merged</p>\n",
+		},
+		{
+			name:     "html escaped",
+			markdown: "# <img src=x>\n<b>bold</b>",
+			want:     "<h1>&lt;img src=x&gt;</h1>\n<p>&lt;b&gt;bold&lt;/b&gt;</p>\n",
+		},
+		{
+			name:     "javascript link neutralized",
+			markdown: "- [run](JavaScript:void%280%29)",
+			want:     "<ul>\n<li>run</li>\n</ul>\n",
+		},
+		{
+			name:     "crlf line endings",
+			markdown: "# Title\r\n\r\nBody\r\n",
+			want:     "<h1>Title</h1>\n<p>Body</p>\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := markdownToHTML(tc.markdown); got != tc.want {
+				t.Errorf("Mismatch:\nExpected: %q\nActual:   %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package activityreport
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatterDelimiter opens and closes a YAML front-matter block.
+const frontMatterDelimiter = "---"
+
+// addFrontMatter prepends a YAML front-matter block to content when cfg.FrontMatter is set.
+// The title, date and period keys default to values computed from the run and logs;
+// entries in cfg.FrontMatter override them. Values are YAML-encoded, so a value
+// containing "---" or newlines cannot terminate the block early.
+func addFrontMatter(cfg *Config, logs []CommitLog, content string, now time.Time) (string, error) {
+	if cfg.FrontMatter == nil {
+		return content, nil
+	}
+	fields := map[string]string{"date": now.Format("2006-01-02")}
+	if cfg.ProjectName != "" {
+		fields["title"] = cfg.ProjectName + " activity report"
+	}
+	if period := logPeriod(logs); period != "" {
+		fields["period"] = period
+	}
+	for k, v := range cfg.FrontMatter {
+		fields[k] = v
+	}
+	encoded, err := yaml.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode report front matter: %w", err)
+	}
+
+	// The blank line after the closing delimiter keeps a leading "---" in the report
+	// (a thematic break) from being read as part of the block or as a heading underline.
+	content = strings.TrimLeft(content, "\n")
+	return frontMatterDelimiter + "\n" + string(encoded) + frontMatterDelimiter + "\n\n" + content, nil
+}
+
+// logPeriod returns "YYYY-MM-DD to YYYY-MM-DD" spanning the commit dates in logs,
+// or "" when no entry carries a parseable commit_date_time.
+func logPeriod(logs []CommitLog) string {
+	var dates []time.Time
+	for _, entry := range logs {
+		raw, ok := entry["commit_date_time"].(string)
+		if !ok {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			dates = append(dates, t.UTC())
+		}
+	}
+	if len(dates) == 0 {
+		return ""
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	return dates[0].Format("2006-01-02") + " to " + dates[len(dates)-1].Format("2006-01-02")
+}
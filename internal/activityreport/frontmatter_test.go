@@ -0,0 +1,103 @@
+package activityreport
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestAddFrontMatter(t *testing.T) {
+	now := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	logs := []CommitLog{
+		{"commit_date_time": "2024-03-05T10:00:00Z"},
+		{"commit_date_time": "2024-03-01T10:00:00+02:00"},
+		{"commit_date_time": "not a date"},
+	}
+	testCases := []struct {
+		name        string
+		projectName string
+		frontMatter map[string]string
+		logs        []CommitLog
+		want        string
+	}{
+		{
+			name:        "disabled",
+			projectName: "Acme",
+			want:        "\n# Report\n",
+		},
+		{
+			name:        "defaults",
+			projectName: "Acme",
+			frontMatter: map[string]string{},
+			logs:        logs,
+			want:        "---\ndate: \"2024-03-10\"\nperiod: 2024-03-01 to 2024-03-05\ntitle: Acme activity report\n---\n\n# Report\n",
+		},
+		{
+			name:        "overrides and extra keys",
+			projectName: "Acme",
+			frontMatter: map[string]string{"title": "Weekly", "tags": "weekly"},
+			want:        "---\ndate: \"2024-03-10\"\ntags: weekly\ntitle: Weekly\n---\n\n# Report\n",
+		},
+		{
+			name:        "values with colons and quotes",
+			projectName: "Acme: API",
+			frontMatter: map[string]string{"summary": `He said "ship it"`, "note": "'quoted'", "draft": "yes"},
+			want: "---\ndate: \"2024-03-10\"\ndraft: \"yes\"\nnote: '''quoted'''\n" +
+				"summary: He said \"ship it\"\ntitle: 'Acme: API activity report'\n---\n\n# Report\n",
+		},
+		{
+			name:        "delimiter inside a value",
+			frontMatter: map[string]string{"description": "one\n---\ntwo"},
+			want:        "---\ndate: \"2024-03-10\"\ndescription: |-\n    one\n    ---\n    two\n---\n\n# Report\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{ProjectName: tc.projectName, FrontMatter: tc.frontMatter}
+			got, err := addFrontMatter(cfg, tc.logs, "\n# Report\n", now)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Mismatch:\nExpected: %q\nActual:   %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestAddFrontMatterRoundTrip(t *testing.T) {
+	values := map[string]string{
+		"title":   "Acme: the API",
+		"quote":   `"double" and 'single'`,
+		"hash":    "# not a comment",
+		"list":    "[a, b]",
+		"number":  "0123",
+		"boolean": "no",
+		"block":   "line one\n---\nline two",
+	}
+	got, err := addFrontMatter(&Config{FrontMatter: values}, nil, "# Report\n", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	block, body, ok := strings.Cut(strings.TrimPrefix(got, frontMatterDelimiter+"\n"), "\n"+frontMatterDelimiter+"\n")
+	if !ok {
+		t.Fatalf("Expected a closed front-matter block, got %q", got)
+	}
+	if body != "\n# Report\n" {
+		t.Errorf("Expected the report after the block, got %q", body)
+	}
+	var decoded map[string]string
+	if err := yaml.Unmarshal([]byte(block), &decoded); err != nil {
+		t.Fatalf("Expected valid YAML, got %v:\n%s", err, block)
+	}
+	want := map[string]string{"date": "2024-03-10"}
+	for k, v := range values {
+		want[k] = v
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Errorf("Mismatch:\nExpected: %v\nActual:   %v", want, decoded)
+	}
+}
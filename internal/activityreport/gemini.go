@@ -0,0 +1,136 @@
+package activityreport
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+// #nosec G101 -- This is the name of an environment variable, not a credential itself.
+const apiKeyEnvVar = "VERTEX_AI_API_KEY" // Environment variable for the API key
+
+// #nosec G101 -- This is the name of an environment variable, not a credential itself.
+const credentialsFileEnvVar = "GOOGLE_APPLICATION_CREDENTIALS" // Environment variable for credentials file
+
+// GeminiGenerator generates reports with Google Gemini in a single chat session: the
+// system prompt is sent first, then each chunk, and the reply to the last chunk is the
+// report. Call Close when done.
+type GeminiGenerator struct {
+	client     *genai.Client
+	model      *genai.GenerativeModel
+	config     *Config
+	transcript *transcript
+	ack        chunkAck
+}
+
+// NewGeminiGenerator creates a Gemini client for cfg.GeminiModel. Credentials come from
+// cfg.CredentialsFile, then the GOOGLE_APPLICATION_CREDENTIALS file, then the
+// VERTEX_AI_API_KEY key; an error wrapping ErrNoCredentials is returned if none is set.
+func NewGeminiGenerator(ctx context.Context, cfg *Config) (*GeminiGenerator, error) {
+	var clientOpts []option.ClientOption
+
+	// Check for credentials file in config
+	if cfg.CredentialsFile != "" {
+		// Use credentials file from config
+		clientOpts = append(clientOpts, option.WithCredentialsFile(cfg.CredentialsFile))
+	} else {
+		// Check for credentials file in environment variable
+		credentialsPath := os.Getenv(credentialsFileEnvVar)
+		if credentialsPath != "" {
+			clientOpts = append(clientOpts, option.WithCredentialsFile(credentialsPath))
+		} else {
+			// Fall back to API key as last resort
+			apiKey := os.Getenv(apiKeyEnvVar)
+			if apiKey == "" {
+				return nil, fmt.Errorf("%w: neither credentials file specified in config/environment nor %s env var set", ErrNoCredentials, apiKeyEnvVar)
+			}
+			clientOpts = append(clientOpts, option.WithAPIKey(apiKey))
+		}
+	}
+
+	if cfg.APIEndpoint != "" {
+		endpoint, err := normalizeEndpoint(cfg.APIEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrConfig, err)
+		}
+		clientOpts = append(clientOpts, option.WithEndpoint(endpoint))
+	}
+
+	// Creating a new client with the generative-ai-go library
+	client, err := genai.NewClient(ctx, clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Gemini AI client: %w", err)
+	}
+	fmt.Printf("Initialized Gemini model %s\n", cfg.GeminiModel)
+	return &GeminiGenerator{client: client, model: client.GenerativeModel(cfg.GeminiModel), config: cfg}, nil
+}
+
+// Generate implements Generator.
+func (g *GeminiGenerator) Generate(ctx context.Context, systemPrompt string, chunks []string) (string, error) {
+	cs := g.model.StartChat()
+
+	fmt.Println("Sending initial prompt to Gemini...")
+	initialResp, err := g.send(ctx, cs, "initial prompt", systemPrompt)
+	g.transcript.record("initial prompt", systemPrompt, extractTextFromResponse(initialResp), err)
+	if err != nil {
+		return "", fmt.Errorf("failed to send initial prompt to Gemini: %w", err)
+	}
+
+	var finalResp *genai.GenerateContentResponse
+	for i, chunk := range chunks {
+		fmt.Printf("Sending chunk %d/%d to Gemini...\n", i+1, len(chunks))
+		label := fmt.Sprintf("chunk %d/%d", i+1, len(chunks))
+		resp, err := g.send(ctx, cs, label, chunk)
+		g.transcript.record(label, chunk, extractTextFromResponse(resp), err)
+		if err != nil {
+			return "", fmt.Errorf("failed to send chunk %d/%d to Gemini: %w", i+1, len(chunks), err)
+		}
+		g.ack.sent(i)
+		finalResp = resp // Store the last response
+	}
+	return extractTextFromResponse(finalResp), nil
+}
+
+// send sends text in the chat session, retrying transient errors.
+func (g *GeminiGenerator) send(ctx context.Context, cs *genai.ChatSession, what, text string) (*genai.GenerateContentResponse, error) {
+	var resp *genai.GenerateContentResponse
+	err := sendWithRetry(ctx, g.config, what, func() (err error) {
+		history := len(cs.History)
+		resp, err = cs.SendMessage(ctx, genai.Text(text))
+		if err != nil {
+			cs.History = cs.History[:history] // SendMessage keeps the failed turn; don't send it twice
+		}
+		return err
+	})
+	return resp, googleAuthError(err)
+}
+
+// Close releases the Gemini client.
+func (g *GeminiGenerator) Close() error {
+	return g.client.Close()
+}
+
+// extractTextFromResponse safely extracts the text content from the Gemini response.
+func extractTextFromResponse(resp *genai.GenerateContentResponse) string {
+	var builder strings.Builder
+	if resp == nil {
+		return ""
+	}
+
+	// Extract text from the response
+	for _, cand := range resp.Candidates {
+		if cand.Content != nil {
+			for _, part := range cand.Content.Parts {
+				if textPart, ok := part.(genai.Text); ok {
+					builder.WriteString(string(textPart))
+				}
+			}
+		}
+	}
+
+	return builder.String()
+}
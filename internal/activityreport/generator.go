@@ -0,0 +1,168 @@
+package activityreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Generator produces a report with a language model. systemPrompt carries the report
+// instructions and chunks the commit logs, as JSON arrays, in send order; the returned
+// text is the model's reply to the last chunk, i.e. the report.
+type Generator interface {
+	Generate(ctx context.Context, systemPrompt string, chunks []string) (string, error)
+}
+
+// chunkAck is told the index of each chunk the model has replied to, so that a run
+// failing midway still knows which commits were delivered. A nil chunkAck does nothing.
+type chunkAck func(chunk int)
+
+// sent reports that chunk was delivered.
+func (a chunkAck) sent(chunk int) {
+	if a != nil {
+		a(chunk)
+	}
+}
+
+// LLM providers accepted by the provider setting.
+const (
+	ProviderGemini    = "gemini" // The default
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+)
+
+// validateProvider checks the provider-specific settings of cfg.
+func validateProvider(cfg *Config) error {
+	switch cfg.Provider {
+	case "", ProviderGemini:
+		if cfg.ProjectID == "" {
+			return fmt.Errorf("project_id cannot be empty in config")
+		}
+		if cfg.Location == "" {
+			return fmt.Errorf("location cannot be empty in config")
+		}
+		if cfg.GeminiModel == "" {
+			return fmt.Errorf("gemini_model cannot be empty in config")
+		}
+		if !cfg.AllowUnknownModel {
+			// Warn only: the list lags behind newly released models, and the API rejects
+			// names that do not exist anyway.
+			if err := validateModel(cfg.GeminiModel); err != nil {
+				cfg.logger().Warn("gemini_model is not a known model, check it for typos", "error", err)
+			}
+		}
+		return nil
+	case ProviderOpenAI, ProviderAnthropic:
+		if cfg.Model == "" {
+			return fmt.Errorf("model cannot be empty in config for provider %q", cfg.Provider)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported provider %q (supported: %s, %s, %s)", cfg.Provider, ProviderGemini, ProviderOpenAI, ProviderAnthropic)
+	}
+}
+
+// newGenerator returns the Generator for cfg.Provider, recording every exchange in tr and
+// reporting each delivered chunk to ack. Generators that hold resources implement
+// io.Closer. Each request is retried on transient errors as configured by MaxRetries and
+// RetryBackoff.
+func newGenerator(ctx context.Context, cfg *Config, tr *transcript, ack chunkAck) (Generator, error) {
+	switch cfg.Provider {
+	case "", ProviderGemini:
+		gen, err := NewGeminiGenerator(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		gen.transcript = tr
+		gen.ack = ack
+		return gen, nil
+	case ProviderOpenAI:
+		gen, err := NewOpenAIGenerator(cfg)
+		if err != nil {
+			return nil, err
+		}
+		gen.transcript = tr
+		gen.ack = ack
+		return gen, nil
+	case ProviderAnthropic:
+		gen, err := NewAnthropicGenerator(cfg)
+		if err != nil {
+			return nil, err
+		}
+		gen.transcript = tr
+		gen.ack = ack
+		return gen, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported provider %q", ErrConfig, cfg.Provider)
+	}
+}
+
+// modelName returns the model cfg selects for its provider.
+func (c *Config) modelName() string {
+	if c.Provider == "" || c.Provider == ProviderGemini {
+		return c.GeminiModel
+	}
+	return c.Model
+}
+
+// providerBaseURL returns cfg.APIEndpoint as an https base URL, or defaultURL when unset.
+func providerBaseURL(cfg *Config, defaultURL string) (string, error) {
+	if cfg.APIEndpoint == "" {
+		return defaultURL, nil
+	}
+	hostPort, err := normalizeEndpoint(cfg.APIEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrConfig, err)
+	}
+	return "https://" + strings.TrimSuffix(hostPort, ":443"), nil
+}
+
+// httpClient returns cfg.HTTPClient, or a client with llmHTTPTimeout when unset.
+func (c *Config) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return &http.Client{Timeout: llmHTTPTimeout}
+}
+
+// llmHTTPTimeout bounds a single request to an HTTP-based provider; long reports can take
+// minutes to generate.
+const llmHTTPTimeout = 5 * time.Minute
+
+// maxErrorBodyBytes caps how much of an error response is quoted in errors.
+const maxErrorBodyBytes = 2048
+
+// postJSON sends payload as JSON to url with headers and decodes a successful response
+// into out. Non-2xx responses become *httpStatusError errors quoting the start of the body.
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return &httpStatusError{URL: url, Status: resp.Status, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(snippet))}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+package activityreport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPostJSON(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+	var gotMethod, gotContentType, gotToken string
+	var gotBody payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotContentType, gotToken = r.Method, r.Header.Get("Content-Type"), r.Header.Get("X-Token")
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		fmt.Fprint(w, `{"name":"pong"}`)
+	}))
+	defer server.Close()
+
+	var out payload
+	err := postJSON(context.Background(), server.Client(), server.URL, map[string]string{"X-Token": "secret"}, payload{Name: "ping"}, &out)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if gotMethod != http.MethodPost || gotContentType != "application/json" || gotToken != "secret" {
+		t.Errorf("Unexpected request: method %q, Content-Type %q, X-Token %q", gotMethod, gotContentType, gotToken)
+	}
+	if gotBody.Name != "ping" {
+		t.Errorf("Expected request body name ping, got %q", gotBody.Name)
+	}
+	if out.Name != "pong" {
+		t.Errorf("Expected response name pong, got %q", out.Name)
+	}
+}
+
+func TestPostJSONErrors(t *testing.T) {
+	testCases := []struct {
+		name      string
+		status    int
+		body      string
+		wantErr   error // Sentinel the error must wrap, or nil
+		wantFail  bool
+		transient bool
+	}{
+		{"unauthorized", http.StatusUnauthorized, `{"error":"invalid key"}`, ErrAuthRejected, true, false},
+		{"forbidden", http.StatusForbidden, `{"error":"no access"}`, ErrAuthRejected, true, false},
+		{"rate limited", http.StatusTooManyRequests, `{"error":"slow down"}`, ErrRateLimited, true, true},
+		{"server error", http.StatusInternalServerError, `{"error":"oops"}`, ErrProviderUnavailable, true, true},
+		{"service unavailable", http.StatusServiceUnavailable, `{"error":"overloaded"}`, ErrProviderUnavailable, true, true},
+		{"bad request", http.StatusBadRequest, `{"error":"bad"}`, nil, true, false},
+		{"invalid JSON reply", http.StatusOK, `not json`, nil, true, false},
+	}
+	sentinels := []error{ErrAuthRejected, ErrRateLimited, ErrProviderUnavailable}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			}))
+			defer server.Close()
+
+			var out map[string]interface{}
+			err := postJSON(context.Background(), server.Client(), server.URL, nil, map[string]string{}, &out)
+			if (err != nil) != tc.wantFail {
+				t.Fatalf("Expected error %t, got %v", tc.wantFail, err)
+			}
+			for _, sentinel := range sentinels {
+				if got := errors.Is(err, sentinel); got != (sentinel == tc.wantErr) {
+					t.Errorf("errors.Is(%v, %v) = %t", err, sentinel, got)
+				}
+			}
+			if got := isTransient(err); got != tc.transient {
+				t.Errorf("Expected transient %t, got %t", tc.transient, got)
+			}
+			var statusErr *httpStatusError
+			if tc.status != http.StatusOK {
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != tc.status || statusErr.Body != tc.body {
+					t.Errorf("Expected an *httpStatusError with status %d and body %q, got %#v", tc.status, tc.body, err)
+				}
+			}
+		})
+	}
+}
+
+// recordedRequest is one request received by a fake provider.
+type recordedRequest struct {
+	path    string
+	headers http.Header
+	body    map[string]interface{}
+}
+
+// fakeProvider starts a TLS server recording every request and answering the i-th one
+// (0-based) with replies[i].
+func fakeProvider(t *testing.T, replies ...string) (*httptest.Server, *[]recordedRequest) {
+	t.Helper()
+	var requests []recordedRequest
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := recordedRequest{path: r.URL.Path, headers: r.Header.Clone()}
+		if err := json.NewDecoder(r.Body).Decode(&req.body); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		if len(requests) >= len(replies) {
+			t.Errorf("Unexpected request %d", len(requests)+1)
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, replies[len(requests)])
+		requests = append(requests, req)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// roleContents returns the role and content of each message in a decoded request body.
+func roleContents(t *testing.T, body map[string]interface{}) [][2]string {
+	t.Helper()
+	messages, _ := body["messages"].([]interface{})
+	var out [][2]string
+	for _, m := range messages {
+		message, _ := m.(map[string]interface{})
+		role, _ := message["role"].(string)
+		content, _ := message["content"].(string)
+		out = append(out, [2]string{role, content})
+	}
+	return out
+}
+
+func TestOpenAIGenerator(t *testing.T) {
+	server, requests := fakeProvider(t,
+		`{"choices":[{"message":{"role":"assistant","content":"first"}}]}`,
+		`{"choices":[{"message":{"role":"assistant","content":"# Report"}}]}`,
+	)
+	gen, err := NewOpenAIGenerator(openAITestConfig(t, server))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	report, err := gen.Generate(context.Background(), "system prompt", []string{"chunk one", "chunk two"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report != "# Report" {
+		t.Errorf("Expected the reply to the last chunk, got %q", report)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(*requests))
+	}
+	last := (*requests)[1]
+	if last.path != "/v1/chat/completions" {
+		t.Errorf("Expected path /v1/chat/completions, got %q", last.path)
+	}
+	if got := last.headers.Get("Authorization"); got != "Bearer test-key" {
+		t.Errorf("Expected Authorization header %q, got %q", "Bearer test-key", got)
+	}
+	if got := last.body["model"]; got != "gpt-test" {
+		t.Errorf("Expected model gpt-test, got %v", got)
+	}
+	wantMessages := [][2]string{{"system", "system prompt"}, {"user", "chunk one"}, {"assistant", "first"}, {"user", "chunk two"}}
+	if got := roleContents(t, last.body); !reflect.DeepEqual(got, wantMessages) {
+		t.Errorf("Mismatch:\nExpected: %v\nActual:   %v", wantMessages, got)
+	}
+}
+
+func TestOpenAIGeneratorReplies(t *testing.T) {
+	testCases := []struct {
+		name  string
+		reply string
+		want  string
+	}{
+		{"first choice", `{"choices":[{"message":{"content":"one"}},{"message":{"content":"two"}}]}`, "one"},
+		{"empty choices", `{"choices":[]}`, ""},
+		{"no choices", `{}`, ""},
+		{"empty content", `{"choices":[{"message":{"role":"assistant","content":""}}]}`, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server, _ := fakeProvider(t, tc.reply)
+			gen, err := NewOpenAIGenerator(openAITestConfig(t, server))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			report, err := gen.Generate(context.Background(), "prompt", []string{"chunk"})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if report != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, report)
+			}
+		})
+	}
+}
+
+// anthropicTestConfig returns a configuration for the anthropic provider talking to server.
+func anthropicTestConfig(t *testing.T, server *httptest.Server) *Config {
+	t.Helper()
+	cfg := openAITestConfig(t, server)
+	t.Setenv(anthropicKeyEnvVar, "test-key")
+	cfg.Provider = ProviderAnthropic
+	cfg.Model = "claude-test"
+	return cfg
+}
+
+func TestAnthropicGenerator(t *testing.T) {
+	server, requests := fakeProvider(t,
+		`{"content":[{"type":"text","text":"first"}]}`,
+		`{"content":[{"type":"text","text":"# Report"},{"type":"tool_use","text":"ignored"},{"type":"text","text":" done"}]}`,
+	)
+	gen, err := NewAnthropicGenerator(anthropicTestConfig(t, server))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	report, err := gen.Generate(context.Background(), "system prompt", []string{"chunk one", "chunk two"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report != "# Report done" {
+		t.Errorf("Expected the text blocks of the last reply, got %q", report)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(*requests))
+	}
+	last := (*requests)[1]
+	if last.path != "/v1/messages" {
+		t.Errorf("Expected path /v1/messages, got %q", last.path)
+	}
+	if got := last.headers.Get("x-api-key"); got != "test-key" {
+		t.Errorf("Expected x-api-key header %q, got %q", "test-key", got)
+	}
+	if got := last.headers.Get("anthropic-version"); got != anthropicAPIVersion {
+		t.Errorf("Expected anthropic-version header %q, got %q", anthropicAPIVersion, got)
+	}
+	if got := last.body["model"]; got != "claude-test" {
+		t.Errorf("Expected model claude-test, got %v", got)
+	}
+	if got := last.body["system"]; got != "system prompt" {
+		t.Errorf("Expected system %q, got %v", "system prompt", got)
+	}
+	if got := last.body["max_tokens"]; got != float64(anthropicMaxTokens) {
+		t.Errorf("Expected max_tokens %d, got %v", anthropicMaxTokens, got)
+	}
+	wantMessages := [][2]string{{"user", "chunk one"}, {"assistant", "first"}, {"user", "chunk two"}}
+	if got := roleContents(t, last.body); !reflect.DeepEqual(got, wantMessages) {
+		t.Errorf("Mismatch:\nExpected: %v\nActual:   %v", wantMessages, got)
+	}
+}
+
+func TestAnthropicGeneratorReplies(t *testing.T) {
+	testCases := []struct {
+		name  string
+		reply string
+		want  string
+	}{
+		{"empty content", `{"content":[]}`, ""},
+		{"no text blocks", `{"content":[{"type":"tool_use"}]}`, ""},
+		{"empty text", `{"content":[{"type":"text","text":""}]}`, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server, _ := fakeProvider(t, tc.reply)
+			gen, err := NewAnthropicGenerator(anthropicTestConfig(t, server))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			report, err := gen.Generate(context.Background(), "prompt", []string{"chunk"})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if report != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, report)
+			}
+		})
+	}
+}
+
+func TestProviderAuthRejected(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"invalid x-api-key"}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	gen, err := NewAnthropicGenerator(anthropicTestConfig(t, server))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	_, err = gen.Generate(context.Background(), "prompt", []string{"chunk"})
+	if !errors.Is(err, ErrAuthRejected) {
+		t.Fatalf("Expected an error wrapping ErrAuthRejected, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "invalid x-api-key") {
+		t.Errorf("Expected the error to quote the response body, got %v", err)
+	}
+}
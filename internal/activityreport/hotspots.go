@@ -0,0 +1,40 @@
+package activityreport
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Stone-IT-Cloud/reporting/pkg/gitlogs"
+)
+
+// hotspotPrompt returns the prompt instructions listing the cfg.HotspotFiles files changed
+// most often in logs, for the report's risks section, or "" when disabled or no commit
+// lists files.
+func hotspotPrompt(cfg *Config, logs []CommitLog) string {
+	if cfg.HotspotFiles <= 0 {
+		return ""
+	}
+	entries := make([]gitlogs.LogEntry, 0, len(logs))
+	for _, entry := range logs {
+		email, _ := entry["author_email"].(string)
+		files, _ := entry["modified_files"].([]interface{})
+		e := gitlogs.LogEntry{AuthorEmail: email}
+		for _, f := range files {
+			if path, ok := f.(string); ok {
+				e.ModifiedFiles = append(e.ModifiedFiles, path)
+			}
+		}
+		entries = append(entries, e)
+	}
+	hotspots := gitlogs.RankHotspots(entries, cfg.HotspotFiles)
+	if len(hotspots) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("Include a Risks section. These files changed most often in the period (commits, distinct authors); mention the ones changed by several authors as maintenance risks:\n")
+	for _, h := range hotspots {
+		fmt.Fprintf(&b, "- %s (%d commits, %d authors)\n", h.Path, h.Changes, h.Authors)
+	}
+	return b.String()
+}
@@ -0,0 +1,54 @@
+package activityreport
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Stone-IT-Cloud/reporting/pkg/gitlogs"
+)
+
+// LanguageAuto, as report_language, writes the report in the language detected from
+// the commit messages.
+const LanguageAuto = "auto"
+
+// languageNames maps the codes returned by gitlogs.DetectCommitLanguage to the names
+// used in the prompt.
+var languageNames = map[string]string{
+	"en": "English",
+	"es": "Spanish",
+	"pt": "Portuguese",
+	"fr": "French",
+	"de": "German",
+	"it": "Italian",
+	"nl": "Dutch",
+}
+
+// reportLanguage resolves cfg.ReportLanguage to the language name given to the model,
+// or "" to leave the choice to the model. "auto" detects the language of the commit
+// subjects in logs and falls back to "" when it cannot be detected; ISO 639-1 codes are
+// expanded to names; anything else is used as given.
+func reportLanguage(cfg *Config, logs []CommitLog) (string, error) {
+	language := strings.TrimSpace(cfg.ReportLanguage)
+	if !strings.EqualFold(language, LanguageAuto) {
+		if name, ok := languageNames[strings.ToLower(language)]; ok {
+			return name, nil
+		}
+		return language, nil
+	}
+
+	entries := make([]gitlogs.LogEntry, 0, len(logs))
+	for _, entry := range logs {
+		message, _ := entry["commit_message"].(string)
+		entries = append(entries, gitlogs.LogEntry{Message: message})
+	}
+	code, err := gitlogs.DetectCommitLanguage(entries)
+	if errors.Is(err, gitlogs.ErrLanguageUndetected) {
+		cfg.logger().Info("could not detect commit message language; leaving report language to the model")
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	cfg.logger().Info("detected commit message language", "language", code)
+	return languageNames[code], nil
+}
@@ -0,0 +1,47 @@
+package activityreport
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// supportedGeminiModels lists the Gemini model identifiers gemini_model is checked against;
+// other names log a warning unless allow_unknown_model is set. Keep it in sync with
+// Google's published models.
+var supportedGeminiModels = map[string]struct{}{
+	"gemini-1.0-pro":            {},
+	"gemini-1.0-pro-001":        {},
+	"gemini-1.0-pro-002":        {},
+	"gemini-1.5-flash":          {},
+	"gemini-1.5-flash-001":      {},
+	"gemini-1.5-flash-002":      {},
+	"gemini-1.5-flash-latest":   {},
+	"gemini-1.5-flash-8b":       {},
+	"gemini-1.5-flash-8b-001":   {},
+	"gemini-1.5-pro":            {},
+	"gemini-1.5-pro-001":        {},
+	"gemini-1.5-pro-002":        {},
+	"gemini-1.5-pro-latest":     {},
+	"gemini-2.0-flash":          {},
+	"gemini-2.0-flash-001":      {},
+	"gemini-2.0-flash-lite":     {},
+	"gemini-2.0-flash-lite-001": {},
+	"gemini-2.5-flash":          {},
+	"gemini-2.5-pro":            {},
+}
+
+// validateModel checks name against supportedGeminiModels. The "models/" prefix
+// accepted by the API is ignored.
+func validateModel(name string) error {
+	if _, ok := supportedGeminiModels[strings.TrimPrefix(name, "models/")]; ok {
+		return nil
+	}
+	known := make([]string, 0, len(supportedGeminiModels))
+	for model := range supportedGeminiModels {
+		known = append(known, model)
+	}
+	sort.Strings(known)
+	return fmt.Errorf("unknown gemini_model %q (set allow_unknown_model: true to silence this); known models: %s",
+		name, strings.Join(known, ", "))
+}
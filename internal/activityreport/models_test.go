@@ -0,0 +1,69 @@
+package activityreport
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestValidateModel(t *testing.T) {
+	testCases := []struct {
+		name    string
+		model   string
+		wantErr bool
+	}{
+		{"known model", "gemini-1.5-flash-001", false},
+		{"known model with prefix", "models/gemini-2.0-flash", false},
+		{"typo", "gemini-1.5-por", true},
+		{"other provider's model", "gpt-4o", true},
+		{"different case", "Gemini-1.5-Pro", true},
+		{"empty", "", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateModel(tc.model)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error %t, got %v", tc.wantErr, err)
+			}
+			if err == nil {
+				return
+			}
+			msg := err.Error()
+			if !strings.Contains(msg, `"`+tc.model+`"`) || !strings.Contains(msg, "gemini-1.5-pro, ") || !strings.Contains(msg, "allow_unknown_model") {
+				t.Errorf("Expected the model, the known models and the escape hatch in the error, got %q", msg)
+			}
+		})
+	}
+}
+
+func TestValidateProviderUnknownModel(t *testing.T) {
+	testCases := []struct {
+		name        string
+		model       string
+		allow       bool
+		wantWarning bool
+	}{
+		{"known model", "gemini-1.5-pro", false, false},
+		{"unknown model", "gemini-1.5-por", false, true},
+		{"unknown model allowed", "gemini-9-ultra", true, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			cfg := &Config{
+				ProjectID:         "project",
+				Location:          "us-central1",
+				GeminiModel:       tc.model,
+				AllowUnknownModel: tc.allow,
+				Logger:            slog.New(slog.NewTextHandler(&logs, nil)),
+			}
+			if err := validateProvider(cfg); err != nil {
+				t.Fatalf("Expected an unknown model not to be an error, got %v", err)
+			}
+			if got := strings.Contains(logs.String(), "level=WARN"); got != tc.wantWarning {
+				t.Errorf("Expected a warning %t, got logs %q", tc.wantWarning, logs.String())
+			}
+		})
+	}
+}
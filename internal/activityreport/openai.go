@@ -0,0 +1,94 @@
+package activityreport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// #nosec G101 -- This is the name of an environment variable, not a credential itself.
+const openAIKeyEnvVar = "OPENAI_API_KEY"
+
+const openAIDefaultURL = "https://api.openai.com"
+
+// OpenAIGenerator generates reports with the OpenAI chat completions API. The system
+// prompt is sent as the system message and each chunk as a user turn, keeping the whole
+// conversation, so the reply to the last chunk is the report.
+type OpenAIGenerator struct {
+	model      string
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	config     *Config
+	transcript *transcript
+	ack        chunkAck
+}
+
+// NewOpenAIGenerator returns a generator for cfg.Model using the OPENAI_API_KEY key; an
+// error wrapping ErrNoCredentials is returned if it is not set. cfg.APIEndpoint, when
+// set, replaces api.openai.com.
+func NewOpenAIGenerator(cfg *Config) (*OpenAIGenerator, error) {
+	apiKey := os.Getenv(openAIKeyEnvVar)
+	if apiKey == "" {
+		return nil, fmt.Errorf("%w: %s env var not set", ErrNoCredentials, openAIKeyEnvVar)
+	}
+	baseURL, err := providerBaseURL(cfg, openAIDefaultURL)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Initialized OpenAI model %s\n", cfg.Model)
+	return &OpenAIGenerator{
+		model:      cfg.Model,
+		apiKey:     apiKey,
+		baseURL:    baseURL,
+		httpClient: cfg.httpClient(),
+		config:     cfg,
+	}, nil
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model    string          `json:"model"`
+	Messages []openAIMessage `json:"messages"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// Generate implements Generator.
+func (g *OpenAIGenerator) Generate(ctx context.Context, systemPrompt string, chunks []string) (string, error) {
+	messages := []openAIMessage{{Role: "system", Content: systemPrompt}}
+	g.transcript.record("system prompt", systemPrompt, "", nil) // Sent along with every chunk
+	reply := ""
+	for i, chunk := range chunks {
+		fmt.Printf("Sending chunk %d/%d to OpenAI...\n", i+1, len(chunks))
+		messages = append(messages, openAIMessage{Role: "user", Content: chunk})
+		label := fmt.Sprintf("chunk %d/%d", i+1, len(chunks))
+		var resp openAIResponse
+		err := sendWithRetry(ctx, g.config, label, func() error {
+			resp = openAIResponse{}
+			return postJSON(ctx, g.httpClient, g.baseURL+"/v1/chat/completions",
+				map[string]string{"Authorization": "Bearer " + g.apiKey},
+				openAIRequest{Model: g.model, Messages: messages}, &resp)
+		})
+		reply = ""
+		if err == nil && len(resp.Choices) > 0 {
+			reply = resp.Choices[0].Message.Content
+		}
+		g.transcript.record(label, chunk, reply, err)
+		if err != nil {
+			return "", fmt.Errorf("failed to send chunk %d/%d to OpenAI: %w", i+1, len(chunks), err)
+		}
+		g.ack.sent(i)
+		messages = append(messages, openAIMessage{Role: "assistant", Content: reply})
+	}
+	return reply, nil
+}
@@ -0,0 +1,46 @@
+package activityreport
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// promptTemplateData is what a prompt template file can refer to, e.g. {{.ProjectName}}.
+type promptTemplateData struct {
+	ProjectName    string
+	ReportLanguage string
+}
+
+// promptTemplate returns the report instructions the prompt starts with: the contents of
+// cfg.PromptTemplatePath when set, rendered as a text/template with promptTemplateData,
+// otherwise the built-in initialPromptBase.
+func promptTemplate(cfg *Config) (string, error) {
+	if cfg.PromptTemplatePath == "" {
+		return initialPromptBase, nil
+	}
+	path := filepath.Clean(cfg.PromptTemplatePath)
+	// #nosec G304 -- The template path comes from the user's own configuration.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to read prompt template %s: %w", ErrConfig, path, err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return "", fmt.Errorf("%w: prompt template %s is empty", ErrConfig, path)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Parse(string(data))
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to parse prompt template %s: %w", ErrConfig, path, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, promptTemplateData{ProjectName: cfg.ProjectName, ReportLanguage: cfg.ReportLanguage}); err != nil {
+		return "", fmt.Errorf("%w: failed to render prompt template %s: %w", ErrConfig, path, err)
+	}
+	prompt := b.String()
+	if !strings.HasSuffix(prompt, "\n") {
+		prompt += "\n" // The generated instructions follow on their own lines
+	}
+	return prompt, nil
+}
@@ -0,0 +1,55 @@
+package activityreport
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPromptTemplate(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write template: %v", err)
+		}
+		return path
+	}
+	testCases := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr string
+	}{
+		{"built-in", "", initialPromptBase, ""},
+		{"plain text", writeTemplate("plain.txt", "Summarize the work."), "Summarize the work.\n", ""},
+		{"trailing newline kept", writeTemplate("newline.txt", "Summarize the work.\n"), "Summarize the work.\n", ""},
+		{"fields", writeTemplate("fields.txt", "Report on {{.ProjectName}} in {{.ReportLanguage}}."), "Report on Acme in es.\n", ""},
+		{"missing file", filepath.Join(dir, "missing.txt"), "", "failed to read prompt template"},
+		{"empty file", writeTemplate("empty.txt", " \n"), "", "is empty"},
+		{"unclosed action", writeTemplate("unclosed.txt", "Report on {{.ProjectName"), "", "failed to parse prompt template"},
+		{"unknown function", writeTemplate("function.txt", "{{upper .ProjectName}}"), "", "failed to parse prompt template"},
+		{"unknown field", writeTemplate("field.txt", "Report for {{.Client}}"), "", "failed to render prompt template"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{PromptTemplatePath: tc.path, ProjectName: "Acme", ReportLanguage: "es"}
+			got, err := promptTemplate(cfg)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if got != tc.want {
+					t.Errorf("Expected %q, got %q", tc.want, got)
+				}
+				return
+			}
+			if !errors.Is(err, ErrConfig) || !strings.Contains(err.Error(), tc.wantErr) || !strings.Contains(err.Error(), tc.path) {
+				t.Errorf("Expected a configuration error naming %s and containing %q, got %v", tc.path, tc.wantErr, err)
+			}
+		})
+	}
+}
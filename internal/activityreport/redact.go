@@ -0,0 +1,16 @@
+package activityreport
+
+import "github.com/Stone-IT-Cloud/reporting/pkg/gitlogs"
+
+// redactCommitLogs masks, in place, the author email of each entry and any email address
+// in its commit message, so they never reach the model or the saved report.
+func redactCommitLogs(logs []CommitLog) {
+	for _, entry := range logs {
+		if email, ok := entry["author_email"].(string); ok {
+			entry["author_email"] = gitlogs.RedactEmail(email)
+		}
+		if message, ok := entry["commit_message"].(string); ok {
+			entry["commit_message"] = gitlogs.RedactEmailsIn(message)
+		}
+	}
+}
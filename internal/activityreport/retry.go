@@ -0,0 +1,170 @@
+package activityreport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Defaults for MaxRetries and RetryBackoff.
+const (
+	defaultMaxRetries   = 3
+	defaultRetryBackoff = 2 * time.Second
+	// maxRetryDelay caps the exponential backoff between two attempts.
+	maxRetryDelay = time.Minute
+)
+
+// maxRetries returns the configured retry count: MaxRetries, defaultMaxRetries when
+// unset, and zero when negative.
+func (c *Config) maxRetries() int {
+	switch {
+	case c.MaxRetries < 0:
+		return 0
+	case c.MaxRetries == 0:
+		return defaultMaxRetries
+	}
+	return c.MaxRetries
+}
+
+// retryBackoff returns the delay before the first retry.
+func (c *Config) retryBackoff() time.Duration {
+	if c.RetryBackoff <= 0 {
+		return defaultRetryBackoff
+	}
+	return c.RetryBackoff
+}
+
+// Errors wrapped by the errors of the HTTP-based providers for the matching statuses.
+var (
+	// ErrAuthRejected is wrapped when the provider rejects the credentials (401, 403).
+	ErrAuthRejected = errors.New("provider rejected the credentials")
+	// ErrRateLimited is wrapped when the provider rate limits the request (429).
+	ErrRateLimited = errors.New("provider rate limit exceeded")
+	// ErrProviderUnavailable is wrapped when the provider fails with a 5xx status.
+	ErrProviderUnavailable = errors.New("provider unavailable")
+)
+
+// httpStatusError is returned by postJSON for non-2xx responses. It unwraps to
+// ErrAuthRejected, ErrRateLimited or ErrProviderUnavailable for the matching statuses.
+type httpStatusError struct {
+	URL        string
+	Status     string
+	StatusCode int
+	Body       string // Start of the response body
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("request to %s failed with status %s: %s", e.URL, e.Status, e.Body)
+}
+
+func (e *httpStatusError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrAuthRejected
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= 500 && e.StatusCode <= 599:
+		return ErrProviderUnavailable
+	}
+	return nil
+}
+
+// isTransient reports whether err is worth retrying: rate limiting, server overload or
+// unavailability, and network timeouts. Everything else (bad requests, authentication,
+// quota exhausted for good) fails on every attempt anyway.
+func isTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return transientHTTPStatus(statusErr.StatusCode)
+	}
+	var googleErr *googleapi.Error
+	if errors.As(err, &googleErr) {
+		return transientHTTPStatus(googleErr.Code)
+	}
+	var httpCoder interface{ HTTPCode() int } // apierror.APIError from the Google client libraries
+	if errors.As(err, &httpCoder) && httpCoder.HTTPCode() > 0 {
+		return transientHTTPStatus(httpCoder.HTTPCode())
+	}
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded, codes.Aborted:
+			return true
+		}
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// transientHTTPStatus reports whether an HTTP status code is worth retrying.
+func transientHTTPStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// googleAuthError wraps err with ErrAuthRejected when a Google client error reports
+// rejected credentials, as httpStatusError does for the other providers.
+func googleAuthError(err error) error {
+	if err == nil || errors.Is(err, ErrAuthRejected) {
+		return err
+	}
+	code := 0
+	var googleErr *googleapi.Error
+	var httpCoder interface{ HTTPCode() int }
+	switch {
+	case errors.As(err, &googleErr):
+		code = googleErr.Code
+	case errors.As(err, &httpCoder):
+		code = httpCoder.HTTPCode()
+	}
+	if code == http.StatusUnauthorized || code == http.StatusForbidden {
+		return fmt.Errorf("%w: %w", ErrAuthRejected, err)
+	}
+	if s, ok := status.FromError(err); ok && (s.Code() == codes.Unauthenticated || s.Code() == codes.PermissionDenied) {
+		return fmt.Errorf("%w: %w", ErrAuthRejected, err)
+	}
+	return err
+}
+
+// sendWithRetry calls send until it succeeds, fails with an error that is not transient,
+// or cfg.maxRetries() retries are used up. Retries wait an exponentially growing delay,
+// starting at cfg.retryBackoff(), with jitter so that parallel runs do not retry in
+// lockstep. what names the request in log messages. The last error is returned.
+func sendWithRetry(ctx context.Context, cfg *Config, what string, send func() error) error {
+	retries, backoff := cfg.maxRetries(), cfg.retryBackoff()
+	for attempt := 0; ; attempt++ {
+		err := send()
+		if err == nil || attempt >= retries || !isTransient(err) || ctx.Err() != nil {
+			if err != nil && attempt > 0 {
+				return fmt.Errorf("%w (after %d attempts)", err, attempt+1)
+			}
+			return err
+		}
+		delay := backoff << attempt
+		if delay <= 0 || delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+		delay = delay/2 + rand.N(delay/2+1) // #nosec G404 -- Jitter needs no cryptographic randomness.
+		cfg.logger().Warn("transient error from the model, retrying", "request", what, "attempt", attempt+1, "delay", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
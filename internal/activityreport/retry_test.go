@@ -0,0 +1,104 @@
+package activityreport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// flakyGenerator fails its first failures calls with err, then replies "report".
+type flakyGenerator struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (g *flakyGenerator) Generate(ctx context.Context, systemPrompt string, chunks []string) (string, error) {
+	g.calls++
+	if g.calls <= g.failures {
+		return "", g.err
+	}
+	return "report", nil
+}
+
+func TestSendWithRetry(t *testing.T) {
+	unavailable := &httpStatusError{Status: "503 Service Unavailable", StatusCode: http.StatusServiceUnavailable}
+	badRequest := &httpStatusError{Status: "400 Bad Request", StatusCode: http.StatusBadRequest}
+	testCases := []struct {
+		name       string
+		maxRetries int
+		gen        *flakyGenerator
+		wantErr    bool
+		wantCalls  int
+	}{
+		{"transient errors then success", 0, &flakyGenerator{failures: 2, err: unavailable}, false, 3},
+		{"retries used up", 1, &flakyGenerator{failures: 2, err: unavailable}, true, 2},
+		{"retries disabled", -1, &flakyGenerator{failures: 2, err: unavailable}, true, 1},
+		{"permanent error", 0, &flakyGenerator{failures: 2, err: badRequest}, true, 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				MaxRetries:   tc.maxRetries,
+				RetryBackoff: time.Millisecond,
+				Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+			}
+			var report string
+			err := sendWithRetry(context.Background(), cfg, "chunk 1/1", func() (err error) {
+				report, err = tc.gen.Generate(context.Background(), "prompt", []string{"[]"})
+				return err
+			})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error %t, got %v", tc.wantErr, err)
+			}
+			if err != nil && !errors.Is(err, tc.gen.err) {
+				t.Errorf("Expected the last error to be returned, got %v", err)
+			}
+			if err == nil && report != "report" {
+				t.Errorf("Expected the successful reply, got %q", report)
+			}
+			if tc.gen.calls != tc.wantCalls {
+				t.Errorf("Expected %d calls, got %d", tc.wantCalls, tc.gen.calls)
+			}
+		})
+	}
+}
+
+func TestGoogleAuthError(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"googleapi unauthorized", &googleapi.Error{Code: http.StatusUnauthorized}, true},
+		{"googleapi forbidden", fmt.Errorf("send: %w", &googleapi.Error{Code: http.StatusForbidden}), true},
+		{"googleapi rate limited", &googleapi.Error{Code: http.StatusTooManyRequests}, false},
+		{"grpc unauthenticated", status.Error(codes.Unauthenticated, "bad key"), true},
+		{"grpc permission denied", status.Error(codes.PermissionDenied, "no access"), true},
+		{"grpc unavailable", status.Error(codes.Unavailable, "down"), false},
+		{"other error", errors.New("boom"), false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := googleAuthError(tc.err)
+			if got := errors.Is(err, ErrAuthRejected); got != tc.want {
+				t.Errorf("Expected ErrAuthRejected %t, got %t (%v)", tc.want, got, err)
+			}
+			if !errors.Is(err, tc.err) {
+				t.Errorf("Expected the original error to be kept, got %v", err)
+			}
+		})
+	}
+	if googleAuthError(nil) != nil {
+		t.Error("Expected nil for a nil error")
+	}
+}
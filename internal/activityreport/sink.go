@@ -0,0 +1,111 @@
+package activityreport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"google.golang.org/api/option"
+	storage "google.golang.org/api/storage/v1"
+)
+
+// gcsScheme is the output path prefix that routes reports to Google Cloud Storage.
+const gcsScheme = "gs://"
+
+// stdoutPath is the output path that writes reports to standard output.
+const stdoutPath = "-"
+
+// ReportSink is a destination for generated report files.
+type ReportSink interface {
+	// Write stores content under name. The meaning of name depends on the sink:
+	// a file path for FileSink, an object name for GCSSink; StdoutSink ignores it.
+	Write(ctx context.Context, name string, content []byte) error
+}
+
+// FileSink writes reports to the local filesystem.
+type FileSink struct{}
+
+// Write saves content to the file at name with owner-only permissions.
+func (FileSink) Write(_ context.Context, name string, content []byte) error {
+	if err := os.WriteFile(name, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write report file %s: %w", name, err)
+	}
+	return nil
+}
+
+// StdoutSink writes reports to Writer, or to standard output when Writer is nil.
+type StdoutSink struct {
+	Writer io.Writer
+}
+
+// Write copies content to the sink's writer.
+func (s StdoutSink) Write(_ context.Context, _ string, content []byte) error {
+	w := s.Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	if _, err := w.Write(content); err != nil {
+		return fmt.Errorf("failed to write report to standard output: %w", err)
+	}
+	return nil
+}
+
+// GCSSink uploads reports as objects into a Google Cloud Storage bucket.
+type GCSSink struct {
+	Bucket     string
+	ClientOpts []option.ClientOption // Optional: credentials/endpoint for the storage client; ADC if empty.
+}
+
+// Write uploads content as the object name inside the sink's bucket.
+func (s GCSSink) Write(ctx context.Context, name string, content []byte) error {
+	svc, err := storage.NewService(ctx, s.ClientOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to initialize GCS client: %w", err)
+	}
+	contentType := "text/markdown; charset=utf-8"
+	if strings.HasSuffix(name, "."+FormatHTML) {
+		contentType = "text/html; charset=utf-8"
+	}
+	obj := &storage.Object{Name: name, ContentType: contentType}
+	if _, err := svc.Objects.Insert(s.Bucket, obj).Media(bytes.NewReader(content)).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to upload report to %s%s/%s: %w", gcsScheme, s.Bucket, name, err)
+	}
+	return nil
+}
+
+// resolveSink picks the sink for outputPath based on its scheme and returns the
+// name to pass to Write. Plain paths map to FileSink, "-" to StdoutSink and
+// gs://bucket/object to GCSSink.
+func resolveSink(cfg *Config, outputPath string) (ReportSink, string, error) {
+	if outputPath == stdoutPath {
+		return StdoutSink{}, outputPath, nil
+	}
+	if !strings.HasPrefix(outputPath, gcsScheme) {
+		return FileSink{}, outputPath, nil
+	}
+	bucket, object, _ := strings.Cut(strings.TrimPrefix(outputPath, gcsScheme), "/")
+	if bucket == "" || object == "" {
+		return nil, "", fmt.Errorf("invalid GCS output path %q: expected gs://bucket/object", outputPath)
+	}
+	// API keys cannot authorize object writes, so only credential files are forwarded;
+	// otherwise the storage client falls back to Application Default Credentials.
+	var clientOpts []option.ClientOption
+	if cfg.CredentialsFile != "" {
+		clientOpts = append(clientOpts, option.WithCredentialsFile(cfg.CredentialsFile))
+	} else if credentialsPath := os.Getenv(credentialsFileEnvVar); credentialsPath != "" {
+		clientOpts = append(clientOpts, option.WithCredentialsFile(credentialsPath))
+	}
+	return GCSSink{Bucket: bucket, ClientOpts: clientOpts}, object, nil
+}
+
+// writeReport stores content at outputPath through the sink matching its scheme.
+func writeReport(ctx context.Context, cfg *Config, outputPath string, content []byte) error {
+	sink, name, err := resolveSink(cfg, outputPath)
+	if err != nil {
+		return err
+	}
+	return sink.Write(ctx, name, content)
+}
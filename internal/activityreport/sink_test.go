@@ -0,0 +1,103 @@
+package activityreport
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestResolveSink(t *testing.T) {
+	t.Setenv(credentialsFileEnvVar, "")
+	reportPath := filepath.Join(t.TempDir(), "report.md")
+	testCases := []struct {
+		name       string
+		outputPath string
+		wantSink   ReportSink
+		wantName   string
+		wantErr    bool
+	}{
+		{"file", reportPath, FileSink{}, reportPath, false},
+		{"relative file", "report.md", FileSink{}, "report.md", false},
+		{"stdout", "-", StdoutSink{}, "-", false},
+		{"gcs object", "gs://reports/acme/2024.md", GCSSink{Bucket: "reports"}, "acme/2024.md", false},
+		{"gcs without object", "gs://reports", nil, "", true},
+		{"gcs with empty object", "gs://reports/", nil, "", true},
+		{"gcs without bucket", "gs:///2024.md", nil, "", true},
+		{"gcs scheme only", "gs://", nil, "", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sink, name, err := resolveSink(&Config{}, tc.outputPath)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error %t, got %v", tc.wantErr, err)
+			}
+			if err != nil {
+				if !strings.Contains(err.Error(), tc.outputPath) {
+					t.Errorf("Expected the output path in the error, got %q", err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(sink, tc.wantSink) || name != tc.wantName {
+				t.Errorf("Expected (%#v, %q), got (%#v, %q)", tc.wantSink, tc.wantName, sink, name)
+			}
+		})
+	}
+}
+
+func TestFileSinkWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.md")
+	if err := (FileSink{}).Write(context.Background(), path, []byte("# Report\n")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil || string(content) != "# Report\n" {
+		t.Fatalf("Expected the report in %s, got %q (err: %v)", path, content, err)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil || info.Mode().Perm() != 0o600 {
+			t.Errorf("Expected owner-only permissions, got %v (err: %v)", info.Mode().Perm(), err)
+		}
+	}
+
+	missing := filepath.Join(dir, "missing", "report.md")
+	err = (FileSink{}).Write(context.Background(), missing, []byte("# Report\n"))
+	if err == nil || !strings.Contains(err.Error(), missing) {
+		t.Errorf("Expected an error naming %s, got %v", missing, err)
+	}
+}
+
+func TestStdoutSinkWrite(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (StdoutSink{Writer: &buf}).Write(context.Background(), stdoutPath, []byte("# Report\n")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if buf.String() != "# Report\n" {
+		t.Errorf("Expected the report verbatim, got %q", buf.String())
+	}
+}
+
+func TestFormatPath(t *testing.T) {
+	testCases := []struct {
+		outputPath string
+		want       string
+	}{
+		{"reports/report.md", "reports/report.html"},
+		{"reports/report", "reports/report.html"},
+		{"gs://reports/acme.md", "gs://reports/acme.html"},
+		{"-", "-"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.outputPath, func(t *testing.T) {
+			if got := formatPath(tc.outputPath, FormatHTML); got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package activityreport
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Stone-IT-Cloud/reporting/pkg/gitlogs"
+)
+
+// transcriptEntry is one prompt/response exchange with the model.
+type transcriptEntry struct {
+	Label    string
+	SentAt   time.Time
+	Prompt   string
+	Response string
+	Err      string // Empty if the exchange succeeded
+}
+
+// transcript records every exchange of a report run so it can be audited later.
+// A nil *transcript is valid and records nothing.
+type transcript struct {
+	path    string
+	redact  bool             // Mask email addresses, as Config.RedactEmails does for the prompts
+	now     func() time.Time // Clock for the entry timestamps
+	entries []transcriptEntry
+}
+
+// newTranscript returns a transcript that will be written to path, or nil if path is empty.
+// With redact, every email address in prompts, responses and errors is masked.
+func newTranscript(path string, redact bool, now func() time.Time) *transcript {
+	if path == "" {
+		return nil
+	}
+	return &transcript{path: path, redact: redact, now: now}
+}
+
+// record appends an exchange. Either response or err is expected to be set.
+func (t *transcript) record(label, prompt, response string, err error) {
+	if t == nil {
+		return
+	}
+	errText := ""
+	if err != nil {
+		errText = err.Error()
+	}
+	if t.redact {
+		prompt, response, errText = gitlogs.RedactEmailsIn(prompt), gitlogs.RedactEmailsIn(response), gitlogs.RedactEmailsIn(errText)
+	}
+	t.entries = append(t.entries, transcriptEntry{Label: label, SentAt: t.now().UTC(), Prompt: prompt, Response: response, Err: errText})
+}
+
+// write saves the transcript as plain text. Nothing is written for a nil transcript.
+func (t *transcript) write() error {
+	if t == nil {
+		return nil
+	}
+	var b strings.Builder
+	for _, e := range t.entries {
+		fmt.Fprintf(&b, "===== %s | %s =====\n", e.Label, e.SentAt.Format(time.RFC3339))
+		fmt.Fprintf(&b, "----- PROMPT -----\n%s\n", e.Prompt)
+		if e.Err != "" {
+			fmt.Fprintf(&b, "----- ERROR -----\n%s\n\n", e.Err)
+			continue
+		}
+		fmt.Fprintf(&b, "----- RESPONSE -----\n%s\n\n", e.Response)
+	}
+	if err := os.WriteFile(t.path, []byte(b.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to write transcript file %s: %w", t.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package activityreport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTranscriptRedactEmails(t *testing.T) {
+	testCases := []struct {
+		name       string
+		redact     bool
+		wantRaw    bool
+		wantMasked bool
+	}{
+		{"redaction on", true, false, true},
+		{"redaction off", false, true, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests == 3 { // The error body echoes an address, as some APIs do
+					http.Error(w, `{"error":"quota exceeded for carol@example.com"}`, http.StatusBadRequest)
+					return
+				}
+				fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Alice (alice@example.com) and bob@example.com shipped."}}]}`)
+			}))
+			defer server.Close()
+
+			cfg := openAITestConfig(t, server)
+			cfg.RedactEmails = tc.redact
+			cfg.TranscriptPath = filepath.Join(t.TempDir(), "transcript.txt")
+			if _, err := GenerateReportWithConfig(context.Background(), testCommitLogsJSON(t, 5), cfg, ""); err == nil {
+				t.Fatal("Expected the third chunk to fail")
+			}
+
+			data, err := os.ReadFile(cfg.TranscriptPath)
+			if err != nil {
+				t.Fatalf("Failed to read transcript: %v", err)
+			}
+			transcript := string(data)
+			for _, email := range []string{"alice@example.com", "bob@example.com", "carol@example.com"} {
+				if got := strings.Contains(transcript, email); got != tc.wantRaw {
+					t.Errorf("Expected %s in the transcript: %t, got %t", email, tc.wantRaw, got)
+				}
+			}
+			for _, masked := range []string{"a***@example.com", "b***@example.com", "c***@example.com"} {
+				if got := strings.Contains(transcript, masked); got != tc.wantMasked {
+					t.Errorf("Expected %s in the transcript: %t, got %t", masked, tc.wantMasked, got)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateReportClock(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"# Report"}}]}`)
+	}))
+	defer server.Close()
+
+	cfg := openAITestConfig(t, server)
+	cfg.Now = func() time.Time { return time.Date(2024, 3, 10, 23, 30, 0, 0, time.FixedZone("PST", -8*3600)) }
+	cfg.FrontMatter = map[string]string{}
+	cfg.TranscriptPath = filepath.Join(t.TempDir(), "transcript.txt")
+	reportPath := filepath.Join(t.TempDir(), "report.md")
+	if _, err := GenerateReportWithConfig(context.Background(), testCommitLogsJSON(t, 1), cfg, reportPath); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	report, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	if !strings.Contains(string(report), "date: \"2024-03-10\"\n") {
+		t.Errorf("Expected the front-matter date of the injected clock, got:\n%s", report)
+	}
+	transcript, err := os.ReadFile(cfg.TranscriptPath)
+	if err != nil {
+		t.Fatalf("Failed to read transcript: %v", err)
+	}
+	if !strings.Contains(string(transcript), "| 2024-03-11T07:30:00Z =====") {
+		t.Errorf("Expected transcript timestamps from the injected clock in UTC, got:\n%s", transcript)
+	}
+}
@@ -0,0 +1,118 @@
+package activityreport
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Values accepted by on_overflow.
+const (
+	OverflowTruncate = "truncate"
+	OverflowError    = "error"
+)
+
+// truncationNote is appended to reports cut down to max_report_bytes.
+const truncationNote = "\n\n---\n\n*Report truncated: the generated report exceeded the configured maximum length.*\n"
+
+// minReportBytes is the smallest max_report_bytes accepted, leaving room for a title
+// and the truncation note.
+const minReportBytes = 256
+
+// validateOverflow checks the max_report_bytes and on_overflow settings.
+func validateOverflow(maxBytes int, onOverflow string) error {
+	if maxBytes < 0 {
+		return fmt.Errorf("max_report_bytes cannot be negative")
+	}
+	if maxBytes > 0 && maxBytes < minReportBytes {
+		return fmt.Errorf("max_report_bytes must be at least %d, got %d", minReportBytes, maxBytes)
+	}
+	switch onOverflow {
+	case "", OverflowTruncate, OverflowError:
+		return nil
+	default:
+		return fmt.Errorf("invalid on_overflow %q: must be %q or %q", onOverflow, OverflowTruncate, OverflowError)
+	}
+}
+
+// enforceMaxReportBytes applies cfg.MaxReportBytes to report. Oversized reports are
+// truncated (see truncateReport) unless on_overflow is "error", in which case an error
+// is returned instead.
+func enforceMaxReportBytes(cfg *Config, report string) (string, error) {
+	if cfg.MaxReportBytes <= 0 || len(report) <= cfg.MaxReportBytes {
+		return report, nil
+	}
+	if cfg.OnOverflow == OverflowError {
+		return "", fmt.Errorf("generated report is %d bytes, over max_report_bytes (%d)", len(report), cfg.MaxReportBytes)
+	}
+	cfg.logger().Warn("truncating oversized report", "bytes", len(report), "max_report_bytes", cfg.MaxReportBytes)
+	return truncateReport(report, cfg.MaxReportBytes), nil
+}
+
+// fenceClose closes a fenced code block left open by a truncation.
+const fenceClose = "\n```"
+
+// truncateReport cuts report so that, with truncationNote appended, it fits in maxBytes.
+// It cuts before the last Markdown heading that fits, so whole sections are kept; failing
+// that, at the last paragraph or line break; and as a last resort at a rune boundary.
+// Lines inside fenced code blocks are not headings, and a code block left open by the
+// cut is closed.
+func truncateReport(report string, maxBytes int) string {
+	if len(report) <= maxBytes {
+		return report
+	}
+	if maxBytes <= 0 {
+		return ""
+	}
+	budget := maxBytes - len(truncationNote) - len(fenceClose)
+	if budget <= 0 {
+		return truncationNote[:min(maxBytes, len(truncationNote))]
+	}
+	for budget > 0 && !utf8.RuneStart(report[budget]) {
+		budget-- // Do not split a multi-byte character
+	}
+	head := report[:budget]
+
+	cut := lastHeadingStart(head)
+	if cut <= 0 {
+		cut = strings.LastIndex(head, "\n\n")
+	}
+	if cut <= 0 {
+		cut = strings.LastIndex(head, "\n")
+	}
+	if cut <= 0 {
+		cut = len(head)
+	}
+	truncated := strings.TrimRight(head[:cut], "\n")
+	if endsInCodeBlock(truncated) {
+		truncated += fenceClose
+	}
+	return truncated + truncationNote
+}
+
+// lastHeadingStart returns the offset of the line break before the last heading line of
+// text outside fenced code blocks, or -1 if there is none after the first line.
+func lastHeadingStart(text string) int {
+	last, offset, inCode := -1, 0, false
+	for _, line := range strings.SplitAfter(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+		} else if level, _ := headingLevel(trimmed); level > 0 && !inCode && offset > 0 {
+			last = offset - 1
+		}
+		offset += len(line)
+	}
+	return last
+}
+
+// endsInCodeBlock reports whether text ends inside a fenced code block.
+func endsInCodeBlock(text string) bool {
+	inCode := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+		}
+	}
+	return inCode
+}
@@ -0,0 +1,161 @@
+package activityreport
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// budgetBytes returns the max_report_bytes leaving budget bytes of report text before the
+// truncation note.
+func budgetBytes(budget int) int {
+	return budget + len(truncationNote) + len(fenceClose)
+}
+
+func TestTruncateReport(t *testing.T) {
+	sectionA := "## A\n" + strings.Repeat("a", 40) + "\n\n"
+	sectionB := "## B\n" + strings.Repeat("b", 40) + "\n\n"
+	testCases := []struct {
+		name     string
+		report   string
+		maxBytes int
+		want     string
+	}{
+		{
+			name:     "fits",
+			report:   "# Short",
+			maxBytes: 100,
+			want:     "# Short",
+		},
+		{
+			name:     "cut at the last heading",
+			report:   "# Title\n\nIntro.\n\n" + sectionA + sectionB + "## C\n" + strings.Repeat("c", 200),
+			maxBytes: budgetBytes(len("# Title\n\nIntro.\n\n"+sectionA+sectionB) + 20),
+			want:     "# Title\n\nIntro.\n\n" + sectionA + "## B\n" + strings.Repeat("b", 40) + truncationNote,
+		},
+		{
+			name:     "cut at a paragraph",
+			report:   "First paragraph.\n\nSecond paragraph.\nstill second " + strings.Repeat("x", 200),
+			maxBytes: budgetBytes(50),
+			want:     "First paragraph." + truncationNote,
+		},
+		{
+			name:     "cut at a line",
+			report:   "line one\nline two\nline three " + strings.Repeat("x", 200),
+			maxBytes: budgetBytes(30),
+			want:     "line one\nline two" + truncationNote,
+		},
+		{
+			name:     "cut at a rune",
+			report:   strings.Repeat("x", 300),
+			maxBytes: budgetBytes(10),
+			want:     strings.Repeat("x", 10) + truncationNote,
+		},
+		{
+			name:     "multi-byte character at the cut",
+			report:   "ab" + strings.Repeat("é", 200), // Two bytes each: the budget of 6 splits the third
+			maxBytes: budgetBytes(6),
+			want:     "abéé" + truncationNote,
+		},
+		{
+			name:     "multi-byte text before a line cut",
+			report:   "日本語の報告\n" + strings.Repeat("語", 100),
+			maxBytes: budgetBytes(30),
+			want:     "日本語の報告" + truncationNote,
+		},
+		{
+			name:     "heading inside fenced code is not a cut point",
+			report:   "# Title\n\nIntro.\n\n```sh\necho one\n# a shell comment\necho two " + strings.Repeat("x", 200) + "\n```\n",
+			maxBytes: budgetBytes(60),
+			want:     "# Title\n\nIntro." + truncationNote,
+		},
+		{
+			name:     "heading after fenced code is a cut point",
+			report:   "# Title\n\n```\n# comment\n```\n\n## Next\n" + strings.Repeat("n", 200),
+			maxBytes: budgetBytes(50),
+			want:     "# Title\n\n```\n# comment\n```" + truncationNote,
+		},
+		{
+			name:     "open code block is closed",
+			report:   "Intro.\n```\nline one\nline two\nline three " + strings.Repeat("x", 200),
+			maxBytes: budgetBytes(40),
+			want:     "Intro.\n```\nline one\nline two" + fenceClose + truncationNote,
+		},
+		{
+			name:     "hash without space is not a heading",
+			report:   "Notes\n\nFixed\n#42 and more " + strings.Repeat("x", 200),
+			maxBytes: budgetBytes(30),
+			want:     "Notes" + truncationNote,
+		},
+		{
+			name:     "budget smaller than the note",
+			report:   strings.Repeat("x", 300),
+			maxBytes: 20,
+			want:     truncationNote[:20],
+		},
+		{
+			name:     "no budget",
+			report:   "report",
+			maxBytes: 0,
+			want:     "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := truncateReport(tc.report, tc.maxBytes)
+			if got != tc.want {
+				t.Errorf("Mismatch:\nExpected: %q\nActual:   %q", tc.want, got)
+			}
+			if len(got) > tc.maxBytes && len(tc.report) > tc.maxBytes {
+				t.Errorf("Expected at most %d bytes, got %d", tc.maxBytes, len(got))
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("Expected valid UTF-8, got %q", got)
+			}
+		})
+	}
+}
+
+func TestEnforceMaxReportBytes(t *testing.T) {
+	long := "# Title\n\n" + strings.Repeat("word ", 200)
+	testCases := []struct {
+		name       string
+		maxBytes   int
+		onOverflow string
+		wantErr    bool
+		wantNote   bool
+	}{
+		{"no limit", 0, "", false, false},
+		{"under the limit", len(long), "", false, false},
+		{"truncate by default", 300, "", false, true},
+		{"truncate", 300, OverflowTruncate, false, true},
+		{"error", 300, OverflowError, true, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				MaxReportBytes: tc.maxBytes,
+				OnOverflow:     tc.onOverflow,
+				Logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+			}
+			got, err := enforceMaxReportBytes(cfg, long)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error %t, got %v", tc.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if hasNote := strings.HasSuffix(got, truncationNote); hasNote != tc.wantNote {
+				t.Errorf("Expected truncation %t, got %q", tc.wantNote, got)
+			}
+			if !tc.wantNote && got != long {
+				t.Errorf("Expected the report unchanged, got %q", got)
+			}
+			if tc.maxBytes > 0 && len(got) > tc.maxBytes {
+				t.Errorf("Expected at most %d bytes, got %d", tc.maxBytes, len(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package gitexec
+
+import (
+	"fmt"
+	"strings"
+)
+
+// forbiddenExtraArgs lists the git flags ExtraArgs may not contain because they change
+// the shape of the output the parser depends on (format, separators, diff output), or
+// because they write outside of stdout.
+var forbiddenExtraArgs = []string{
+	"--pretty", "--format", "--oneline", "--output", "-z", "--graph", "--line-prefix",
+	"--name-only", "--name-status", "--raw", "--stat", "--numstat", "--shortstat",
+	"--dirstat", "--summary", "--compact-summary", "-p", "-u", "--patch",
+	"--patch-with-stat", "--patch-with-raw",
+}
+
+// ValidateExtraArgs rejects extra git arguments that would break output parsing.
+func ValidateExtraArgs(args []string) error {
+	for _, arg := range args {
+		for _, forbidden := range forbiddenExtraArgs {
+			if arg == forbidden || strings.HasPrefix(arg, forbidden+"=") {
+				return fmt.Errorf("extra git argument %q is not allowed: it changes the output format", arg)
+			}
+		}
+	}
+	return nil
+}
+
+// Pathspecs returns the git pathspecs selecting paths minus excludePaths, or nil for no filter.
+func Pathspecs(paths, excludePaths []string) []string {
+	if len(paths) == 0 && len(excludePaths) == 0 {
+		return nil
+	}
+	specs := append([]string(nil), paths...)
+	if len(specs) == 0 {
+		specs = append(specs, ".") // Exclusions need a positive pathspec to subtract from
+	}
+	for _, p := range excludePaths {
+		specs = append(specs, ":(exclude)"+p)
+	}
+	return specs
+}
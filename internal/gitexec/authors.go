@@ -0,0 +1,29 @@
+package gitexec
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// CompileAuthorPatterns compiles the ExcludeAuthorsMatching patterns once per call.
+func CompileAuthorPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ExcludeAuthorsMatching pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// AuthorExcluded reports whether any of patterns matches the author's email or name.
+func AuthorExcluded(patterns []*regexp.Regexp, name, email string) bool {
+	for _, re := range patterns {
+		if re.MatchString(email) || re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
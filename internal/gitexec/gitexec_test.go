@@ -0,0 +1,158 @@
+package gitexec
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setupRepo returns a git repository whose commits carry the given messages, oldest first.
+func setupRepo(t *testing.T, messages ...string) string {
+	t.Helper()
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\nOutput:\n%s", args, err, output)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	messageFile := filepath.Join(t.TempDir(), "message.txt") // Long messages exceed the argument limit
+	for _, message := range messages {
+		if err := os.WriteFile(messageFile, []byte(message), 0o600); err != nil {
+			t.Fatalf("Failed to write message: %v", err)
+		}
+		run("-c", "user.name=Alice", "-c", "user.email=alice@example.com", "commit", "-q", "--allow-empty", "-F", messageFile)
+	}
+	return repo
+}
+
+func TestStream(t *testing.T) {
+	long := strings.Repeat("x", maxScanTokenSize+1) // Forces the unbounded re-read
+	testCases := []struct {
+		name     string
+		messages []string
+	}{
+		{"short tokens", []string{"first", "second", "third"}},
+		{"token over the scan limit", []string{"first", long, "third"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := setupRepo(t, tc.messages...)
+			var got []string
+			sawOutput, _, err := Stream(repo, []string{"log", "--reverse", "--format=%x00%s"}, 0, func(token string) {
+				if token != "" {
+					got = append(got, strings.TrimSuffix(token, "\n"))
+				}
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !sawOutput {
+				t.Error("Expected sawOutput to be true")
+			}
+			if !reflect.DeepEqual(got, tc.messages) {
+				t.Errorf("Expected %d tokens in order, got %d", len(tc.messages), len(got))
+			}
+		})
+	}
+}
+
+func TestStreamGitFailure(t *testing.T) {
+	repo := setupRepo(t)
+	sawOutput, stderr, err := Stream(repo, []string{"log"}, '\n', func(string) {})
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Expected an *exec.ExitError for a repository without commits, got %v", err)
+	}
+	if sawOutput {
+		t.Error("Expected sawOutput to be false")
+	}
+	if !strings.Contains(stderr, "does not have any commits") {
+		t.Errorf("Expected git's stderr, got %q", stderr)
+	}
+}
+
+func TestValidateRepoPath(t *testing.T) {
+	repo := setupRepo(t)
+	file := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(file, []byte("x"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	testCases := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{"repository", repo, ""},
+		{"empty", "", "cannot be empty"},
+		{"missing", filepath.Join(repo, "missing"), "does not exist"},
+		{"file", file, "is not a directory"},
+		{"not a repository", t.TempDir(), "missing .git directory"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ValidateRepoPath(tc.path)
+			if tc.wantErr == "" {
+				if err != nil || got != repo {
+					t.Errorf("Expected (%q, nil), got (%q, %v)", repo, got, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestUnknownRef(t *testing.T) {
+	repo := setupRepo(t, "first")
+	testCases := []struct {
+		name   string
+		stderr string
+		want   string
+		wantOK bool
+	}{
+		{"unknown revision", "fatal: ambiguous argument 'nope': unknown revision or path not in the working tree.", "nope", true},
+		{"bad revision", "fatal: bad revision 'nope'", "nope", true},
+		{"range with unknown end", "fatal: ambiguous argument 'main..nope': unknown revision", "nope", true},
+		{"range with unknown start", "fatal: bad revision 'nope...main'", "nope", true},
+		{"other failure", "fatal: not a git repository", "", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := UnknownRef(repo, tc.stderr)
+			if got != tc.want || ok != tc.wantOK {
+				t.Errorf("Expected (%q, %t), got (%q, %t)", tc.want, tc.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestPathspecs(t *testing.T) {
+	testCases := []struct {
+		name         string
+		paths        []string
+		excludePaths []string
+		want         []string
+	}{
+		{"no filter", nil, nil, nil},
+		{"paths", []string{"web/", "*.go"}, nil, []string{"web/", "*.go"}},
+		{"exclusions only", nil, []string{"vendor/"}, []string{".", ":(exclude)vendor/"}},
+		{"both", []string{"web/"}, []string{"web/dist/"}, []string{"web/", ":(exclude)web/dist/"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Pathspecs(tc.paths, tc.excludePaths); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package gitexec
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// unknownRevisionPattern matches the fatal messages git prints for revisions that do
+// not resolve, capturing the argument as given (possibly a range such as "a..b").
+var unknownRevisionPattern = regexp.MustCompile(`(?:ambiguous argument '([^']*)': unknown revision|bad revision '([^']*)')`)
+
+// UnknownRef returns the revision git's stderr reports as unresolvable, and false when it
+// reports none. For a range, the endpoint that does not resolve is returned.
+func UnknownRef(absRepoPath, stderr string) (string, bool) {
+	m := unknownRevisionPattern.FindStringSubmatch(stderr)
+	if m == nil {
+		return "", false
+	}
+	ref := m[1] + m[2]
+	for _, sep := range []string{"...", ".."} {
+		if !strings.Contains(ref, sep) {
+			continue
+		}
+		for _, side := range strings.SplitN(ref, sep, 2) {
+			if side != "" && !revisionExists(absRepoPath, side) {
+				return side, true
+			}
+		}
+		break
+	}
+	return ref, true
+}
+
+// revisionExists reports whether rev resolves to a commit.
+func revisionExists(absRepoPath, rev string) bool {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", "--end-of-options", rev+"^{commit}")
+	cmd.Dir = absRepoPath
+	return cmd.Run() == nil
+}
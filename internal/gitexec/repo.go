@@ -0,0 +1,37 @@
+package gitexec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ValidateRepoPath checks that repoPath is an existing directory holding a .git entry and
+// returns its absolute path.
+func ValidateRepoPath(repoPath string) (string, error) {
+	if repoPath == "" {
+		return "", fmt.Errorf("repository path cannot be empty")
+	}
+	absRepoPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path for %q: %w", repoPath, err)
+	}
+	info, err := os.Stat(absRepoPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("repository path %q does not exist", absRepoPath)
+		}
+		return "", fmt.Errorf("failed to stat repository path %q: %w", absRepoPath, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("repository path %q is not a directory", absRepoPath)
+	}
+	gitDirPath := filepath.Join(absRepoPath, ".git")
+	if _, err := os.Stat(gitDirPath); err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("path %q is not a git repository (missing .git directory)", absRepoPath)
+		}
+		return "", fmt.Errorf("failed to stat .git directory in %q: %w", absRepoPath, err)
+	}
+	return absRepoPath, nil
+}
@@ -0,0 +1,98 @@
+package gitexec
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// maxScanTokenSize bounds a single record read by the scanner (e.g. one commit header or
+// numstat line). The scan buffer only grows up to this size when a record needs it;
+// larger records are handled by re-reading the output without a size limit.
+const maxScanTokenSize = 1 << 20
+
+// Stream runs git with args in dir and passes each delim-terminated token of its
+// stdout to handle while git is still running, so the full output is never held in memory.
+// It reports whether any token was read and returns git's stderr. A failing git process
+// yields an *exec.ExitError; other errors mean the output could not be read.
+//
+// Tokens are scanned with a bounded buffer. When a token exceeds maxScanTokenSize, git is
+// run again and its output is read with an unbounded reader, skipping the tokens that
+// were already handled.
+func Stream(dir string, args []string, delim byte, handle func(token string)) (sawOutput bool, stderr string, err error) {
+	handled := 0
+	stderr, err = runGitStream(dir, args, func(stdout io.Reader) error {
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxScanTokenSize)
+		scanner.Split(splitOn(delim))
+		for scanner.Scan() {
+			handle(scanner.Text())
+			handled++
+		}
+		return scanner.Err()
+	})
+	if !errors.Is(err, bufio.ErrTooLong) {
+		return handled > 0, stderr, err
+	}
+
+	seen := 0
+	stderr, err = runGitStream(dir, args, func(stdout io.Reader) error {
+		reader := bufio.NewReader(stdout)
+		for {
+			token, readErr := reader.ReadString(delim)
+			if token != "" {
+				if seen >= handled {
+					handle(strings.TrimSuffix(token, string(delim)))
+				}
+				seen++
+			}
+			if readErr == io.EOF {
+				return nil
+			}
+			if readErr != nil {
+				return readErr
+			}
+		}
+	})
+	return seen > 0, stderr, err
+}
+
+// runGitStream starts git with args in dir and hands its stdout to consume. If consume
+// fails, git is stopped so Wait does not block on a full pipe nobody reads anymore.
+func runGitStream(dir string, args []string, consume func(stdout io.Reader) error) (stderr string, err error) {
+	cmd := exec.Command("git", args...) // #nosec G204 -- args are built by the calling package
+	cmd.Dir = dir
+	var stderrBuf bytes.Buffer
+	cmd.Stderr = &stderrBuf
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", fmt.Errorf("failed to open git output: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("failed to start git: %w", err)
+	}
+	if consumeErr := consume(stdout); consumeErr != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return stderrBuf.String(), fmt.Errorf("error reading git output: %w", consumeErr)
+	}
+	err = cmd.Wait()
+	return stderrBuf.String(), err
+}
+
+// splitOn returns a bufio.SplitFunc that splits on delim, dropping the terminator.
+func splitOn(delim byte) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if i := bytes.IndexByte(data, delim); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
@@ -0,0 +1,177 @@
+package gitcontributors
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Stone-IT-Cloud/reporting/internal/gitexec"
+)
+
+// WeekBucket counts the commits made in one calendar week.
+type WeekBucket struct {
+	WeekStart time.Time // Monday 00:00 UTC of the week.
+	Commits   int
+}
+
+// AuthorActivityReport describes one author's commit cadence.
+type AuthorActivityReport struct {
+	Email           string
+	Commits         int
+	FirstCommitDate time.Time
+	LastCommitDate  time.Time
+	// Weeks holds one bucket per week from the first to the last commit, oldest first.
+	// Weeks without commits are included with a zero count so gaps stay visible.
+	Weeks []WeekBucket
+	// ActiveDays is the number of distinct UTC calendar days with at least one commit.
+	// With Options.BusinessDaysOnly, days are taken in Options.Location and weekend days
+	// are not counted.
+	ActiveDays int
+	// LongestGap is the longest time between two consecutive commits. Zero with fewer than two commits.
+	// With Options.BusinessDaysOnly, time falling on weekend days is not counted.
+	LongestGap time.Duration
+	// LinesChanged is the total churn: inserted plus deleted lines (binary files count as zero).
+	LinesChanged int
+}
+
+// AuthorActivity reports the commit cadence of the author with the given email
+// (matched case-insensitively). It honors StartDate, EndDate, IncludeMergeCommits,
+// NetOfReverts (applied to LinesChanged), BusinessDaysOnly, IdentityNormalizeRegex and
+// AliasMap (applied to email too), Paths and ExcludePaths from opts; other options are
+// ignored. If the author has no commits in range, the report is empty apart from Email.
+func AuthorActivity(repoPath, email string, opts *Options) (AuthorActivityReport, error) {
+	report := AuthorActivityReport{Email: email}
+	absRepoPath, err := gitexec.ValidateRepoPath(repoPath)
+	if err != nil {
+		return report, err
+	}
+	if strings.TrimSpace(email) == "" {
+		return report, fmt.Errorf("author email cannot be empty")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	logger := opts.logger()
+	calendar := opts.businessCalendar()
+	identities, err := opts.identityResolver()
+	if err != nil {
+		return report, err
+	}
+	wanted := identities.normalize(strings.TrimSpace(email))
+	var excludedChurn map[string]struct{}
+	if opts.NetOfReverts {
+		if excludedChurn, err = revertedPairs(absRepoPath, opts); err != nil {
+			return report, err
+		}
+	}
+
+	const commitMarker = "\x1e"
+	args := []string{"log", "--pretty=format:%x1e%H%x00%aE%x00%aI", "--numstat"}
+	if opts.StartDate != nil {
+		args = append(args, "--after="+opts.StartDate.Format(time.RFC3339))
+	}
+	if end := opts.endDate(); end != nil {
+		args = append(args, "--before="+end.Format(time.RFC3339))
+	}
+	if !opts.IncludeMergeCommits {
+		args = append(args, "--no-merges")
+	}
+	args = append(args, "--")
+	args = append(args, opts.pathspecs()...)
+
+	data := &aggregatedContributorData{
+		Email:        email,
+		FilesTouched: make(map[string]struct{}),
+		ActiveDays:   make(map[string]struct{}),
+	}
+	var commitDates []time.Time
+	matching := false // Whether the numstat lines being read belong to the author and count as churn
+	sawOutput, stderrStr, err := gitexec.Stream(absRepoPath, args, '\n', func(line string) {
+		if line == "" {
+			return
+		}
+		if !strings.HasPrefix(line, commitMarker) {
+			if matching {
+				addNumstatLine(data, line)
+			}
+			return
+		}
+		matching = false
+		parts := strings.SplitN(strings.TrimPrefix(line, commitMarker), "\x00", 3)
+		if len(parts) != 3 {
+			logger.Warn("skipping malformed git log output line", "line", line)
+			return
+		}
+		if !strings.EqualFold(identities.normalize(strings.TrimSpace(parts[1])), wanted) {
+			return
+		}
+		commitDate, err := time.Parse(time.RFC3339, strings.TrimSpace(parts[2]))
+		if err != nil {
+			logger.Warn("skipping commit with unparseable date", "date", parts[2], "error", err)
+			return
+		}
+		commitDate = commitDate.UTC()
+		commitDates = append(commitDates, commitDate)
+		switch {
+		case calendar == nil:
+			data.ActiveDays[commitDate.Format("2006-01-02")] = struct{}{}
+		case !calendar.isWeekend(commitDate):
+			data.ActiveDays[commitDate.In(calendar.loc).Format("2006-01-02")] = struct{}{}
+		}
+		_, reverted := excludedChurn[parts[0]]
+		matching = !reverted
+	})
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return report, fmt.Errorf("git log command failed (path: %q, args: %v): %w", absRepoPath, args, err)
+		}
+		if strings.Contains(stderrStr, "does not have any commits") ||
+			strings.Contains(stderrStr, "bad default revision 'HEAD'") ||
+			!sawOutput {
+			return report, nil
+		}
+		return report, fmt.Errorf("git log command failed (path: %q, args: %v): %w\nstderr: %s",
+			absRepoPath, args, err, stderrStr)
+	}
+	if len(commitDates) == 0 {
+		return report, nil
+	}
+
+	sort.Slice(commitDates, func(i, j int) bool { return commitDates[i].Before(commitDates[j]) })
+	report.Commits = len(commitDates)
+	report.FirstCommitDate = commitDates[0]
+	report.LastCommitDate = commitDates[len(commitDates)-1]
+	report.ActiveDays = len(data.ActiveDays)
+	report.LinesChanged = data.LinesChanged
+	for i := 1; i < len(commitDates); i++ {
+		gap := commitDates[i].Sub(commitDates[i-1])
+		if calendar != nil {
+			gap = calendar.businessDuration(commitDates[i-1], commitDates[i])
+		}
+		if gap > report.LongestGap {
+			report.LongestGap = gap
+		}
+	}
+
+	lastWeek := weekStart(report.LastCommitDate)
+	for week := weekStart(report.FirstCommitDate); !week.After(lastWeek); week = week.AddDate(0, 0, 7) {
+		report.Weeks = append(report.Weeks, WeekBucket{WeekStart: week})
+	}
+	for _, d := range commitDates {
+		idx := int(weekStart(d).Sub(report.Weeks[0].WeekStart).Hours() / (24 * 7))
+		report.Weeks[idx].Commits++
+	}
+	return report, nil
+}
+
+// weekStart returns Monday 00:00 UTC of the week containing t.
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := (int(day.Weekday()) + 6) % 7 // Days since Monday
+	return day.AddDate(0, 0, -offset)
+}
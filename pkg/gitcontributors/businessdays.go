@@ -0,0 +1,55 @@
+package gitcontributors
+
+import "time"
+
+// businessCalendar tells weekend days apart from business days in a timezone.
+type businessCalendar struct {
+	loc     *time.Location
+	weekend map[time.Weekday]bool
+}
+
+// businessCalendar returns the calendar for BusinessDaysOnly, or nil when it is off.
+func (o *Options) businessCalendar() *businessCalendar {
+	if !o.BusinessDaysOnly {
+		return nil
+	}
+	cal := &businessCalendar{loc: o.Location, weekend: make(map[time.Weekday]bool)}
+	if cal.loc == nil {
+		cal.loc = time.UTC
+	}
+	days := o.WeekendDays
+	if days == nil {
+		days = []time.Weekday{time.Saturday, time.Sunday}
+	}
+	for _, d := range days {
+		cal.weekend[d] = true
+	}
+	return cal
+}
+
+// isWeekend reports whether t falls on a weekend day in the calendar's timezone.
+func (c *businessCalendar) isWeekend(t time.Time) bool {
+	return c.weekend[t.In(c.loc).Weekday()]
+}
+
+// businessDuration returns the part of [from, to) that falls on business days.
+func (c *businessCalendar) businessDuration(from, to time.Time) time.Duration {
+	var total time.Duration
+	local := from.In(c.loc)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, c.loc)
+	for day.Before(to) {
+		next := day.AddDate(0, 0, 1) // Not day+24h, so DST days keep their real length
+		if !c.weekend[day.Weekday()] {
+			start, end := day, next
+			if from.After(start) {
+				start = from
+			}
+			if to.Before(end) {
+				end = to
+			}
+			total += end.Sub(start)
+		}
+		day = next
+	}
+	return total
+}
@@ -0,0 +1,173 @@
+package gitcontributors
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Stone-IT-Cloud/reporting/internal/gitexec"
+)
+
+// NoExtension is the ChurnByExtension key for files without an extension (e.g. Makefile).
+const NoExtension = "(none)"
+
+// ChurnStat aggregates the line changes made to one group of files.
+type ChurnStat struct {
+	Insertions int
+	Deletions  int
+	Files      int // Distinct paths changed.
+}
+
+// ChurnByExtension aggregates inserted and deleted lines by file extension (".go", ".md",
+// ...) over the commits in range, showing where the work went: code, docs or config.
+// Extensions are lowercased; files without one are grouped under NoExtension. Binary files
+// count towards Files but add no lines. A renamed file counts under its new name.
+// It honors StartDate, EndDate, IncludeMergeCommits, NetOfReverts, Paths, ExcludePaths
+// and ExtraArgs from opts; other options are ignored.
+func ChurnByExtension(repoPath string, opts *Options) (map[string]ChurnStat, error) {
+	absRepoPath, err := gitexec.ValidateRepoPath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	stats := make(map[string]ChurnStat)
+	files := make(map[string]map[string]struct{}) // Extension -> distinct paths
+	err = walkNumstat(absRepoPath, opts, func(added, deleted int, path string) {
+		path = renamedPath(path)
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext == "" {
+			ext = NoExtension
+		}
+		stat := stats[ext]
+		stat.Insertions += added
+		stat.Deletions += deleted
+		if files[ext] == nil {
+			files[ext] = make(map[string]struct{})
+		}
+		if _, seen := files[ext][path]; !seen {
+			files[ext][path] = struct{}{}
+			stat.Files++
+		}
+		stats[ext] = stat
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// NetLinesChanged sums the lines inserted (added) and deleted over the commits in range,
+// and their difference (net = added - deleted), the "team added a net N lines" headline.
+// Binary files add no lines. It honors the same options as ChurnByExtension.
+func NetLinesChanged(repoPath string, opts *Options) (added, deleted, net int, err error) {
+	absRepoPath, err := gitexec.ValidateRepoPath(repoPath)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	err = walkNumstat(absRepoPath, opts, func(a, d int, _ string) {
+		added += a
+		deleted += d
+	})
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return added, deleted, added - deleted, nil
+}
+
+// walkNumstat calls handle for every numstat line of the commits selected by opts (see
+// ChurnByExtension), skipping reverted pairs when NetOfReverts is set. An empty
+// repository or range yields no calls.
+func walkNumstat(absRepoPath string, opts *Options, handle func(added, deleted int, path string)) error {
+	if err := gitexec.ValidateExtraArgs(opts.ExtraArgs); err != nil {
+		return err
+	}
+	logger := opts.logger()
+	var excludedChurn map[string]struct{}
+	if opts.NetOfReverts {
+		var err error
+		if excludedChurn, err = revertedPairs(absRepoPath, opts); err != nil {
+			return err
+		}
+	}
+
+	const commitMarker = "\x1e"
+	args := []string{"log", "--pretty=format:%x1e%H", "--numstat"}
+	if opts.StartDate != nil {
+		args = append(args, "--after="+opts.StartDate.Format(time.RFC3339))
+	}
+	if end := opts.endDate(); end != nil {
+		args = append(args, "--before="+end.Format(time.RFC3339))
+	}
+	if !opts.IncludeMergeCommits {
+		args = append(args, "--no-merges")
+	}
+	args = append(args, opts.ExtraArgs...)
+	args = append(args, "--")
+	args = append(args, opts.pathspecs()...)
+
+	counting := false // Whether the numstat lines being read are aggregated
+	sawOutput, stderrStr, err := gitexec.Stream(absRepoPath, args, '\n', func(line string) {
+		if line == "" {
+			return
+		}
+		if strings.HasPrefix(line, commitMarker) {
+			_, reverted := excludedChurn[strings.TrimPrefix(line, commitMarker)]
+			counting = !reverted
+			return
+		}
+		if !counting {
+			return
+		}
+		added, deleted, path, ok := parseNumstatLine(line)
+		if !ok {
+			logger.Warn("skipping malformed numstat line", "line", line)
+			return
+		}
+		handle(added, deleted, path)
+	})
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return fmt.Errorf("git log command failed (path: %q, args: %v): %w", absRepoPath, args, err)
+		}
+		if refErr := unknownRefError(absRepoPath, stderrStr); refErr != nil {
+			return refErr
+		}
+		if strings.Contains(stderrStr, "does not have any commits") ||
+			strings.Contains(stderrStr, "bad default revision 'HEAD'") ||
+			!sawOutput {
+			return nil
+		}
+		return fmt.Errorf("git log command failed (path: %q, args: %v): %w\nstderr: %s",
+			absRepoPath, args, err, stderrStr)
+	}
+	return nil
+}
+
+// renamedPath returns the new name from a numstat rename path, either "old => new" or
+// "dir/{old => new}/file"; other paths are returned unchanged.
+func renamedPath(path string) string {
+	if open := strings.Index(path, "{"); open >= 0 {
+		if closing := strings.Index(path[open:], "}"); closing >= 0 {
+			inner := path[open+1 : open+closing]
+			if _, newName, ok := strings.Cut(inner, " => "); ok {
+				joined := path[:open] + newName + path[open+closing+1:]
+				// An empty side, as in "{old => }/file", leaves a stray slash.
+				return strings.TrimPrefix(strings.ReplaceAll(joined, "//", "/"), "/")
+			}
+		}
+	}
+	if _, newName, ok := strings.Cut(path, " => "); ok {
+		return newName
+	}
+	return path
+}
@@ -0,0 +1,233 @@
+package gitcontributors
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Stone-IT-Cloud/reporting/internal/gitexec"
+)
+
+// codeOwnersLocations are the paths, relative to the repository root, where GitHub
+// looks for a CODEOWNERS file, in order of precedence.
+var codeOwnersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// CodeOwnersRule is one non-comment line of a CODEOWNERS file.
+type CodeOwnersRule struct {
+	Pattern string   // gitignore-style pattern, e.g. "/web/" or "*.go"
+	Owners  []string // @user, @org/team or email entries; empty when the rule removes ownership
+	Line    int      // 1-based line number in the file
+}
+
+// StaleOwnership reports an owned area without commits by any of its owners.
+type StaleOwnership struct {
+	Pattern string
+	Owners  []string
+	// Commits is the number of commits in range touching the area, by anyone.
+	Commits int
+	// LastCommitDate is the latest commit in range touching the area, by anyone.
+	// Zero when the area had no activity at all.
+	LastCommitDate time.Time
+}
+
+// ParseCodeOwners reads CODEOWNERS rules from r. Blank lines and comments are skipped;
+// a "\#" prefix escapes a pattern starting with "#".
+func ParseCodeOwners(r io.Reader) ([]CodeOwnersRule, error) {
+	var rules []CodeOwnersRule
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i]) // Trailing comment
+		}
+		fields := strings.Fields(line)
+		rules = append(rules, CodeOwnersRule{
+			Pattern: strings.TrimPrefix(fields[0], `\`),
+			Owners:  fields[1:],
+			Line:    lineNo,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading CODEOWNERS: %w", err)
+	}
+	return rules, nil
+}
+
+// FindStaleOwnership parses the repository's CODEOWNERS file and returns the owned areas
+// whose owners made no commit touching them within the StartDate/EndDate range of opts.
+// As in CODEOWNERS, the last matching rule wins: a path is attributed only to the last
+// rule matching it. Merge commits are excluded unless IncludeMergeCommits is set.
+//
+// Owners are matched to commit authors heuristically: an email owner must equal the
+// author email, and an @user owner matches an author whose name equals the handle or
+// whose email local part is the handle (including GitHub noreply addresses). Team owners
+// (@org/team) cannot be resolved without the provider API and never match, so areas
+// owned only by teams are always reported. Returns nil if the repository has no CODEOWNERS.
+func FindStaleOwnership(repoPath string, opts *Options) ([]StaleOwnership, error) {
+	absRepoPath, err := gitexec.ValidateRepoPath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	var rules []CodeOwnersRule
+	for _, location := range codeOwnersLocations {
+		// #nosec G304 -- fixed locations inside the validated repository.
+		data, err := os.ReadFile(filepath.Join(absRepoPath, location))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", location, err)
+		}
+		if rules, err = ParseCodeOwners(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+		break
+	}
+
+	var stale []StaleOwnership
+	for i, rule := range rules {
+		if len(rule.Owners) == 0 {
+			continue // Explicitly unowned
+		}
+		specs := codeOwnersPathspecs(rule.Pattern, false)
+		for _, later := range rules[i+1:] {
+			specs = append(specs, codeOwnersPathspecs(later.Pattern, true)...)
+		}
+		area, err := areaActivity(absRepoPath, opts, specs, rule.Owners)
+		if err != nil {
+			return nil, err
+		}
+		if !area.ownerActive {
+			stale = append(stale, StaleOwnership{
+				Pattern:        rule.Pattern,
+				Owners:         rule.Owners,
+				Commits:        area.commits,
+				LastCommitDate: area.lastCommit,
+			})
+		}
+	}
+	return stale, nil
+}
+
+// codeOwnersPathspecs converts a gitignore-style CODEOWNERS pattern into git glob
+// pathspecs, as exclusions when exclude is set.
+func codeOwnersPathspecs(pattern string, exclude bool) []string {
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.Trim(pattern, "/")
+	if !anchored {
+		p = "**/" + p
+	}
+	var globs []string
+	if dirOnly {
+		globs = []string{p + "/**"}
+	} else {
+		globs = []string{p, p + "/**"} // Matches a file or everything under a directory
+	}
+
+	magic := ":(glob)"
+	if exclude {
+		magic = ":(glob,exclude)"
+	}
+	specs := make([]string, len(globs))
+	for i, g := range globs {
+		specs[i] = magic + g
+	}
+	return specs
+}
+
+// areaSummary is the commit activity found for one CODEOWNERS area.
+type areaSummary struct {
+	commits     int
+	lastCommit  time.Time
+	ownerActive bool
+}
+
+// areaActivity scans the commits touching pathspecs and checks them against owners.
+func areaActivity(absRepoPath string, opts *Options, pathspecs, owners []string) (areaSummary, error) {
+	var summary areaSummary
+	args := []string{"log", "--encoding=UTF-8", "--pretty=format:%aN%x00%aE%x00%aI"}
+	if opts.StartDate != nil {
+		args = append(args, "--after="+opts.StartDate.Format(time.RFC3339))
+	}
+	if end := opts.endDate(); end != nil {
+		args = append(args, "--before="+end.Format(time.RFC3339))
+	}
+	if !opts.IncludeMergeCommits {
+		args = append(args, "--no-merges")
+	}
+	args = append(args, "--")
+	args = append(args, pathspecs...)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = absRepoPath
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		stderrStr := stderr.String()
+		if strings.Contains(stderrStr, "does not have any commits") || strings.Contains(stderrStr, "bad default revision 'HEAD'") {
+			return summary, nil
+		}
+		return summary, fmt.Errorf("git log command failed (args: %v): %w\nstderr: %s", args, err, stderrStr)
+	}
+
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), "\x00", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		commitDate, err := time.Parse(time.RFC3339, parts[2])
+		if err != nil {
+			continue
+		}
+		summary.commits++
+		if commitDate.After(summary.lastCommit) {
+			summary.lastCommit = commitDate.UTC()
+		}
+		for _, owner := range owners {
+			if ownerMatches(owner, parts[0], parts[1]) {
+				summary.ownerActive = true
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return summary, fmt.Errorf("error reading git log output: %w", err)
+	}
+	return summary, nil
+}
+
+// ownerMatches reports whether a CODEOWNERS owner entry designates the commit author.
+func ownerMatches(owner, authorName, authorEmail string) bool {
+	if !strings.HasPrefix(owner, "@") {
+		return strings.EqualFold(owner, authorEmail)
+	}
+	handle := strings.TrimPrefix(owner, "@")
+	if strings.Contains(handle, "/") {
+		return false // Team; membership is not known locally
+	}
+	if strings.EqualFold(handle, authorName) {
+		return true
+	}
+	local, domain, _ := strings.Cut(authorEmail, "@")
+	if strings.EqualFold(domain, "users.noreply.github.com") {
+		if _, login, ok := strings.Cut(local, "+"); ok {
+			local = login // <id>+<login>@users.noreply.github.com
+		}
+	}
+	return strings.EqualFold(handle, local)
+}
@@ -0,0 +1,78 @@
+package gitcontributors
+
+import (
+	"fmt"
+	"time"
+)
+
+// CohortAnalysis groups the contributors returned by GetContributors for opts by the
+// month of their first-ever commit, keyed "YYYY-MM" (UTC), or by quarter ("YYYY-Qn") with
+// Options.CohortQuarterly. This shows onboarding waves over the project's life.
+//
+// The cohort is decided by a separate pass over the full history, so a contributor whose
+// first commit predates StartDate still lands in the cohort of that first commit; the
+// returned Contributor values keep their stats for the requested range. The history pass
+// honors IncludeMergeCommits, GroupMissingEmails, IdentityNormalizeRegex, AliasMap,
+// ExtraArgs, Paths and ExcludePaths. Within a cohort, contributors keep the order of GetContributors.
+func CohortAnalysis(repoPath string, opts *Options) (map[string][]Contributor, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	identities, err := opts.identityResolver()
+	if err != nil {
+		return nil, err
+	}
+	// Both passes run unredacted so that contributors are matched by their real emails;
+	// RedactEmails is applied to the result.
+	ranged := *opts
+	ranged.RedactEmails = false
+	contributors, err := GetContributors(repoPath, &ranged)
+	if err != nil {
+		return nil, err
+	}
+	history, err := GetContributors(repoPath, &Options{
+		IncludeMergeCommits:    opts.IncludeMergeCommits,
+		GroupMissingEmails:     opts.GroupMissingEmails,
+		IdentityNormalizeRegex: opts.IdentityNormalizeRegex,
+		AliasMap:               opts.AliasMap,
+		ExtraArgs:              opts.ExtraArgs,
+		Paths:                  opts.Paths,
+		ExcludePaths:           opts.ExcludePaths,
+		Logger:                 opts.Logger,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read full history for cohorts: %w", err)
+	}
+	firstCommits := make(map[string]time.Time, len(history))
+	for _, c := range history {
+		firstCommits[identities.key(c.Name, c.Email)] = c.FirstCommitDate
+	}
+
+	cohorts := make(map[string][]Contributor)
+	for _, c := range contributors {
+		first, ok := firstCommits[identities.key(c.Name, c.Email)]
+		if !ok {
+			first = c.FirstCommitDate
+		}
+		if opts.RedactEmails {
+			c.Email = redactEmail(c.Email)
+		}
+		key := cohortKey(first, opts.CohortQuarterly)
+		cohorts[key] = append(cohorts[key], c)
+	}
+	if opts.RedactEmails {
+		for _, members := range cohorts {
+			sortContributors(members) // GetContributors sorts by the redacted emails
+		}
+	}
+	return cohorts, nil
+}
+
+// cohortKey formats the month ("2024-01") or quarter ("2024-Q1") containing t in UTC.
+func cohortKey(t time.Time, quarterly bool) string {
+	t = t.UTC()
+	if quarterly {
+		return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+	}
+	return t.Format("2006-01")
+}
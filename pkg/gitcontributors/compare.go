@@ -0,0 +1,179 @@
+package gitcontributors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DeltaStatus classifies how a contributor changed between two reports.
+type DeltaStatus string
+
+const (
+	DeltaNew       DeltaStatus = "new"       // Only in the later report.
+	DeltaDeparted  DeltaStatus = "departed"  // Only in the earlier report.
+	DeltaChanged   DeltaStatus = "changed"   // In both, with a different commit count.
+	DeltaUnchanged DeltaStatus = "unchanged" // In both, with the same commit count.
+)
+
+// ContributorDelta describes one contributor's change between two reports.
+type ContributorDelta struct {
+	Name          string
+	Email         string
+	BeforeCommits int
+	AfterCommits  int
+	Change        int // AfterCommits - BeforeCommits
+	Status        DeltaStatus
+}
+
+// CompareContributors matches contributors of two reports (e.g. last week and this
+// week) by name and email, ignoring case like GetContributors, and returns one delta
+// per contributor found in either report, sorted by name and email.
+func CompareContributors(before, after []Contributor) []ContributorDelta {
+	key := func(c Contributor) string { return strings.ToLower(fmt.Sprintf("%s<%s>", c.Name, c.Email)) }
+	deltas := make(map[string]*ContributorDelta)
+	for _, c := range before {
+		deltas[key(c)] = &ContributorDelta{Name: c.Name, Email: c.Email, BeforeCommits: c.Commits, Status: DeltaDeparted}
+	}
+	for _, c := range after {
+		d, ok := deltas[key(c)]
+		if !ok {
+			d = &ContributorDelta{Name: c.Name, Email: c.Email, Status: DeltaNew}
+			deltas[key(c)] = d
+		} else if d.BeforeCommits == c.Commits {
+			d.Status = DeltaUnchanged
+		} else {
+			d.Status = DeltaChanged
+		}
+		d.AfterCommits = c.Commits
+	}
+
+	result := make([]ContributorDelta, 0, len(deltas))
+	for _, d := range deltas {
+		d.Change = d.AfterCommits - d.BeforeCommits
+		result = append(result, *d)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		nameI, nameJ := strings.ToLower(result[i].Name), strings.ToLower(result[j].Name)
+		if nameI != nameJ {
+			return nameI < nameJ
+		}
+		return strings.ToLower(result[i].Email) < strings.ToLower(result[j].Email)
+	})
+	return result
+}
+
+// Formats accepted by RenderContributorDiff.
+const (
+	DiffFormatMarkdown = "markdown"
+	DiffFormatText     = "text"
+)
+
+// maxMovers caps the "biggest movers" section of RenderContributorDiff.
+const maxMovers = 5
+
+// RenderContributorDiff renders deltas from CompareContributors as a "team activity
+// changes" section listing newcomers, departed contributors and the biggest movers by
+// absolute change in commits. format is DiffFormatMarkdown (with emoji indicators) or
+// DiffFormatText (with +/- indicators); any other value is an error.
+func RenderContributorDiff(delta []ContributorDelta, format string) (string, error) {
+	var md bool
+	switch format {
+	case DiffFormatMarkdown:
+		md = true
+	case DiffFormatText:
+	default:
+		return "", fmt.Errorf("unsupported diff format %q: must be %q or %q", format, DiffFormatMarkdown, DiffFormatText)
+	}
+
+	var newcomers, departed, movers []ContributorDelta
+	for _, d := range delta {
+		switch d.Status {
+		case DeltaNew:
+			newcomers = append(newcomers, d)
+		case DeltaDeparted:
+			departed = append(departed, d)
+		case DeltaChanged:
+			movers = append(movers, d)
+		}
+	}
+	sort.SliceStable(movers, func(i, j int) bool { return abs(movers[i].Change) > abs(movers[j].Change) })
+	if len(movers) > maxMovers {
+		movers = movers[:maxMovers]
+	}
+
+	var b strings.Builder
+	heading := func(title string) {
+		if md {
+			fmt.Fprintf(&b, "### %s\n\n", title)
+		} else {
+			fmt.Fprintf(&b, "%s\n%s\n", title, strings.Repeat("-", len(title)))
+		}
+	}
+	identity := func(d ContributorDelta) string {
+		if md {
+			return fmt.Sprintf("**%s** <%s>", d.Name, d.Email)
+		}
+		return fmt.Sprintf("%s <%s>", d.Name, d.Email)
+	}
+
+	if md {
+		b.WriteString("## Team activity changes\n\n")
+	} else {
+		b.WriteString("TEAM ACTIVITY CHANGES\n\n")
+	}
+
+	heading("Newcomers")
+	for _, d := range newcomers {
+		indicator := "+"
+		if md {
+			indicator = "🆕"
+		}
+		fmt.Fprintf(&b, "- %s %s: %d commits\n", indicator, identity(d), d.AfterCommits)
+	}
+	if len(newcomers) == 0 {
+		b.WriteString("- None\n")
+	}
+	b.WriteString("\n")
+
+	heading("Departed contributors")
+	for _, d := range departed {
+		indicator := "-"
+		if md {
+			indicator = "👋"
+		}
+		fmt.Fprintf(&b, "- %s %s: %d commits previously\n", indicator, identity(d), d.BeforeCommits)
+	}
+	if len(departed) == 0 {
+		b.WriteString("- None\n")
+	}
+	b.WriteString("\n")
+
+	heading("Biggest movers")
+	if len(movers) > 0 && md {
+		b.WriteString("| | Contributor | Before | After | Change |\n|---|---|---:|---:|---:|\n")
+	}
+	for _, d := range movers {
+		if md {
+			indicator := "📈"
+			if d.Change < 0 {
+				indicator = "📉"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %d | %d | %+d |\n", indicator, identity(d), d.BeforeCommits, d.AfterCommits, d.Change)
+			continue
+		}
+		fmt.Fprintf(&b, "- %s: %d -> %d (%+d)\n", identity(d), d.BeforeCommits, d.AfterCommits, d.Change)
+	}
+	if len(movers) == 0 {
+		b.WriteString("- None\n")
+	}
+	return b.String(), nil
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -1,15 +1,16 @@
 package gitcontributors // <-- The package name is now 'gitcontributors'
 
 import (
-	"bufio"
-	"bytes"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/Stone-IT-Cloud/reporting/internal/gitexec"
 )
 
 // Contributor holds aggregated information about a single repository contributor.
@@ -19,13 +20,145 @@ type Contributor struct {
 	Commits         int       // Number of commits within the specified date range (if any)
 	FirstCommitDate time.Time // First commit date within the specified date range (if any)
 	LastCommitDate  time.Time // Last commit date within the specified date range (if any)
+	// ContributionScore is a heuristic impact number computed from Options.ScoreWeights.
+	// It is zero unless scoring is enabled.
+	ContributionScore float64
+	// LinesAdded and LinesDeleted are the lines inserted and deleted by the contributor's
+	// commits, from git log --numstat. Binary files count as zero.
+	LinesAdded   int
+	LinesDeleted int
+	// LinesChanged is LinesAdded plus LinesDeleted.
+	LinesChanged int
 }
 
 // Options allows configuring the behavior of GetContributors.
 type Options struct {
 	IncludeMergeCommits bool
-	StartDate           *time.Time // Optional: Only count commits on or after this date/time (inclusive).
-	EndDate             *time.Time // Optional: Only count commits on or before this date/time (inclusive).
+	StartDate           *time.Time       // Optional: Only count commits on or after this date/time (inclusive).
+	EndDate             *time.Time       // Optional: Only count commits on or before this date/time (inclusive).
+	InclusiveEndDate    bool             // Optional: Extend a date-only EndDate (midnight in Location) to the end of that day.
+	Location            *time.Location   // Optional: Timezone for InclusiveEndDate (defaults to EndDate's own location) and for BusinessDaysOnly (defaults to UTC).
+	Logger              *slog.Logger     // Optional: Receives structured warnings. Defaults to a stderr text handler.
+	Now                 func() time.Time // Optional: Clock used for date-relative metrics. Defaults to time.Now.
+	ScoreWeights        *ScoreWeights    // Optional: Enables ContributionScore. Use DefaultScoreWeights() for sensible defaults.
+	// GroupMissingEmails controls commits whose author email is empty. By default they are
+	// skipped; when true they are aggregated under a single UnknownContributorName entry.
+	// Either way, the number of such commits is reported as a warning via Logger.
+	GroupMissingEmails bool
+	// ActiveSince filters the returned contributors to those whose LastCommitDate is on or
+	// after this time. Unlike StartDate it does not limit which commits are counted, so
+	// contributors keep their full stats for the range.
+	ActiveSince *time.Time
+	// ActiveWithin is a relative form of ActiveSince: only contributors with a commit within
+	// this duration before Now are returned. If both are set, the later cutoff applies.
+	ActiveWithin time.Duration
+	// ExtraArgs are passed verbatim to git log, just before the final "--", as an escape
+	// hatch for flags not covered by these options. They can conflict with the flags set
+	// internally (e.g. --no-merges); use with care. Arguments that change the output
+	// format, add diff output or redirect it (--pretty, --format, -z, --name-status,
+	// --stat, -p, --graph, --output and the like) are rejected.
+	ExtraArgs []string
+	// NetOfReverts excludes reverted commits and the reverts themselves from the line
+	// counts and the file aggregation used by ContributionScore, since together they net to zero. Only
+	// reverts created by git revert (detected via "This reverts commit <hash>") whose
+	// target is also within the selected range are handled. Commit counts are unaffected.
+	NetOfReverts bool
+	// CohortQuarterly makes CohortAnalysis key cohorts by quarter ("2024-Q1") instead of
+	// by month ("2024-01").
+	CohortQuarterly bool
+	// ExcludeAuthorsMatching drops commits whose author email or name matches any of these
+	// regular expressions (Go syntax, unanchored), e.g. `@mycompany\.com$` to keep only
+	// outside contributions. Prefix a pattern with (?i) to ignore case. Invalid patterns
+	// are an error.
+	ExcludeAuthorsMatching []string
+	// RedactEmails masks returned contributor emails, keeping the first character and the
+	// domain ("a***@example.com"), for reports published or sent to third parties.
+	// Contributors are still told apart by their full email.
+	RedactEmails bool
+	// IdentityNormalizeRegex rewrites every author email with these rules, in order, before
+	// contributors are told apart, so aliases such as "ann+ci@example.com" and
+	// "ann@example.com" count as one contributor. Returned emails are the rewritten ones.
+	// While IdentityNormalizeRegex or AliasMap is set, contributors are told apart by email
+	// alone (ignoring case) and named after the name most of their commits use, the most
+	// recent one on a tie. Honored by GetContributors, CountContributors, CohortAnalysis
+	// and AuthorActivity.
+	IdentityNormalizeRegex []*IdentityRule
+	// AliasMap maps alias emails to the canonical email of the same person, e.g.
+	// {"alice@corp.com": "alice@example.com"}, so both count as one contributor with the
+	// canonical email, whatever names the commits were recorded under. Keys match ignoring
+	// case and are looked up after IdentityNormalizeRegex; keys differing only by case must
+	// map to the same email. The repository's .mailmap is always honored and applies first.
+	// Honored wherever IdentityNormalizeRegex is.
+	AliasMap map[string]string
+	// Paths limits the analysis to commits touching these paths (git pathspecs, e.g.
+	// "web/" or "*.go"), e.g. one project of a monorepo. Line statistics only count the
+	// lines changed within them.
+	Paths []string
+	// ExcludePaths leaves these paths out: commits touching only excluded paths are not
+	// counted, nor are the lines changed in them. Combines with Paths.
+	ExcludePaths []string
+	// BusinessDaysOnly makes AuthorActivity leave weekend days out of ActiveDays and
+	// LongestGap, so weekends without commits do not count as gaps. Days are taken in
+	// Location.
+	BusinessDaysOnly bool
+	// WeekendDays are the days BusinessDaysOnly skips. Defaults to Saturday and Sunday.
+	WeekendDays []time.Weekday
+}
+
+// activityCutoff returns the LastCommitDate threshold from ActiveSince/ActiveWithin,
+// or nil when no activity filter is configured.
+func (o *Options) activityCutoff() *time.Time {
+	cutoff := o.ActiveSince
+	if o.ActiveWithin > 0 {
+		within := o.now().Add(-o.ActiveWithin)
+		if cutoff == nil || within.After(*cutoff) {
+			cutoff = &within
+		}
+	}
+	return cutoff
+}
+
+// UnknownContributorName labels the contributor that collects commits without an author
+// email when Options.GroupMissingEmails is set.
+const UnknownContributorName = "(unknown)"
+
+// logger returns the configured logger or a stderr text logger when none is set.
+func (o *Options) logger() *slog.Logger {
+	if o.Logger != nil {
+		return o.Logger
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, nil))
+}
+
+// pathspecs returns the git pathspecs for Paths and ExcludePaths, or nil for no filter.
+func (o *Options) pathspecs() []string {
+	return gitexec.Pathspecs(o.Paths, o.ExcludePaths)
+}
+
+// endDate returns EndDate, extended to the last instant of its day when
+// InclusiveEndDate is set and EndDate is a bare date (midnight in Location).
+func (o *Options) endDate() *time.Time {
+	if o.EndDate == nil || !o.InclusiveEndDate {
+		return o.EndDate
+	}
+	loc := o.Location
+	if loc == nil {
+		loc = o.EndDate.Location()
+	}
+	local := o.EndDate.In(loc)
+	if local.Hour() != 0 || local.Minute() != 0 || local.Second() != 0 || local.Nanosecond() != 0 {
+		return o.EndDate // Has a time component; already precise
+	}
+	endOfDay := local.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	return &endOfDay
+}
+
+// now returns the current time according to the configured clock.
+func (o *Options) now() time.Time {
+	if o.Now != nil {
+		return o.Now()
+	}
+	return time.Now()
 }
 
 // Internal struct to hold aggregated data during processing.
@@ -35,11 +168,17 @@ type aggregatedContributorData struct {
 	Commits         int
 	FirstCommitDate time.Time
 	LastCommitDate  time.Time
+	LinesAdded      int
+	LinesDeleted    int
+	LinesChanged    int
+	FilesTouched    map[string]struct{}
+	ActiveDays      map[string]struct{}
+	Names           map[string]*nameUse // Author names seen, when identity rules merge contributors
 }
 
 // GetContributors retrieves a list of contributors for a given Git repository path.
 // It parses the Git log to aggregate contributor data such as name, email, number of commits,
-// and the first and last commit dates.
+// the first and last commit dates, and the lines added and deleted.
 //
 // Parameters:
 //   - repoPath: The file system path to the Git repository.
@@ -75,7 +214,7 @@ type aggregatedContributorData struct {
 //	}
 func GetContributors(repoPath string, opts *Options) ([]Contributor, error) {
 	// --- Input Validation & Path Setup ---
-	absRepoPath, err := validateRepoPath(repoPath)
+	absRepoPath, err := gitexec.ValidateRepoPath(repoPath)
 	if err != nil {
 		return nil, err
 	}
@@ -84,72 +223,127 @@ func GetContributors(repoPath string, opts *Options) ([]Contributor, error) {
 	if opts == nil {
 		opts = &Options{}
 	}
+	buckets, err := aggregateContributors(absRepoPath, opts, func(time.Time) string { return "" })
+	if err != nil {
+		return nil, err
+	}
+
+	// --- Convert Map to Slice ---
+	activeCutoff := opts.activityCutoff()
+	contributors := make([]Contributor, 0, len(buckets[""]))
+	for _, c := range buckets[""] {
+		if activeCutoff != nil && c.LastCommitDate.Before(*activeCutoff) {
+			continue
+		}
+		contributors = append(contributors, c)
+	}
+	return contributors, nil
+}
+
+// aggregateContributors runs the GetContributors log pass and aggregates each commit into
+// the bucket that bucketOf returns for its author date, so that several periods can be
+// computed at once. Each bucket's contributors are sorted like GetContributors; buckets
+// without commits are absent. ActiveSince and ActiveWithin are left to the caller.
+func aggregateContributors(absRepoPath string, opts *Options, bucketOf func(time.Time) string) (map[string][]Contributor, error) {
+	logger := opts.logger()
+	if err := gitexec.ValidateExtraArgs(opts.ExtraArgs); err != nil {
+		return nil, err
+	}
+	excludedAuthors, err := gitexec.CompileAuthorPatterns(opts.ExcludeAuthorsMatching)
+	if err != nil {
+		return nil, err
+	}
+	identities, err := opts.identityResolver()
+	if err != nil {
+		return nil, err
+	}
+
+	var excludedChurn map[string]struct{}
+	if opts.NetOfReverts {
+		if excludedChurn, err = revertedPairs(absRepoPath, opts); err != nil {
+			return nil, err
+		}
+	}
 
 	// --- Execute Git Log Command ---
-	const logFormat = "--pretty=format:%aN|%aE|%aI"
-	const separator = "|"
-	args := []string{"log", logFormat}
+	// Each commit header starts with a record separator so it can be told apart from
+	// the --numstat lines that follow it.
+	// Fields are NUL-separated: git does not allow NUL in names or emails, unlike "|".
+	const commitMarker = "\x1e"
+	const logFormat = "--pretty=format:%x1e%H%x00%aN%x00%aE%x00%aI"
+	const separator = "\x00"
+	args := []string{"log", "--encoding=UTF-8", logFormat, "--numstat"} // Author names may use a legacy commit encoding
 
 	if opts.StartDate != nil {
 		args = append(args, "--after="+opts.StartDate.Format(time.RFC3339))
 	}
-	if opts.EndDate != nil {
-		args = append(args, "--before="+opts.EndDate.Format(time.RFC3339))
+	if end := opts.endDate(); end != nil {
+		args = append(args, "--before="+end.Format(time.RFC3339))
 	}
 	if !opts.IncludeMergeCommits {
 		args = append(args, "--no-merges")
 	}
+	args = append(args, opts.ExtraArgs...)
 	args = append(args, "--")
-
-	cmd := exec.Command("git", args...)
-	cmd.Dir = absRepoPath
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		stderrStr := stderr.String()
-		if strings.Contains(stderrStr, "does not have any commits") ||
-			strings.Contains(stderrStr, "bad default revision 'HEAD'") ||
-			stdout.Len() == 0 {
-			return []Contributor{}, nil
-		}
-		return nil, fmt.Errorf("git log command failed (path: %q, args: %v): %w\nstderr: %s",
-			absRepoPath, args, err, stderrStr)
-	}
+	args = append(args, opts.pathspecs()...)
 
 	// --- Aggregate Data ---
-	contributorsMap := make(map[string]*aggregatedContributorData)
-	scanner := bufio.NewScanner(&stdout)
+	// Bucket -> identity key (see identityResolver.key) -> data
+	contributorsMap := make(map[string]map[string]*aggregatedContributorData)
+	var current *aggregatedContributorData // Contributor owning the numstat lines being read
+	countChurn := false                    // Whether the numstat lines being read are aggregated
+	missingEmailCommits := 0
 
-	for scanner.Scan() {
-		line := scanner.Text()
+	sawOutput, stderrStr, err := gitexec.Stream(absRepoPath, args, '\n', func(line string) {
 		if line == "" {
-			continue
+			return
+		}
+		if !strings.HasPrefix(line, commitMarker) {
+			if current != nil && countChurn {
+				addNumstatLine(current, line)
+			}
+			return
 		}
+		line = strings.TrimPrefix(line, commitMarker)
+		current = nil
 
-		parts := strings.SplitN(line, separator, 3)
-		if len(parts) != 3 {
-			fmt.Fprintf(os.Stderr, "Warning: malformed git log output line: %q\n", line)
-			continue
+		parts := strings.SplitN(line, separator, 4)
+		if len(parts) != 4 {
+			logger.Warn("skipping malformed git log output line", "line", line)
+			return
 		}
 
-		name := strings.TrimSpace(parts[0])
-		email := strings.TrimSpace(parts[1])
-		dateStr := strings.TrimSpace(parts[2])
+		hash := parts[0]
+		name := strings.TrimSpace(parts[1])
+		email := identities.normalize(strings.TrimSpace(parts[2]))
+		dateStr := strings.TrimSpace(parts[3])
 
 		if name == "" && email == "" {
-			continue
+			return
+		}
+		if gitexec.AuthorExcluded(excludedAuthors, name, email) {
+			return
+		}
+		if email == "" {
+			missingEmailCommits++
+			if !opts.GroupMissingEmails {
+				return
+			}
+			name = UnknownContributorName
 		}
 
 		commitDate, err := time.Parse(time.RFC3339, dateStr)
 		if err != nil {
-			continue
-		} // Skip commits with unparseable dates?
+			logger.Warn("skipping commit with unparseable date", "date", dateStr, "error", err)
+			return
+		}
 
-		mapKey := strings.ToLower(fmt.Sprintf("%s<%s>", name, email))
-		aggData, exists := contributorsMap[mapKey]
+		mapKey := identities.key(name, email)
+		bucket := bucketOf(commitDate)
+		if contributorsMap[bucket] == nil {
+			contributorsMap[bucket] = make(map[string]*aggregatedContributorData)
+		}
+		aggData, exists := contributorsMap[bucket][mapKey]
 		if !exists {
 			aggData = &aggregatedContributorData{
 				Name:            name,
@@ -157,8 +351,10 @@ func GetContributors(repoPath string, opts *Options) ([]Contributor, error) {
 				Commits:         1,
 				FirstCommitDate: commitDate,
 				LastCommitDate:  commitDate,
+				FilesTouched:    make(map[string]struct{}),
+				ActiveDays:      make(map[string]struct{}),
 			}
-			contributorsMap[mapKey] = aggData
+			contributorsMap[bucket][mapKey] = aggData
 		} else {
 			aggData.Commits++
 			if aggData.FirstCommitDate.IsZero() || commitDate.Before(aggData.FirstCommitDate) {
@@ -174,72 +370,85 @@ func GetContributors(repoPath string, opts *Options) ([]Contributor, error) {
 				aggData.Email = email
 			}
 		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading git log output: %w", err)
-	}
-
-	// --- Convert Map to Slice ---
-	contributors := make([]Contributor, 0, len(contributorsMap))
-	for _, data := range contributorsMap {
-		if data.FirstCommitDate.IsZero() || data.LastCommitDate.IsZero() {
-			continue
+		aggData.ActiveDays[commitDate.UTC().Format("2006-01-02")] = struct{}{}
+		if identities.merges() {
+			if aggData.Names == nil {
+				aggData.Names = make(map[string]*nameUse)
+			}
+			use := aggData.Names[name]
+			if use == nil {
+				use = &nameUse{}
+				aggData.Names[name] = use
+			}
+			use.commits++
+			if commitDate.After(use.last) {
+				use.last = commitDate
+			}
 		}
-		contributors = append(contributors, Contributor{
-			Name:            data.Name,
-			Email:           data.Email,
-			Commits:         data.Commits,
-			FirstCommitDate: data.FirstCommitDate.UTC(),
-			LastCommitDate:  data.LastCommitDate.UTC(),
-		})
-	}
-
-	// --- Sorting ---
-	sortContributors(contributors)
-	return contributors, nil
-}
-
-// validateRepoPath validates the provided repository path to ensure it is a valid
-// Git repository. It performs the following checks:
-//   - The repository path is not empty.
-//   - The repository path can be resolved to an absolute path.
-//   - The resolved path exists and is a directory.
-//   - The directory contains a ".git" subdirectory, indicating it is a Git repository.
-//
-// Parameters:
-//   - repoPath: The path to the repository to validate.
-//
-// Returns:
-//   - A string representing the absolute path of the repository if validation succeeds.
-//   - An error if the validation fails, describing the reason for failure.
-func validateRepoPath(repoPath string) (string, error) {
-	// ... (implementation identical to previous version) ...
-	if repoPath == "" {
-		return "", fmt.Errorf("repository path cannot be empty")
-	}
-	absRepoPath, err := filepath.Abs(repoPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to get absolute path for %q: %w", repoPath, err)
-	}
-	info, err := os.Stat(absRepoPath)
+		current = aggData
+		_, reverted := excludedChurn[hash]
+		countChurn = !reverted
+	})
 	if err != nil {
-		if os.IsNotExist(err) {
-			return "", fmt.Errorf("repository path %q does not exist", absRepoPath)
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("git log command failed (path: %q, args: %v): %w", absRepoPath, args, err)
+		}
+		if refErr := unknownRefError(absRepoPath, stderrStr); refErr != nil {
+			return nil, refErr
+		}
+		if strings.Contains(stderrStr, "does not have any commits") ||
+			strings.Contains(stderrStr, "bad default revision 'HEAD'") ||
+			!sawOutput {
+			return map[string][]Contributor{}, nil
 		}
-		return "", fmt.Errorf("failed to stat repository path %q: %w", absRepoPath, err)
+		return nil, fmt.Errorf("git log command failed (path: %q, args: %v): %w\nstderr: %s",
+			absRepoPath, args, err, stderrStr)
 	}
-	if !info.IsDir() {
-		return "", fmt.Errorf("repository path %q is not a directory", absRepoPath)
+	if missingEmailCommits > 0 {
+		action := "skipped"
+		if opts.GroupMissingEmails {
+			action = "grouped as " + UnknownContributorName
+		}
+		logger.Warn("found commits without author email", "count", missingEmailCommits, "action", action)
 	}
-	gitDirPath := filepath.Join(absRepoPath, ".git")
-	if _, err := os.Stat(gitDirPath); err != nil {
-		if os.IsNotExist(err) {
-			return "", fmt.Errorf("path %q is not a git repository (missing .git directory)", absRepoPath)
+
+	// --- Convert Maps to Slices ---
+	buckets := make(map[string][]Contributor, len(contributorsMap))
+	for bucket, bucketData := range contributorsMap {
+		contributors := make([]Contributor, 0, len(bucketData))
+		for _, data := range bucketData {
+			if data.FirstCommitDate.IsZero() || data.LastCommitDate.IsZero() {
+				continue
+			}
+			if len(data.Names) > 0 {
+				data.Name = dominantName(data.Names)
+			}
+			email := data.Email
+			if opts.RedactEmails {
+				email = redactEmail(email)
+			}
+			contributor := Contributor{
+				Name:            data.Name,
+				Email:           email,
+				Commits:         data.Commits,
+				FirstCommitDate: data.FirstCommitDate.UTC(),
+				LastCommitDate:  data.LastCommitDate.UTC(),
+				LinesAdded:      data.LinesAdded,
+				LinesDeleted:    data.LinesDeleted,
+				LinesChanged:    data.LinesChanged,
+			}
+			if opts.ScoreWeights != nil {
+				contributor.ContributionScore = opts.ScoreWeights.score(data)
+			}
+			contributors = append(contributors, contributor)
 		}
-		return "", fmt.Errorf("failed to stat .git directory in %q: %w", absRepoPath, err)
+
+		// --- Sorting ---
+		sortContributors(contributors)
+		buckets[bucket] = contributors
 	}
-	return absRepoPath, nil
+	return buckets, nil
 }
 
 // sortContributors sorts a slice of Contributor structs in a stable manner.
@@ -1,6 +1,8 @@
 package gitcontributors_test // <-- The test package for 'gitcontributors'
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -45,8 +47,10 @@ func gitCommit(t *testing.T, repoPath, message, authorName, authorEmail string,
 		t.Fatalf("Failed to write dummy file for commit: %v", err)
 	}
 	runGitCommand(t, repoPath, "add", dummyFile)
-	cmd := exec.Command("git", "commit", "-m", message)
+	// Pass the message on stdin: command-line arguments are too small for very long messages
+	cmd := exec.Command("git", "commit", "-F", "-")
 	cmd.Dir = repoPath
+	cmd.Stdin = strings.NewReader(message)
 	isoDate := commitDate.Format(time.RFC3339)
 	cmd.Env = append(os.Environ(),
 		"GIT_AUTHOR_NAME="+authorName,
@@ -151,7 +155,7 @@ func TestGetContributors(t *testing.T) {
 			gitCommit(t, repoPath, "Middle", author2Name, author2Email, testTime(2023, 5, 15, 14))
 			gitCommit(t, repoPath, "End", author1Name, author1Email, testTime(2023, 5, 20, 16))
 			gitCommit(t, repoPath, "Way After", author2Name, author2Email, testTime(2023, 5, 25, 18))
-		}, opts: &gitcontributors.Options{StartDate: PtrTime(testTime(2023, 5, 10, 0)), EndDate: PtrTime(time.Date(2023, 5, 20, 23, 59, 59, 0, time.UTC))}, expectedContributors: []gitcontributors.Contributor{{Name: author1Name, Email: author1Email, Commits: 2, FirstCommitDate: testTime(2023, 5, 10, 12), LastCommitDate: testTime(2023, 5, 20, 16)}, {Name: author2Name, Email: author2Email, Commits: 1, FirstCommitDate: testTime(2023, 5, 15, 14), LastCommitDate: testTime(2023, 5, 15, 14)}}, expectedError: false},
+		}, opts: &gitcontributors.Options{StartDate: PtrTime(testTime(2023, 5, 10, 0)), EndDate: PtrTime(time.Date(2023, 5, 20, 23, 59, 59, 0, time.UTC))}, expectedContributors: []gitcontributors.Contributor{{Name: author1Name, Email: author1Email, Commits: 2, FirstCommitDate: testTime(2023, 5, 10, 12), LastCommitDate: testTime(2023, 5, 20, 16), LinesAdded: 6, LinesChanged: 6}, {Name: author2Name, Email: author2Email, Commits: 1, FirstCommitDate: testTime(2023, 5, 15, 14), LastCommitDate: testTime(2023, 5, 15, 14), LinesAdded: 3, LinesChanged: 3}}, expectedError: false},
 	}
 
 	// --- Run Test Cases ---
@@ -264,3 +268,1058 @@ func TestGetContributors(t *testing.T) {
 		})
 	}
 }
+
+func TestGetContributorsContributionScore(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	// Each gitCommit writes one new file with three lines.
+	gitCommit(t, repoPath, "A C1", author1Name, author1Email, testTime(2023, 6, 1, 10))
+	gitCommit(t, repoPath, "A C2", author1Name, author1Email, testTime(2023, 6, 1, 12))
+	gitCommit(t, repoPath, "A C3", author1Name, author1Email, testTime(2023, 6, 2, 9))
+	gitCommit(t, repoPath, "B C1", author2Name, author2Email, testTime(2023, 6, 3, 9))
+
+	weights := &gitcontributors.ScoreWeights{Commits: 1, LinesChanged: 1, FilesTouched: 10, ActiveDays: 100}
+	opts := &gitcontributors.Options{StartDate: PtrTime(testTime(2023, 6, 1, 0)), EndDate: PtrTime(testTime(2023, 6, 30, 0)), ScoreWeights: weights}
+	contributors, err := gitcontributors.GetContributors(repoPath, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(contributors) != 2 {
+		t.Fatalf("Expected 2 contributors, got %d: %+v", len(contributors), contributors)
+	}
+
+	expected := map[string]float64{
+		author1Email: 3*1 + 9*1 + 3*10 + 2*100, // 3 commits, 9 lines, 3 files, 2 days
+		author2Email: 1*1 + 3*1 + 1*10 + 1*100, // 1 commit, 3 lines, 1 file, 1 day
+	}
+	expectedLines := map[string]int{author1Email: 9, author2Email: 3}
+	for _, c := range contributors {
+		if c.ContributionScore != expected[c.Email] {
+			t.Errorf("Score mismatch for %s: expected %v, got %v", c.Email, expected[c.Email], c.ContributionScore)
+		}
+		if c.LinesChanged != expectedLines[c.Email] {
+			t.Errorf("LinesChanged mismatch for %s: expected %d, got %d", c.Email, expectedLines[c.Email], c.LinesChanged)
+		}
+	}
+
+	// Without weights no score is computed.
+	contributors, err = gitcontributors.GetContributors(repoPath, &gitcontributors.Options{StartDate: opts.StartDate, EndDate: opts.EndDate})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, c := range contributors {
+		if c.ContributionScore != 0 {
+			t.Errorf("Expected zero score without weights for %s, got %v", c.Email, c.ContributionScore)
+		}
+	}
+}
+
+func TestGetContributorsMissingEmail(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "A C1", author1Name, author1Email, testTime(2023, 7, 1, 10))
+	gitCommit(t, repoPath, "Ghost C1", "Ghost One", "", testTime(2023, 7, 2, 10))
+	gitCommit(t, repoPath, "Ghost C2", "Ghost Two", "", testTime(2023, 7, 3, 10))
+	window := &gitcontributors.Options{StartDate: PtrTime(testTime(2023, 7, 1, 0)), EndDate: PtrTime(testTime(2023, 7, 31, 0))}
+
+	contributors, err := gitcontributors.GetContributors(repoPath, window)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(contributors) != 1 || contributors[0].Email != author1Email {
+		t.Errorf("Expected commits without email to be skipped by default, got %+v", contributors)
+	}
+
+	grouped := *window
+	grouped.GroupMissingEmails = true
+	contributors, err = gitcontributors.GetContributors(repoPath, &grouped)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(contributors) != 2 {
+		t.Fatalf("Expected 2 contributors when grouping, got %d: %+v", len(contributors), contributors)
+	}
+	var unknown *gitcontributors.Contributor
+	for i := range contributors {
+		if contributors[i].Name == gitcontributors.UnknownContributorName {
+			unknown = &contributors[i]
+		}
+	}
+	if unknown == nil || unknown.Commits != 2 || unknown.Email != "" {
+		t.Errorf("Expected a single %s contributor with 2 commits, got %+v", gitcontributors.UnknownContributorName, unknown)
+	}
+}
+
+func TestGetContributorsActivityFilter(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "A C1", author1Name, author1Email, testTime(2023, 1, 10, 10))
+	gitCommit(t, repoPath, "B C1", author2Name, author2Email, testTime(2023, 1, 5, 10))
+	gitCommit(t, repoPath, "A C2", author1Name, author1Email, testTime(2023, 3, 10, 10))
+	window := gitcontributors.Options{EndDate: PtrTime(testTime(2023, 12, 31, 0))}
+
+	activeSince := window
+	activeSince.ActiveSince = PtrTime(testTime(2023, 3, 1, 0))
+	contributors, err := gitcontributors.GetContributors(repoPath, &activeSince)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(contributors) != 1 || contributors[0].Email != author1Email || contributors[0].Commits != 2 {
+		t.Errorf("Expected only Alice with her full 2 commits, got %+v", contributors)
+	}
+
+	activeWithin := window
+	activeWithin.ActiveWithin = 60 * 24 * time.Hour
+	activeWithin.Now = func() time.Time { return testTime(2023, 4, 1, 0) }
+	contributors, err = gitcontributors.GetContributors(repoPath, &activeWithin)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(contributors) != 1 || contributors[0].Email != author1Email {
+		t.Errorf("Expected only Alice to be active within 60 days of the injected clock, got %+v", contributors)
+	}
+}
+
+func TestAuthorActivity(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	// 2023-06-05 is a Monday; the week of 2023-06-12 has no commits from Alice.
+	gitCommit(t, repoPath, "A C1", author1Name, author1Email, testTime(2023, 6, 5, 10))
+	gitCommit(t, repoPath, "B C1", author2Name, author2Email, testTime(2023, 6, 6, 10))
+	gitCommit(t, repoPath, "A C2", author1Name, author1Email, testTime(2023, 6, 7, 10))
+	gitCommit(t, repoPath, "A C3", author1Name, author1Email, testTime(2023, 6, 19, 10))
+
+	opts := &gitcontributors.Options{EndDate: PtrTime(testTime(2023, 6, 30, 0))}
+	report, err := gitcontributors.AuthorActivity(repoPath, strings.ToUpper(author1Email), opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := gitcontributors.AuthorActivityReport{
+		Email:           strings.ToUpper(author1Email),
+		Commits:         3,
+		FirstCommitDate: testTime(2023, 6, 5, 10),
+		LastCommitDate:  testTime(2023, 6, 19, 10),
+		Weeks: []gitcontributors.WeekBucket{
+			{WeekStart: testTime(2023, 6, 5, 0), Commits: 2},
+			{WeekStart: testTime(2023, 6, 12, 0), Commits: 0},
+			{WeekStart: testTime(2023, 6, 19, 0), Commits: 1},
+		},
+		ActiveDays:   3,
+		LongestGap:   12 * 24 * time.Hour,
+		LinesChanged: 9, // Each gitCommit adds a three-line file
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("Report mismatch:\nExpected: %+v\nActual:   %+v", expected, report)
+	}
+
+	report, err = gitcontributors.AuthorActivity(repoPath, "nobody@example.com", opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if report.Commits != 0 || report.Weeks != nil {
+		t.Errorf("Expected empty report for unknown author, got %+v", report)
+	}
+
+	if _, err := gitcontributors.AuthorActivity(repoPath, "", opts); err == nil {
+		t.Error("Expected an error for an empty email, but got nil")
+	}
+}
+
+func TestAuthorActivityBusinessDaysOnly(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	// 2023-06-09 is a Friday and 2023-06-12 a Monday.
+	gitCommit(t, repoPath, "A C1", author1Name, author1Email, testTime(2023, 6, 9, 10))
+	gitCommit(t, repoPath, "A C2", author1Name, author1Email, testTime(2023, 6, 12, 10))
+
+	testCases := []struct {
+		name       string
+		opts       *gitcontributors.Options
+		activeDays int
+		longestGap time.Duration
+	}{
+		{"calendar time", &gitcontributors.Options{}, 2, 72 * time.Hour},
+		{"weekends skipped", &gitcontributors.Options{BusinessDaysOnly: true}, 2, 24 * time.Hour},
+		// At UTC-12 the commits land on Thursday 22:00 and Sunday 22:00.
+		{"team timezone", &gitcontributors.Options{BusinessDaysOnly: true, Location: time.FixedZone("UTC-12", -12*3600)}, 1, 26 * time.Hour},
+		{"custom weekend", &gitcontributors.Options{BusinessDaysOnly: true, WeekendDays: []time.Weekday{time.Friday, time.Saturday}}, 1, 34 * time.Hour},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report, err := gitcontributors.AuthorActivity(repoPath, author1Email, tc.opts)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if report.ActiveDays != tc.activeDays || report.LongestGap != tc.longestGap {
+				t.Errorf("Expected %d active days and a %v gap, got %d and %v", tc.activeDays, tc.longestGap, report.ActiveDays, report.LongestGap)
+			}
+			if report.Commits != 2 {
+				t.Errorf("Expected 2 commits, got %d", report.Commits)
+			}
+		})
+	}
+}
+
+func TestGetContributorsExtraArgs(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "A C1", author1Name, author1Email, testTime(2023, 8, 1, 10))
+	gitCommit(t, repoPath, "B C1", author2Name, author2Email, testTime(2023, 8, 2, 10))
+
+	contributors, err := gitcontributors.GetContributors(repoPath, &gitcontributors.Options{ExtraArgs: []string{"--author=" + author1Email}})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(contributors) != 1 || contributors[0].Email != author1Email {
+		t.Errorf("Expected only %s, got %+v", author1Email, contributors)
+	}
+
+	for _, arg := range []string{"--format=%H", "-z", "--name-status", "--raw", "--stat", "--numstat", "--shortstat", "-p", "--patch", "--graph"} {
+		if _, err := gitcontributors.GetContributors(repoPath, &gitcontributors.Options{ExtraArgs: []string{arg}}); err == nil {
+			t.Errorf("Expected an error for extra argument %q, but got nil", arg)
+		}
+		if _, err := gitcontributors.CountContributors(repoPath, &gitcontributors.Options{ExtraArgs: []string{arg}}); err == nil {
+			t.Errorf("Expected CountContributors to reject extra argument %q, but got nil", arg)
+		}
+	}
+}
+
+func TestGetContributorsNetOfReverts(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "A C1", author1Name, author1Email, testTime(2023, 9, 1, 10))
+	gitCommit(t, repoPath, "A C2", author1Name, author1Email, testTime(2023, 9, 2, 10))
+	revertDate := testTime(2023, 9, 3, 10).Format(time.RFC3339)
+	cmd := exec.Command("git", "revert", "--no-edit", "HEAD")
+	cmd.Dir = repoPath
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+author2Name, "GIT_AUTHOR_EMAIL="+author2Email, "GIT_AUTHOR_DATE="+revertDate,
+		"GIT_COMMITTER_NAME="+author2Name, "GIT_COMMITTER_EMAIL="+author2Email, "GIT_COMMITTER_DATE="+revertDate,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git revert failed: %v\nOutput: %s", err, output)
+	}
+
+	weights := &gitcontributors.ScoreWeights{LinesChanged: 1}
+	testCases := []struct {
+		name         string
+		netOfReverts bool
+		expected     map[string]float64
+	}{
+		{name: "Gross", netOfReverts: false, expected: map[string]float64{author1Email: 6, author2Email: 3}},
+		{name: "Net of reverts", netOfReverts: true, expected: map[string]float64{author1Email: 3, author2Email: 0}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := &gitcontributors.Options{StartDate: PtrTime(testTime(2023, 9, 1, 0)), EndDate: PtrTime(testTime(2023, 9, 30, 0)), ScoreWeights: weights, NetOfReverts: tc.netOfReverts}
+			contributors, err := gitcontributors.GetContributors(repoPath, opts)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if len(contributors) != 2 {
+				t.Fatalf("Expected 2 contributors, got %d: %+v", len(contributors), contributors)
+			}
+			for _, c := range contributors {
+				if c.ContributionScore != tc.expected[c.Email] {
+					t.Errorf("Score mismatch for %s: expected %v, got %v", c.Email, tc.expected[c.Email], c.ContributionScore)
+				}
+			}
+
+			report, err := gitcontributors.AuthorActivity(repoPath, author1Email, opts)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if report.LinesChanged != int(tc.expected[author1Email]) {
+				t.Errorf("AuthorActivity churn mismatch: expected %v, got %d", tc.expected[author1Email], report.LinesChanged)
+			}
+		})
+	}
+}
+
+func TestGetContributorsNetOfRevertChains(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "Base", author1Name, author1Email, testTime(2023, 9, 1, 10))
+	gitCommit(t, repoPath, "X", author1Name, author1Email, testTime(2023, 9, 2, 10))
+	revert := func(day int) {
+		t.Helper()
+		date := testTime(2023, 9, day, 10).Format(time.RFC3339)
+		cmd := exec.Command("git", "revert", "--no-edit", "HEAD")
+		cmd.Dir = repoPath
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME="+author2Name, "GIT_AUTHOR_EMAIL="+author2Email, "GIT_AUTHOR_DATE="+date,
+			"GIT_COMMITTER_NAME="+author2Name, "GIT_COMMITTER_EMAIL="+author2Email, "GIT_COMMITTER_DATE="+date,
+		)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git revert failed: %v\nOutput: %s", err, output)
+		}
+	}
+	opts := &gitcontributors.Options{StartDate: PtrTime(testTime(2023, 9, 1, 0)), EndDate: PtrTime(testTime(2023, 9, 30, 0)), NetOfReverts: true}
+	linesAdded := func() map[string]int {
+		t.Helper()
+		contributors, err := gitcontributors.GetContributors(repoPath, opts)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		added := make(map[string]int)
+		for _, c := range contributors {
+			added[c.Email] = c.LinesAdded
+		}
+		return added
+	}
+
+	revert(3) // Revert X
+	revert(4) // Revert the revert: X's lines are back in the tree
+	if added := linesAdded(); added[author1Email] != 6 || added[author2Email] != 0 {
+		t.Errorf("Two reverts: expected 6 lines for %s and 0 for %s, got %v", author1Email, author2Email, added)
+	}
+
+	revert(5) // X is gone again
+	if added := linesAdded(); added[author1Email] != 3 || added[author2Email] != 0 {
+		t.Errorf("Three reverts: expected 3 lines for %s and 0 for %s, got %v", author1Email, author2Email, added)
+	}
+}
+
+func TestCountContributors(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "A C1", author1Name, author1Email, testTime(2023, 10, 1, 10))
+	gitCommit(t, repoPath, "A C2", author1Name, strings.ToUpper(author1Email), testTime(2023, 10, 2, 10))
+	gitCommit(t, repoPath, "A alt", author3Name, author3Email, testTime(2023, 10, 3, 10))
+	gitCommit(t, repoPath, "B C1", author2Name, author2Email, testTime(2023, 10, 4, 10))
+	gitCommit(t, repoPath, "C C1", author4Name, author4Email, testTime(2023, 11, 1, 10))
+	// A name stored in a legacy encoding, read back with a non-UTF-8 output encoding configured
+	runGitCommand(t, repoPath, "config", "i18n.commitEncoding", "ISO-8859-1")
+	gitCommit(t, repoPath, "Z C1", "Zo\xeb Zulu", "zoe@example.com", testTime(2023, 11, 2, 10))
+	runGitCommand(t, repoPath, "config", "--unset", "i18n.commitEncoding")
+	runGitCommand(t, repoPath, "config", "i18n.logOutputEncoding", "ISO-8859-1")
+	gitCommitUnparseableDate(t, repoPath, "Mallory", "mallory@example.com", testTime(2023, 11, 3, 10))
+
+	testCases := []struct {
+		name string
+		opts *gitcontributors.Options
+	}{
+		{name: "All", opts: &gitcontributors.Options{EndDate: PtrTime(testTime(2023, 12, 1, 0))}},
+		{name: "October", opts: &gitcontributors.Options{StartDate: PtrTime(testTime(2023, 10, 1, 0)), EndDate: PtrTime(testTime(2023, 10, 31, 0))}},
+		{name: "Nothing in range", opts: &gitcontributors.Options{StartDate: PtrTime(testTime(2024, 1, 1, 0)), EndDate: PtrTime(testTime(2024, 2, 1, 0))}},
+		{name: "Excluding authors", opts: &gitcontributors.Options{EndDate: PtrTime(testTime(2023, 12, 1, 0)), ExcludeAuthorsMatching: []string{"(?i)^alice"}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			contributors, err := gitcontributors.GetContributors(repoPath, tc.opts)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			count, err := gitcontributors.CountContributors(repoPath, tc.opts)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if count != len(contributors) {
+				t.Errorf("Expected count %d to match GetContributors, got %d", len(contributors), count)
+			}
+			for _, c := range contributors {
+				if c.Email == "mallory@example.com" {
+					t.Errorf("Expected the commit with an unparseable date to be skipped, got %+v", c)
+				}
+				if c.Email == "zoe@example.com" && c.Name != "Zoë Zulu" {
+					t.Errorf("Expected the legacy-encoded name as UTF-8, got %q", c.Name)
+				}
+			}
+		})
+	}
+}
+
+// gitCommitUnparseableDate writes a commit whose author timezone (+9999) git stores and
+// prints but that does not parse as RFC 3339, and moves HEAD to it.
+func gitCommitUnparseableDate(t *testing.T, repoPath, authorName, authorEmail string, commitDate time.Time) {
+	t.Helper()
+	output := func(stdin string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoPath
+		cmd.Stdin = strings.NewReader(stdin)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git command failed (args: %v): %v", args, err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	object := fmt.Sprintf("tree %s\nparent %s\nauthor %s <%s> %d +9999\ncommitter %s <%s> %d +0000\n\nUnparseable date\n",
+		output("", "rev-parse", "HEAD^{tree}"), output("", "rev-parse", "HEAD"),
+		authorName, authorEmail, commitDate.Unix(), authorName, authorEmail, commitDate.Unix())
+	hash := output(object, "hash-object", "-t", "commit", "-w", "--literally", "--stdin")
+	runGitCommand(t, repoPath, "update-ref", "HEAD", hash)
+}
+
+func TestWriteContributorsPrometheus(t *testing.T) {
+	contributors := []gitcontributors.Contributor{
+		{Name: `Alice "A" \ Alpha`, Email: author1Email, Commits: 3, FirstCommitDate: testTime(2023, 9, 1, 0), LastCommitDate: testTime(2023, 9, 2, 0), ContributionScore: 12.5},
+	}
+	var buf bytes.Buffer
+	if err := gitcontributors.WriteContributorsPrometheus(&buf, contributors, "org/repo\nx"); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	labels := `{repo="org/repo\nx",author="Alice \"A\" \\ Alpha",email="alice@example.com"}`
+	expectedLines := []string{
+		"# TYPE git_contributor_commits gauge",
+		"git_contributor_commits" + labels + " 3",
+		"git_contributor_first_commit_timestamp_seconds" + labels + " 1693526400",
+		"git_contributor_last_commit_timestamp_seconds" + labels + " 1693612800",
+		"git_contributor_contribution_score" + labels + " 12.5",
+	}
+	output := buf.String()
+	for _, line := range expectedLines {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Expected output to contain line %q, got:\n%s", line, output)
+		}
+	}
+}
+
+func TestCompareContributorsAndRenderDiff(t *testing.T) {
+	before := []gitcontributors.Contributor{
+		{Name: author1Name, Email: author1Email, Commits: 5},
+		{Name: author2Name, Email: author2Email, Commits: 2},
+		{Name: author3Name, Email: author3Email, Commits: 1},
+	}
+	after := []gitcontributors.Contributor{
+		{Name: author1Name, Email: strings.ToUpper(author1Email), Commits: 1},
+		{Name: author2Name, Email: author2Email, Commits: 2},
+		{Name: author4Name, Email: author4Email, Commits: 3},
+	}
+	delta := gitcontributors.CompareContributors(before, after)
+	expected := []gitcontributors.ContributorDelta{
+		{Name: author3Name, Email: author3Email, BeforeCommits: 1, AfterCommits: 0, Change: -1, Status: gitcontributors.DeltaDeparted},
+		{Name: author1Name, Email: author1Email, BeforeCommits: 5, AfterCommits: 1, Change: -4, Status: gitcontributors.DeltaChanged},
+		{Name: author2Name, Email: author2Email, BeforeCommits: 2, AfterCommits: 2, Change: 0, Status: gitcontributors.DeltaUnchanged},
+		{Name: author4Name, Email: author4Email, BeforeCommits: 0, AfterCommits: 3, Change: 3, Status: gitcontributors.DeltaNew},
+	}
+	if !reflect.DeepEqual(delta, expected) {
+		t.Fatalf("Delta mismatch:\nExpected: %+v\nActual:   %+v", expected, delta)
+	}
+
+	markdown, err := gitcontributors.RenderContributorDiff(delta, gitcontributors.DiffFormatMarkdown)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, want := range []string{
+		"- 🆕 **Charlie Charlie** <bob@example.com>: 3 commits",
+		"- 👋 **Alice Alpha** <alice.alt@example.com>: 1 commits previously",
+		"| 📉 | **Alice Alpha** <alice@example.com> | 5 | 1 | -4 |",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Expected markdown to contain %q, got:\n%s", want, markdown)
+		}
+	}
+	if strings.Contains(markdown, "Bob Bravo") {
+		t.Errorf("Expected unchanged contributors to be omitted, got:\n%s", markdown)
+	}
+
+	text, err := gitcontributors.RenderContributorDiff(delta, gitcontributors.DiffFormatText)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !strings.Contains(text, "- Alice Alpha <alice@example.com>: 5 -> 1 (-4)") {
+		t.Errorf("Unexpected text rendering:\n%s", text)
+	}
+
+	if _, err := gitcontributors.RenderContributorDiff(delta, "html"); err == nil {
+		t.Error("Expected an error for an unsupported format, but got nil")
+	}
+}
+
+func TestFindStaleOwnership(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	commitFile := func(file, authorName, authorEmail string, date time.Time) {
+		t.Helper()
+		path := filepath.Join(repoPath, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", file, err)
+		}
+		if err := os.WriteFile(path, []byte(date.String()), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+		runGitCommand(t, repoPath, "add", file)
+		cmd := exec.Command("git", "commit", "-m", "Update "+file)
+		cmd.Dir = repoPath
+		isoDate := date.Format(time.RFC3339)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME="+authorName, "GIT_AUTHOR_EMAIL="+authorEmail, "GIT_AUTHOR_DATE="+isoDate,
+			"GIT_COMMITTER_NAME="+authorName, "GIT_COMMITTER_EMAIL="+authorEmail, "GIT_COMMITTER_DATE="+isoDate,
+		)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git commit failed: %v\nOutput: %s", err, output)
+		}
+	}
+
+	commitFile(".github/CODEOWNERS", author1Name, author1Email, testTime(2023, 1, 1, 10))
+	if err := os.WriteFile(filepath.Join(repoPath, ".github", "CODEOWNERS"), []byte(strings.Join([]string{
+		"# Ownership",
+		"*.md       @alice",
+		"/web/      alice@example.com @bob",
+		"/web/gen/  @org/frontend",
+		"/api/      @carol # Carol owns the API",
+	}, "\n")), 0o644); err != nil {
+		t.Fatalf("Failed to write CODEOWNERS: %v", err)
+	}
+	commitFile("web/app.js", author1Name, author1Email, testTime(2023, 5, 1, 10))
+	commitFile("web/gen/bundle.js", author1Name, author1Email, testTime(2023, 5, 2, 10))
+	commitFile("api/main.go", author2Name, author2Email, testTime(2023, 5, 3, 10))
+	commitFile("api/README.md", author1Name, author1Email, testTime(2023, 5, 4, 10)) // *.md, but /api/ wins
+
+	opts := &gitcontributors.Options{StartDate: PtrTime(testTime(2023, 4, 1, 0)), EndDate: PtrTime(testTime(2023, 6, 1, 0))}
+	stale, err := gitcontributors.FindStaleOwnership(repoPath, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := []gitcontributors.StaleOwnership{
+		{Pattern: "*.md", Owners: []string{"@alice"}},
+		{Pattern: "/web/gen/", Owners: []string{"@org/frontend"}, Commits: 1, LastCommitDate: testTime(2023, 5, 2, 10)},
+		{Pattern: "/api/", Owners: []string{"@carol"}, Commits: 2, LastCommitDate: testTime(2023, 5, 4, 10)},
+	}
+	if !reflect.DeepEqual(stale, expected) {
+		t.Errorf("Stale ownership mismatch:\nExpected: %+v\nActual:   %+v", expected, stale)
+	}
+}
+
+func TestGetContributorsInclusiveEndDate(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "A C1", author1Name, author1Email, testTime(2023, 9, 1, 9))
+	gitCommit(t, repoPath, "B C1", author2Name, author2Email, testTime(2023, 9, 2, 15))
+
+	bareDate := testTime(2023, 9, 2, 0)
+	for _, tc := range []struct {
+		inclusive bool
+		expected  int
+	}{{false, 1}, {true, 2}} {
+		contributors, err := gitcontributors.GetContributors(repoPath, &gitcontributors.Options{EndDate: &bareDate, InclusiveEndDate: tc.inclusive})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if len(contributors) != tc.expected {
+			t.Errorf("InclusiveEndDate=%t: expected %d contributors, got %+v", tc.inclusive, tc.expected, contributors)
+		}
+	}
+}
+
+func TestRenderLeaderboard(t *testing.T) {
+	contributors := []gitcontributors.Contributor{
+		{Name: "Dana", Email: "dana@example.com", Commits: 1, LinesChanged: 900, ContributionScore: 10},
+		{Name: author2Name, Email: author2Email, Commits: 5, LinesChanged: 50, ContributionScore: 20},
+		{Name: author1Name, Email: author1Email, Commits: 5, LinesChanged: 10, ContributionScore: 30},
+		{Name: author4Name, Email: author4Email, Commits: 2, LinesChanged: 0, ContributionScore: 5},
+	}
+
+	byCommits := gitcontributors.RenderLeaderboard(contributors, gitcontributors.LeaderboardOptions{})
+	expected := "1. 🥇 **Alice Alpha** — 5 commits, 10 lines changed\n" +
+		"1. 🥇 **Bob Bravo** — 5 commits, 50 lines changed\n" +
+		"3. 🥉 **Charlie Charlie** — 2 commits, 0 lines changed\n" +
+		"4. **Dana** — 1 commits, 900 lines changed\n"
+	if byCommits != expected {
+		t.Errorf("Leaderboard by commits mismatch:\nExpected:\n%s\nActual:\n%s", expected, byCommits)
+	}
+
+	byLines := gitcontributors.RenderLeaderboard(contributors, gitcontributors.LeaderboardOptions{Metric: gitcontributors.RankByLines, Limit: 2})
+	expected = "1. 🥇 **Dana** — 1 commits, 900 lines changed\n" +
+		"2. 🥈 **Bob Bravo** — 5 commits, 50 lines changed\n"
+	if byLines != expected {
+		t.Errorf("Leaderboard by lines mismatch:\nExpected:\n%s\nActual:\n%s", expected, byLines)
+	}
+
+	byScore := gitcontributors.RenderLeaderboard(contributors, gitcontributors.LeaderboardOptions{Metric: gitcontributors.RankByScore, Limit: 1})
+	if byScore != "1. 🥇 **Alice Alpha** — 5 commits, 10 lines changed, score 30.0\n" {
+		t.Errorf("Unexpected leaderboard by score:\n%s", byScore)
+	}
+}
+
+func TestGetContributorsHugeMessage(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	hugeMessage := "Huge\n\n" + strings.Repeat("All work and no play makes Jack a dull boy.\n", 80_000) // ~3.5 MB
+	gitCommit(t, repoPath, hugeMessage, "Alice", "alice@example.com", testTime(2023, 9, 1, 10))
+	gitCommit(t, repoPath, "Small", "Alice", "alice@example.com", testTime(2023, 9, 2, 10))
+
+	contributors, err := gitcontributors.GetContributors(repoPath, &gitcontributors.Options{
+		StartDate: PtrTime(testTime(2023, 8, 1, 0)),
+		EndDate:   PtrTime(testTime(2023, 10, 1, 0)),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(contributors) != 1 || contributors[0].Commits != 2 {
+		t.Errorf("Expected Alice with 2 commits, got %+v", contributors)
+	}
+}
+
+func TestChurnByExtension(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(repoPath, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		runGitCommand(t, repoPath, "add", name)
+	}
+	commit := func(message string, when time.Time) {
+		t.Helper()
+		cmd := exec.Command("git", "commit", "-m", message)
+		cmd.Dir = repoPath
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+when.Format(time.RFC3339), "GIT_COMMITTER_DATE="+when.Format(time.RFC3339))
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git commit failed: %v\nOutput: %s", err, output)
+		}
+	}
+
+	write("main.go", "a\nb\nc\n")
+	write("pkg/util.GO", "x\n")
+	write("README.md", "doc\n")
+	write("Makefile", "all:\n")
+	commit("Initial", testTime(2023, 9, 1, 10))
+	write("main.go", "a\nc\nd\ne\n") // -1 +2
+	commit("Edit", testTime(2023, 9, 2, 10))
+
+	stats, err := gitcontributors.ChurnByExtension(repoPath, &gitcontributors.Options{
+		StartDate: PtrTime(testTime(2023, 8, 1, 0)),
+		EndDate:   PtrTime(testTime(2023, 10, 1, 0)),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := map[string]gitcontributors.ChurnStat{
+		".go":                       {Insertions: 6, Deletions: 1, Files: 2},
+		".md":                       {Insertions: 1, Files: 1},
+		gitcontributors.NoExtension: {Insertions: 1, Files: 1},
+	}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("Mismatch:\nExpected: %+v\nActual:   %+v", expected, stats)
+	}
+}
+
+func TestGetContributorsPaths(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	commit := func(file, content, authorName, authorEmail string, when time.Time) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(repoPath, file)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(repoPath, file), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		runGitCommand(t, repoPath, "add", file)
+		cmd := exec.Command("git", "commit", "-m", "Update "+file)
+		cmd.Dir = repoPath
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME="+authorName, "GIT_AUTHOR_EMAIL="+authorEmail, "GIT_AUTHOR_DATE="+when.Format(time.RFC3339),
+			"GIT_COMMITTER_NAME="+authorName, "GIT_COMMITTER_EMAIL="+authorEmail, "GIT_COMMITTER_DATE="+when.Format(time.RFC3339))
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git commit failed: %v\nOutput: %s", err, output)
+		}
+	}
+	commit("src/main.go", "a\nb\nc\n", author1Name, author1Email, testTime(2023, 9, 1, 10))
+	commit("docs/guide.md", "one\ntwo\n", author2Name, author2Email, testTime(2023, 9, 2, 10))
+	commit("src/main.go", "a\n", author1Name, author1Email, testTime(2023, 9, 3, 10))
+
+	testCases := []struct {
+		name     string
+		opts     *gitcontributors.Options
+		expected map[string]int // Email -> lines changed
+	}{
+		{"src only", &gitcontributors.Options{Paths: []string{"src/"}}, map[string]int{author1Email: 5}},
+		{"docs only", &gitcontributors.Options{Paths: []string{"docs/"}}, map[string]int{author2Email: 2}},
+		{"src excluded", &gitcontributors.Options{ExcludePaths: []string{"src/"}}, map[string]int{author2Email: 2}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			contributors, err := gitcontributors.GetContributors(repoPath, tc.opts)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			got := make(map[string]int)
+			for _, c := range contributors {
+				got[c.Email] = c.LinesChanged
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+			count, err := gitcontributors.CountContributors(repoPath, tc.opts)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if count != len(tc.expected) {
+				t.Errorf("Expected CountContributors to return %d, got %d", len(tc.expected), count)
+			}
+		})
+	}
+}
+
+func TestGetContributorsIdentityNormalizeRegex(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "C1", "Ann Lee", "ann@example.com", testTime(2023, 9, 1, 10))
+	gitCommit(t, repoPath, "C2", "CI (Ann)", "ann+ci@example.com", testTime(2023, 9, 2, 10))
+	gitCommit(t, repoPath, "C3", "ann", "ann@eu.example.com", testTime(2023, 9, 3, 10))
+	gitCommit(t, repoPath, "C4", "Ann Lee", "ann@example.com", testTime(2023, 9, 4, 10))
+
+	stripTag, err := gitcontributors.NewIdentityRule(`\+[^@]*@`, "@")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	dropSubdomain, err := gitcontributors.NewIdentityRule(`@[^@.]+\.(?P<domain>example\.com)$`, "@${domain}")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	opts := &gitcontributors.Options{
+		StartDate:              PtrTime(testTime(2023, 8, 1, 0)),
+		EndDate:                PtrTime(testTime(2023, 12, 31, 0)),
+		IdentityNormalizeRegex: []*gitcontributors.IdentityRule{stripTag, dropSubdomain},
+	}
+	contributors, err := gitcontributors.GetContributors(repoPath, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(contributors) != 1 || contributors[0].Email != "ann@example.com" || contributors[0].Commits != 4 {
+		t.Errorf("Expected one contributor ann@example.com with 4 commits, got %+v", contributors)
+	} else if contributors[0].Name != "Ann Lee" {
+		t.Errorf("Expected the most used name %q, got %q", "Ann Lee", contributors[0].Name)
+	}
+	if count, err := gitcontributors.CountContributors(repoPath, opts); err != nil || count != 1 {
+		t.Errorf("Expected CountContributors to return 1, got %d (error: %v)", count, err)
+	}
+
+	for _, tc := range []struct{ pattern, replacement string }{
+		{`[a-`, "@"},            // Invalid pattern
+		{`\+[^@]*@`, "$1@"},     // No such group
+		{`(?P<tag>\+)`, "${x}"}, // No such named group
+	} {
+		if _, err := gitcontributors.NewIdentityRule(tc.pattern, tc.replacement); err == nil {
+			t.Errorf("Expected an error for pattern %q and replacement %q", tc.pattern, tc.replacement)
+		}
+	}
+}
+
+func TestGetContributorsAliasMap(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "C1", "Alice Smith", "alice@example.com", testTime(2023, 9, 1, 10))
+	gitCommit(t, repoPath, "C2", "alice", "alice@corp.com", testTime(2023, 9, 5, 10))
+	gitCommit(t, repoPath, "C3", "A. Smith", "Alice@Corp.com", testTime(2023, 9, 9, 10))
+	gitCommit(t, repoPath, "C4", "Bob", "bob@example.com", testTime(2023, 9, 3, 10))
+
+	opts := &gitcontributors.Options{
+		StartDate: PtrTime(testTime(2023, 8, 1, 0)),
+		EndDate:   PtrTime(testTime(2023, 12, 31, 0)),
+		AliasMap:  map[string]string{"ALICE@corp.com": "alice@example.com"},
+	}
+	contributors, err := gitcontributors.GetContributors(repoPath, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(contributors) != 2 {
+		t.Fatalf("Expected 2 contributors, got %d: %+v", len(contributors), contributors)
+	}
+	alice := contributors[0]
+	if alice.Email != "alice@example.com" || alice.Commits != 3 {
+		t.Errorf("Expected alice@example.com with 3 commits, got %+v", alice)
+	}
+	if alice.Name != "A. Smith" { // Each name has one commit, so the most recent wins
+		t.Errorf("Expected the most recent name %q, got %q", "A. Smith", alice.Name)
+	}
+	if !alice.FirstCommitDate.Equal(testTime(2023, 9, 1, 10)) || !alice.LastCommitDate.Equal(testTime(2023, 9, 9, 10)) {
+		t.Errorf("Expected Alice's commits to span 2023-09-01 to 2023-09-09, got %v to %v", alice.FirstCommitDate, alice.LastCommitDate)
+	}
+	if contributors[1].Email != "bob@example.com" || contributors[1].Commits != 1 {
+		t.Errorf("Expected bob@example.com with 1 commit, got %+v", contributors[1])
+	}
+	if count, err := gitcontributors.CountContributors(repoPath, opts); err != nil || count != 2 {
+		t.Errorf("Expected CountContributors to return 2, got %d (error: %v)", count, err)
+	}
+	cohorts, err := gitcontributors.CohortAnalysis(repoPath, opts)
+	if err != nil || len(cohorts["2023-09"]) != 2 {
+		t.Errorf("Expected both contributors in the 2023-09 cohort, got %+v (error: %v)", cohorts, err)
+	}
+
+	opts.AliasMap = map[string]string{"alice@corp.com": "alice@example.com", "Alice@Corp.com": "other@example.com"}
+	if _, err := gitcontributors.GetContributors(repoPath, opts); err == nil {
+		t.Error("Expected an error for aliases differing only by case with different targets")
+	}
+}
+
+func TestNetLinesChanged(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(repoPath, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		runGitCommand(t, repoPath, "add", name)
+	}
+	commit := func(message string, when time.Time) {
+		t.Helper()
+		cmd := exec.Command("git", "commit", "-m", message)
+		cmd.Dir = repoPath
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+when.Format(time.RFC3339), "GIT_COMMITTER_DATE="+when.Format(time.RFC3339))
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git commit failed: %v\nOutput: %s", err, output)
+		}
+	}
+
+	write("main.go", "a\nb\nc\nd\n")
+	write("docs/guide.md", "one\ntwo\n")
+	write("logo.bin", "\x00\x01\x02")
+	commit("Initial", testTime(2023, 9, 1, 10))
+	write("main.go", "a\n")         // -3
+	write("docs/guide.md", "one\n") // -1
+	write("logo.bin", "\x00\x03")   // Binary: no lines
+	commit("Trim", testTime(2023, 9, 2, 10))
+
+	window := gitcontributors.Options{StartDate: PtrTime(testTime(2023, 8, 1, 0)), EndDate: PtrTime(testTime(2023, 10, 1, 0))}
+	added, deleted, net, err := gitcontributors.NetLinesChanged(repoPath, &window)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if added != 6 || deleted != 4 || net != 2 {
+		t.Errorf("Expected 6 added, 4 deleted, net 2; got %d, %d, %d", added, deleted, net)
+	}
+
+	filtered := window
+	filtered.ExcludePaths = []string{"docs/"}
+	added, deleted, net, err = gitcontributors.NetLinesChanged(repoPath, &filtered)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if added != 4 || deleted != 3 || net != 1 {
+		t.Errorf("Expected 4 added, 3 deleted, net 1 without docs; got %d, %d, %d", added, deleted, net)
+	}
+}
+
+func TestContributorsByMonth(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "Jan A", author1Name, author1Email, testTime(2023, 1, 10, 10))
+	gitCommit(t, repoPath, "Jan B", author2Name, author2Email, testTime(2023, 1, 20, 10))
+	gitCommit(t, repoPath, "Jan A again", author1Name, author1Email, testTime(2023, 1, 25, 10))
+	gitCommit(t, repoPath, "Mar A", author1Name, author1Email, testTime(2023, 3, 5, 10))
+
+	months, err := gitcontributors.ContributorsByMonth(repoPath, &gitcontributors.Options{
+		StartDate: PtrTime(testTime(2023, 1, 1, 0)),
+		EndDate:   PtrTime(testTime(2023, 4, 30, 0)),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	commits := make(map[string]map[string]int, len(months))
+	for month, contributors := range months {
+		commits[month] = make(map[string]int)
+		for _, c := range contributors {
+			commits[month][c.Email] = c.Commits
+		}
+	}
+	expected := map[string]map[string]int{
+		"2023-01": {author1Email: 2, author2Email: 1},
+		"2023-02": {},
+		"2023-03": {author1Email: 1},
+		"2023-04": {},
+	}
+	if !reflect.DeepEqual(commits, expected) {
+		t.Errorf("Mismatch:\nExpected: %v\nActual:   %v", expected, commits)
+	}
+	if jan := months["2023-01"]; len(jan) == 2 && (!jan[0].FirstCommitDate.Equal(testTime(2023, 1, 10, 10)) || !jan[0].LastCommitDate.Equal(testTime(2023, 1, 25, 10))) {
+		t.Errorf("Expected January dates for %s, got %+v", jan[0].Email, jan[0])
+	}
+}
+
+func TestCohortAnalysis(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "Alice joins", "Alice", "alice@example.com", testTime(2023, 1, 10, 10))
+	gitCommit(t, repoPath, "Bob joins", "Bob", "bob@example.com", testTime(2023, 2, 5, 10))
+	gitCommit(t, repoPath, "Carol joins", "Carol", "carol@example.com", testTime(2023, 5, 20, 10))
+	gitCommit(t, repoPath, "Alice again", "Alice", "alice@example.com", testTime(2023, 6, 1, 10))
+
+	window := gitcontributors.Options{StartDate: PtrTime(testTime(2023, 5, 1, 0)), EndDate: PtrTime(testTime(2023, 7, 1, 0))}
+	cohortNames := func(cohorts map[string][]gitcontributors.Contributor) map[string][]string {
+		names := make(map[string][]string, len(cohorts))
+		for key, contributors := range cohorts {
+			for _, c := range contributors {
+				names[key] = append(names[key], c.Name)
+			}
+			sort.Strings(names[key])
+		}
+		return names
+	}
+
+	monthly, err := gitcontributors.CohortAnalysis(repoPath, &window)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// Alice is only active in the window with her June commit but joined in January.
+	if expected := map[string][]string{"2023-01": {"Alice"}, "2023-05": {"Carol"}}; !reflect.DeepEqual(cohortNames(monthly), expected) {
+		t.Errorf("Monthly mismatch:\nExpected: %v\nActual:   %v", expected, cohortNames(monthly))
+	}
+	if alice := monthly["2023-01"][0]; alice.Commits != 1 {
+		t.Errorf("Expected Alice's stats limited to the window (1 commit), got %d", alice.Commits)
+	}
+
+	quarterly := window
+	quarterly.StartDate = PtrTime(testTime(2023, 1, 1, 0))
+	quarterly.CohortQuarterly = true
+	byQuarter, err := gitcontributors.CohortAnalysis(repoPath, &quarterly)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if expected := map[string][]string{"2023-Q1": {"Alice", "Bob"}, "2023-Q2": {"Carol"}}; !reflect.DeepEqual(cohortNames(byQuarter), expected) {
+		t.Errorf("Quarterly mismatch:\nExpected: %v\nActual:   %v", expected, cohortNames(byQuarter))
+	}
+}
+
+func TestGetContributorsExcludeAuthorsMatching(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "Maintainer", "Mia Maintainer", "mia@mycompany.com", testTime(2023, 10, 1, 10))
+	gitCommit(t, repoPath, "Bot", "release-bot", "bot@ci.example.org", testTime(2023, 10, 2, 10))
+	gitCommit(t, repoPath, "Outside", author2Name, author2Email, testTime(2023, 10, 3, 10))
+
+	contributors, err := gitcontributors.GetContributors(repoPath, &gitcontributors.Options{
+		StartDate:              PtrTime(testTime(2023, 9, 1, 0)),
+		EndDate:                PtrTime(testTime(2023, 11, 1, 0)),
+		ExcludeAuthorsMatching: []string{`@mycompany\.com$`, `-bot$`},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(contributors) != 1 || contributors[0].Email != author2Email {
+		t.Errorf("Expected only %s, got %+v", author2Email, contributors)
+	}
+
+	_, err = gitcontributors.GetContributors(repoPath, &gitcontributors.Options{ExcludeAuthorsMatching: []string{"("}})
+	if err == nil || !strings.Contains(err.Error(), "invalid ExcludeAuthorsMatching pattern") {
+		t.Errorf("Expected an invalid pattern error, got %v", err)
+	}
+}
+
+func TestGetContributorsLines(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	commit := func(name, email string, files map[string]string, when time.Time) {
+		t.Helper()
+		for file, content := range files {
+			if err := os.WriteFile(filepath.Join(repoPath, file), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			runGitCommand(t, repoPath, "add", file)
+		}
+		cmd := exec.Command("git", "commit", "-m", "Change")
+		cmd.Dir = repoPath
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME="+name, "GIT_AUTHOR_EMAIL="+email, "GIT_AUTHOR_DATE="+when.Format(time.RFC3339),
+			"GIT_COMMITTER_NAME="+name, "GIT_COMMITTER_EMAIL="+email, "GIT_COMMITTER_DATE="+when.Format(time.RFC3339))
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git commit failed: %v\nOutput: %s", err, output)
+		}
+	}
+
+	commit(author1Name, author1Email, map[string]string{"a.txt": "1\n2\n3\n4\n", "image.bin": "\x00\x01"}, testTime(2023, 9, 1, 10))
+	commit(author2Name, author2Email, map[string]string{"a.txt": "1\n2\nthree\n"}, testTime(2023, 9, 2, 10)) // +1 -2
+	commit(author1Name, author1Email, map[string]string{"b.txt": "x\ny\n", "image.bin": "\x00\x02"}, testTime(2023, 9, 3, 10))
+
+	contributors, err := gitcontributors.GetContributors(repoPath, &gitcontributors.Options{
+		StartDate: PtrTime(testTime(2023, 8, 1, 0)),
+		EndDate:   PtrTime(testTime(2023, 10, 1, 0)),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := map[string][3]int{ // Added, deleted, changed; the binary file counts as zero
+		author1Email: {6, 0, 6},
+		author2Email: {1, 2, 3},
+	}
+	if len(contributors) != len(expected) {
+		t.Fatalf("Expected %d contributors, got %+v", len(expected), contributors)
+	}
+	for _, c := range contributors {
+		if got := [3]int{c.LinesAdded, c.LinesDeleted, c.LinesChanged}; got != expected[c.Email] {
+			t.Errorf("Lines mismatch for %s: expected %v, got %v", c.Email, expected[c.Email], got)
+		}
+	}
+}
+
+func TestGetContributorsRedactEmails(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "One", author1Name, author1Email, testTime(2023, 9, 1, 10))
+	gitCommit(t, repoPath, "Two", author1Name, author1Email, testTime(2023, 9, 2, 10))
+	gitCommit(t, repoPath, "Three", author2Name, author2Email, testTime(2023, 9, 3, 10))
+
+	contributors, err := gitcontributors.GetContributors(repoPath, &gitcontributors.Options{
+		StartDate:    PtrTime(testTime(2023, 8, 1, 0)),
+		EndDate:      PtrTime(testTime(2023, 10, 1, 0)),
+		RedactEmails: true,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	emails := make(map[string]int)
+	for _, c := range contributors {
+		emails[c.Email] = c.Commits
+	}
+	expected := map[string]int{"a***@example.com": 2, "b***@example.com": 1}
+	if !reflect.DeepEqual(emails, expected) {
+		t.Errorf("Expected redacted emails %v, got %v", expected, emails)
+	}
+}
+
+func TestGetContributorsPipeInName(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "Piped", "Dana | Ops", "dana@example.com", testTime(2023, 10, 1, 10))
+	gitCommit(t, repoPath, "Piped again", "Dana | Ops", "dana@example.com", testTime(2023, 10, 2, 10))
+
+	opts := &gitcontributors.Options{StartDate: PtrTime(testTime(2023, 9, 1, 0)), EndDate: PtrTime(testTime(2023, 11, 1, 0))}
+	contributors, err := gitcontributors.GetContributors(repoPath, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := []gitcontributors.Contributor{{
+		Name:            "Dana | Ops",
+		Email:           "dana@example.com",
+		Commits:         2,
+		FirstCommitDate: testTime(2023, 10, 1, 10),
+		LastCommitDate:  testTime(2023, 10, 2, 10),
+		LinesAdded:      6, // Each gitCommit adds a three-line file
+		LinesChanged:    6,
+	}}
+	if !reflect.DeepEqual(contributors, expected) {
+		t.Errorf("Mismatch:\nExpected: %+v\nActual:   %+v", expected, contributors)
+	}
+	if count, err := gitcontributors.CountContributors(repoPath, opts); err != nil || count != 1 {
+		t.Errorf("Expected a count of 1, got %d (err: %v)", count, err)
+	}
+}
+
+func TestGetContributorsUnknownRef(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	opts := &gitcontributors.Options{ExtraArgs: []string{"v9.9"}}
+
+	_, err := gitcontributors.GetContributors(repoPath, opts)
+	var unknownRef gitcontributors.ErrUnknownRef
+	if !errors.As(err, &unknownRef) || unknownRef.Ref != "v9.9" {
+		t.Fatalf("Expected ErrUnknownRef for v9.9, got %v", err)
+	}
+	if err.Error() != "reference 'v9.9' not found in repository" {
+		t.Errorf("Unexpected message: %q", err.Error())
+	}
+
+	_, err = gitcontributors.CountContributors(repoPath, &gitcontributors.Options{ExtraArgs: []string{"main..nope"}})
+	if !errors.As(err, &unknownRef) || unknownRef.Ref != "nope" {
+		t.Errorf("Expected ErrUnknownRef for the missing end of the range, got %v", err)
+	}
+}
+
+func TestTimezoneDistribution(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	berlin := time.FixedZone("CEST", 2*3600)
+	newYork := time.FixedZone("EST", -5*3600)
+	gitCommit(t, repoPath, "Berlin 1", author1Name, author1Email, time.Date(2023, 10, 1, 9, 0, 0, 0, berlin))
+	gitCommit(t, repoPath, "Berlin 2", author1Name, author1Email, time.Date(2023, 10, 2, 9, 0, 0, 0, berlin))
+	gitCommit(t, repoPath, "New York", author2Name, author2Email, time.Date(2023, 10, 2, 9, 0, 0, 0, newYork))
+	gitCommit(t, repoPath, "UTC", author2Name, author2Email, testTime(2023, 10, 3, 9))
+
+	distribution, err := gitcontributors.TimezoneDistribution(repoPath, &gitcontributors.Options{
+		StartDate: PtrTime(testTime(2023, 9, 1, 0)),
+		EndDate:   PtrTime(testTime(2023, 11, 1, 0)),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := map[string]int{"+02:00": 2, "-05:00": 1, "+00:00": 1}
+	if !reflect.DeepEqual(distribution, expected) {
+		t.Errorf("Mismatch:\nExpected: %v\nActual:   %v", expected, distribution)
+	}
+}
@@ -0,0 +1,99 @@
+package gitcontributors
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/Stone-IT-Cloud/reporting/internal/gitexec"
+)
+
+// CountContributors returns the number of distinct contributors that GetContributors
+// would return for the same options, without aggregating dates or per-person data.
+// Like GetContributors, it skips commits whose author date cannot be parsed.
+// It honors StartDate, EndDate, IncludeMergeCommits, GroupMissingEmails, ExtraArgs and
+// ExcludeAuthorsMatching; ActiveSince/ActiveWithin and ScoreWeights need full
+// aggregation and are ignored.
+func CountContributors(repoPath string, opts *Options) (int, error) {
+	absRepoPath, err := gitexec.ValidateRepoPath(repoPath)
+	if err != nil {
+		return 0, err
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	if err := gitexec.ValidateExtraArgs(opts.ExtraArgs); err != nil {
+		return 0, err
+	}
+	excludedAuthors, err := gitexec.CompileAuthorPatterns(opts.ExcludeAuthorsMatching)
+	if err != nil {
+		return 0, err
+	}
+	identities, err := opts.identityResolver()
+	if err != nil {
+		return 0, err
+	}
+
+	logger := opts.logger()
+
+	// Same encoding and fields as GetContributors, so both agree on names and skipped commits.
+	args := []string{"log", "--encoding=UTF-8", "--pretty=format:%aN%x00%aE%x00%aI"}
+	if opts.StartDate != nil {
+		args = append(args, "--after="+opts.StartDate.Format(time.RFC3339))
+	}
+	if end := opts.endDate(); end != nil {
+		args = append(args, "--before="+end.Format(time.RFC3339))
+	}
+	if !opts.IncludeMergeCommits {
+		args = append(args, "--no-merges")
+	}
+	args = append(args, opts.ExtraArgs...)
+	args = append(args, "--")
+	args = append(args, opts.pathspecs()...)
+
+	// Keys mirror GetContributors so both functions agree on who is distinct.
+	seen := make(map[string]struct{})
+	sawOutput, stderrStr, err := gitexec.Stream(absRepoPath, args, '\n', func(line string) {
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) != 3 {
+			return
+		}
+		name, email := strings.TrimSpace(parts[0]), identities.normalize(strings.TrimSpace(parts[1]))
+		if name == "" && email == "" {
+			return
+		}
+		if gitexec.AuthorExcluded(excludedAuthors, name, email) {
+			return
+		}
+		if email == "" {
+			if !opts.GroupMissingEmails {
+				return
+			}
+			name = UnknownContributorName
+		}
+		if _, err := time.Parse(time.RFC3339, strings.TrimSpace(parts[2])); err != nil {
+			logger.Warn("skipping commit with unparseable date", "date", parts[2], "error", err)
+			return
+		}
+		seen[identities.key(name, email)] = struct{}{}
+	})
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return 0, fmt.Errorf("git log command failed (path: %q, args: %v): %w", absRepoPath, args, err)
+		}
+		if refErr := unknownRefError(absRepoPath, stderrStr); refErr != nil {
+			return 0, refErr
+		}
+		if strings.Contains(stderrStr, "does not have any commits") ||
+			strings.Contains(stderrStr, "bad default revision 'HEAD'") ||
+			!sawOutput {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("git log command failed (path: %q, args: %v): %w\nstderr: %s",
+			absRepoPath, args, err, stderrStr)
+	}
+	return len(seen), nil
+}
@@ -0,0 +1,135 @@
+package gitcontributors
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IdentityRule rewrites author emails with a regular expression before contributors are
+// told apart, so that aliases following an organizational convention are counted as one
+// contributor. Create rules with NewIdentityRule.
+type IdentityRule struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// replacementRef matches the $n, $name and ${name} references of a replacement
+// template, and the $$ escape.
+var replacementRef = regexp.MustCompile(`\$(\$|\{[^}]*\}|[A-Za-z0-9_]+)`)
+
+// NewIdentityRule returns a rule replacing every match of pattern (Go syntax) in an email
+// with replacement, which may refer to capture groups as in regexp.Expand ($1, ${name}).
+// For example, `\+[^@]*@` with "@" strips "+tag" suffixes from local parts, and
+// `@[^@.]+\.(example\.com)$` with "@$1" drops a subdomain. An error is returned if
+// pattern does not compile or replacement refers to a group pattern does not have.
+func NewIdentityRule(pattern, replacement string) (*IdentityRule, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identity pattern %q: %w", pattern, err)
+	}
+	for _, m := range replacementRef.FindAllStringSubmatch(replacement, -1) {
+		ref := m[1]
+		if ref == "$" {
+			continue
+		}
+		if ref[0] == '{' {
+			ref = ref[1 : len(ref)-1]
+		}
+		if n, err := strconv.Atoi(ref); err == nil {
+			if n > re.NumSubexp() {
+				return nil, fmt.Errorf("identity replacement %q refers to group %d, but pattern %q has %d", replacement, n, pattern, re.NumSubexp())
+			}
+			continue
+		}
+		if re.SubexpIndex(ref) < 0 {
+			return nil, fmt.Errorf("identity replacement %q refers to unknown group %q of pattern %q", replacement, ref, pattern)
+		}
+	}
+	return &IdentityRule{re: re, replacement: replacement}, nil
+}
+
+// apply rewrites email with the rule.
+func (r *IdentityRule) apply(email string) string {
+	if r == nil || r.re == nil {
+		return email
+	}
+	return r.re.ReplaceAllString(email, r.replacement)
+}
+
+// identityResolver applies IdentityNormalizeRegex and AliasMap to author emails and
+// decides which commits belong to the same contributor. Build it once per call with
+// Options.identityResolver.
+type identityResolver struct {
+	rules   []*IdentityRule
+	aliases map[string]string // Lowercased alias -> canonical email
+}
+
+// identityResolver returns the resolver for o. An error is returned if AliasMap has two
+// aliases that differ only by case but map to different emails.
+func (o *Options) identityResolver() (*identityResolver, error) {
+	r := &identityResolver{rules: o.IdentityNormalizeRegex}
+	if len(o.AliasMap) > 0 {
+		r.aliases = make(map[string]string, len(o.AliasMap))
+		for alias, canonical := range o.AliasMap {
+			key := strings.ToLower(strings.TrimSpace(alias))
+			if existing, ok := r.aliases[key]; ok && existing != canonical {
+				return nil, fmt.Errorf("alias %q maps to both %q and %q", key, existing, canonical)
+			}
+			r.aliases[key] = canonical
+		}
+	}
+	return r, nil
+}
+
+// merges reports whether identity rules are configured, in which case contributors are
+// told apart by their canonical email alone.
+func (r *identityResolver) merges() bool {
+	return len(r.rules) > 0 || len(r.aliases) > 0
+}
+
+// normalize applies IdentityNormalizeRegex to email, in order, then AliasMap.
+func (r *identityResolver) normalize(email string) string {
+	for _, rule := range r.rules {
+		email = rule.apply(email)
+	}
+	if canonical, ok := r.aliases[strings.ToLower(email)]; ok {
+		return canonical
+	}
+	return email
+}
+
+// key returns the lowercased identity that commits are aggregated by: "name<email>", or
+// the email alone when identity rules are configured, so that aliases recorded under
+// different names still merge.
+func (r *identityResolver) key(name, email string) string {
+	if r.merges() {
+		return strings.ToLower(email)
+	}
+	return strings.ToLower(fmt.Sprintf("%s<%s>", name, email))
+}
+
+// nameUse counts the commits recorded under one author name of a merged contributor.
+type nameUse struct {
+	commits int
+	last    time.Time
+}
+
+// dominantName returns the name used by the most commits, the most recently used one on
+// a tie, then the lexicographically smallest, so the choice does not depend on log order.
+func dominantName(names map[string]*nameUse) string {
+	best := ""
+	var bestUse *nameUse
+	for name, use := range names {
+		switch {
+		case bestUse == nil,
+			use.commits > bestUse.commits,
+			use.commits == bestUse.commits && use.last.After(bestUse.last),
+			use.commits == bestUse.commits && use.last.Equal(bestUse.last) && name < best:
+			best, bestUse = name, use
+		}
+	}
+	return best
+}
@@ -0,0 +1,75 @@
+package gitcontributors
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LeaderboardMetric selects the value contributors are ranked by.
+type LeaderboardMetric string
+
+const (
+	RankByCommits LeaderboardMetric = "commits" // Contributor.Commits (the default)
+	RankByScore   LeaderboardMetric = "score"   // Contributor.ContributionScore
+	RankByLines   LeaderboardMetric = "lines"   // Contributor.LinesChanged
+)
+
+// LeaderboardOptions configures RenderLeaderboard.
+type LeaderboardOptions struct {
+	// Metric ranks contributors; empty or unknown values rank by commits.
+	// Score is only meaningful if GetContributors ran with ScoreWeights.
+	Metric LeaderboardMetric
+	// Limit caps the number of entries; zero lists everyone.
+	Limit int
+}
+
+// leaderboardMedals decorates the first three ranks.
+var leaderboardMedals = []string{"🥇", "🥈", "🥉"}
+
+// RenderLeaderboard renders contributors as a ranked markdown list, highest first,
+// with medals for the top three ranks. Contributors with equal values share a rank
+// (1, 1, 3, ...) and are ordered by name and email. Each entry shows commits and
+// churn, plus the score when ranking by score.
+func RenderLeaderboard(contributors []Contributor, opts LeaderboardOptions) string {
+	if len(contributors) == 0 {
+		return "_No contributors in this period._\n"
+	}
+	metric := opts.Metric
+	value := func(c Contributor) float64 {
+		switch metric {
+		case RankByScore:
+			return c.ContributionScore
+		case RankByLines:
+			return float64(c.LinesChanged)
+		default:
+			return float64(c.Commits)
+		}
+	}
+
+	ranked := append([]Contributor(nil), contributors...)
+	sortContributors(ranked)
+	sort.SliceStable(ranked, func(i, j int) bool { return value(ranked[i]) > value(ranked[j]) })
+	if opts.Limit > 0 && len(ranked) > opts.Limit {
+		ranked = ranked[:opts.Limit]
+	}
+
+	var b strings.Builder
+	rank := 0
+	for i, c := range ranked {
+		if i == 0 || value(c) != value(ranked[i-1]) {
+			rank = i + 1
+		}
+		medal := ""
+		if rank <= len(leaderboardMedals) {
+			medal = leaderboardMedals[rank-1] + " "
+		}
+		stats := fmt.Sprintf("%d commits, %d lines changed", c.Commits, c.LinesChanged)
+		if metric == RankByScore {
+			stats += ", score " + strconv.FormatFloat(c.ContributionScore, 'f', 1, 64)
+		}
+		fmt.Fprintf(&b, "%d. %s**%s** — %s\n", rank, medal, c.Name, stats)
+	}
+	return b.String()
+}
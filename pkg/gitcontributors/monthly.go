@@ -0,0 +1,65 @@
+package gitcontributors
+
+import (
+	"time"
+
+	"github.com/Stone-IT-Cloud/reporting/internal/gitexec"
+)
+
+// ContributorsByMonth returns, for each month in range keyed "YYYY-MM" (UTC), the
+// contributors who committed that month with their stats for that month only (commits,
+// lines, first and last commit, and ContributionScore with ScoreWeights). This is the
+// data behind "activity over time by person" charts.
+//
+// All months from StartDate (or the first commit) to EndDate (or the last commit) are
+// present, those without commits with an empty slice. Each month is sorted like
+// GetContributors. The history is read in a single git log pass; every option of
+// GetContributors applies except ActiveSince and ActiveWithin.
+func ContributorsByMonth(repoPath string, opts *Options) (map[string][]Contributor, error) {
+	absRepoPath, err := gitexec.ValidateRepoPath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	months, err := aggregateContributors(absRepoPath, opts, func(t time.Time) string {
+		return cohortKey(t, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(months) == 0 && opts.StartDate == nil && opts.EndDate == nil {
+		return months, nil
+	}
+
+	// Fill the months without commits so charts get a continuous axis.
+	var first, last string
+	for month := range months {
+		if first == "" || month < first {
+			first = month
+		}
+		if last == "" || month > last {
+			last = month
+		}
+	}
+	if opts.StartDate != nil {
+		first = cohortKey(*opts.StartDate, false)
+	}
+	if end := opts.endDate(); end != nil {
+		last = cohortKey(*end, false)
+	}
+	if first == "" || last == "" {
+		return months, nil // Open-ended range without any commit to anchor it
+	}
+	start, err := time.Parse("2006-01", first)
+	if err != nil {
+		return nil, err
+	}
+	for month := start; month.Format("2006-01") <= last; month = month.AddDate(0, 1, 0) {
+		if key := month.Format("2006-01"); months[key] == nil {
+			months[key] = []Contributor{}
+		}
+	}
+	return months, nil
+}
@@ -0,0 +1,55 @@
+package gitcontributors
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// prometheusMetric describes one gauge emitted by WriteContributorsPrometheus.
+type prometheusMetric struct {
+	name  string
+	help  string
+	value func(Contributor) float64
+}
+
+// contributorMetrics lists the gauges written per contributor, in output order.
+var contributorMetrics = []prometheusMetric{
+	{"git_contributor_commits", "Number of commits by the contributor in the report range.", func(c Contributor) float64 { return float64(c.Commits) }},
+	{"git_contributor_first_commit_timestamp_seconds", "Unix time of the contributor's first commit in the report range.", func(c Contributor) float64 { return float64(c.FirstCommitDate.Unix()) }},
+	{"git_contributor_last_commit_timestamp_seconds", "Unix time of the contributor's last commit in the report range.", func(c Contributor) float64 { return float64(c.LastCommitDate.Unix()) }},
+	{"git_contributor_contribution_score", "Heuristic contribution score; zero unless scoring was enabled.", func(c Contributor) float64 { return c.ContributionScore }},
+}
+
+// WriteContributorsPrometheus writes contributors as gauges in the Prometheus text
+// exposition format, labelled with repo, author (name) and email, e.g.
+//
+//	git_contributor_commits{repo="x",author="Alice",email="alice@example.com"} 42
+//
+// Label values are escaped as the format requires.
+func WriteContributorsPrometheus(w io.Writer, contributors []Contributor, repo string) error {
+	bw := bufio.NewWriter(w)
+	for _, metric := range contributorMetrics {
+		fmt.Fprintf(bw, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(bw, "# TYPE %s gauge\n", metric.name)
+		for _, c := range contributors {
+			fmt.Fprintf(bw, "%s{repo=\"%s\",author=\"%s\",email=\"%s\"} %s\n",
+				metric.name, escapeLabelValue(repo), escapeLabelValue(c.Name), escapeLabelValue(c.Email),
+				strconv.FormatFloat(metric.value(c), 'f', -1, 64))
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write Prometheus metrics: %w", err)
+	}
+	return nil
+}
+
+// labelValueEscaper escapes backslashes, double quotes and newlines in label values.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabelValue makes s safe to embed in a quoted Prometheus label value.
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
@@ -0,0 +1,23 @@
+package gitcontributors
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// redactEmail masks the local part of email, keeping its first character and the domain
+// ("alice@example.com" -> "a***@example.com"). Duplicated from gitlogs.RedactEmail.
+func redactEmail(email string) string {
+	if email == "" {
+		return ""
+	}
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return "***"
+	}
+	if local == "" {
+		return "***@" + domain
+	}
+	_, size := utf8.DecodeRuneInString(local) // Keep a whole character, even if multi-byte
+	return local[:size] + "***@" + domain
+}
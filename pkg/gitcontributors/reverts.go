@@ -0,0 +1,101 @@
+package gitcontributors
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Stone-IT-Cloud/reporting/internal/gitexec"
+)
+
+// revertPattern matches the line git revert adds to the message of a revert commit.
+var revertPattern = regexp.MustCompile(`This reverts commit ([0-9a-f]{40})`)
+
+// revertedPairs returns the hashes of commits whose changes cancel out through reverts
+// within the range selected by opts. A revert chain (a commit, its revert, the revert of
+// that revert, and so on) cancels pairwise from its end: with an even number of reverts
+// only the reverts are returned and the original commit's changes stand; with an odd
+// number the original commit is returned too. Reverts of commits outside the range are
+// not returned.
+func revertedPairs(absRepoPath string, opts *Options) (map[string]struct{}, error) {
+	if err := gitexec.ValidateExtraArgs(opts.ExtraArgs); err != nil {
+		return nil, err
+	}
+	args := []string{"log", "--pretty=format:%x1e%H%x00%B"}
+	if opts.StartDate != nil {
+		args = append(args, "--after="+opts.StartDate.Format(time.RFC3339))
+	}
+	if end := opts.endDate(); end != nil {
+		args = append(args, "--before="+end.Format(time.RFC3339))
+	}
+	if !opts.IncludeMergeCommits {
+		args = append(args, "--no-merges")
+	}
+	args = append(args, opts.ExtraArgs...)
+	args = append(args, "--")
+
+	inRange := make(map[string]struct{})
+	reverts := make(map[string]string) // Revert hash -> target hash
+	var order []string                 // Hashes newest first, as git log lists them
+	// Messages span several lines, so records are split on the marker instead.
+	sawOutput, stderrStr, err := gitexec.Stream(absRepoPath, args, '\x1e', func(record string) {
+		hash, message, ok := strings.Cut(record, "\x00")
+		if !ok {
+			return
+		}
+		hash = strings.TrimSpace(hash)
+		inRange[hash] = struct{}{}
+		order = append(order, hash)
+		if m := revertPattern.FindStringSubmatch(message); m != nil {
+			reverts[hash] = m[1]
+		}
+	})
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("git log command failed while detecting reverts: %w", err)
+		}
+		if !sawOutput {
+			return map[string]struct{}{}, nil // Empty repository or nothing in range
+		}
+		return nil, fmt.Errorf("git log command failed while detecting reverts: %w\nstderr: %s", err, stderrStr)
+	}
+
+	// revertedBy links each in-range commit to the earliest in-range revert of it; later
+	// duplicate reverts start chains of their own.
+	revertedBy := make(map[string]string)
+	for i := len(order) - 1; i >= 0; i-- {
+		revert := order[i]
+		target, ok := reverts[revert]
+		if !ok {
+			continue
+		}
+		if _, ok := inRange[target]; !ok {
+			continue
+		}
+		if _, taken := revertedBy[target]; !taken {
+			revertedBy[target] = revert
+		}
+	}
+
+	excluded := make(map[string]struct{})
+	for root := range revertedBy {
+		if target, ok := reverts[root]; ok && revertedBy[target] == root {
+			continue // Part of a longer chain, handled from its root
+		}
+		chain := []string{root}
+		for next, ok := revertedBy[root]; ok; next, ok = revertedBy[next] {
+			chain = append(chain, next)
+		}
+		if len(chain)%2 == 1 {
+			chain = chain[1:] // An even number of reverts: the root's changes stand
+		}
+		for _, hash := range chain {
+			excluded[hash] = struct{}{}
+		}
+	}
+	return excluded, nil
+}
@@ -0,0 +1,63 @@
+package gitcontributors
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ScoreWeights configures how ContributionScore is computed. The score is a heuristic:
+// it is meant for ranking contributors relative to each other within one report, not
+// as an absolute measure of effort or value.
+//
+//	score = Commits*commits + LinesChanged*(added+deleted) + FilesTouched*distinctFiles + ActiveDays*distinctDays
+type ScoreWeights struct {
+	Commits      float64 // Weight per commit.
+	LinesChanged float64 // Weight per inserted or deleted line (binary files count as zero).
+	FilesTouched float64 // Weight per distinct file path modified.
+	ActiveDays   float64 // Weight per distinct UTC calendar day with at least one commit.
+}
+
+// DefaultScoreWeights returns weights that favor sustained activity over raw volume:
+// a day of work counts for two commits, and a hundred changed lines count for one.
+func DefaultScoreWeights() *ScoreWeights {
+	return &ScoreWeights{
+		Commits:      1.0,
+		LinesChanged: 0.01,
+		FilesTouched: 0.25,
+		ActiveDays:   2.0,
+	}
+}
+
+// score applies the weights to the aggregated data of a single contributor.
+func (w *ScoreWeights) score(data *aggregatedContributorData) float64 {
+	return w.Commits*float64(data.Commits) +
+		w.LinesChanged*float64(data.LinesChanged) +
+		w.FilesTouched*float64(len(data.FilesTouched)) +
+		w.ActiveDays*float64(len(data.ActiveDays))
+}
+
+// addNumstatLine parses a single numstat line (see parseNumstatLine) and accumulates
+// it into data. Unrecognized lines are ignored.
+func addNumstatLine(data *aggregatedContributorData, line string) {
+	added, deleted, path, ok := parseNumstatLine(line)
+	if !ok {
+		return
+	}
+	data.LinesAdded += added
+	data.LinesDeleted += deleted
+	data.LinesChanged += added + deleted
+	data.FilesTouched[path] = struct{}{}
+}
+
+// parseNumstatLine splits a single "added<TAB>deleted<TAB>path" line produced by
+// git log --numstat. Binary files report "-" for both counts and yield zero lines.
+// ok is false for lines that are not numstat lines.
+func parseNumstatLine(line string) (added, deleted int, path string, ok bool) {
+	fields := strings.SplitN(line, "\t", 3)
+	if len(fields) != 3 {
+		return 0, 0, "", false
+	}
+	added, _ = strconv.Atoi(fields[0])
+	deleted, _ = strconv.Atoi(fields[1])
+	return added, deleted, fields[2], true
+}
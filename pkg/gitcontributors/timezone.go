@@ -0,0 +1,84 @@
+package gitcontributors
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/Stone-IT-Cloud/reporting/internal/gitexec"
+)
+
+// TimezoneDistribution counts the commits in range by the UTC offset the author
+// recorded them in, keyed like "+02:00" or "-05:00" ("+00:00" for UTC). It shows where,
+// and therefore when, a distributed team works. It honors StartDate, EndDate,
+// IncludeMergeCommits, ExtraArgs and ExcludeAuthorsMatching from opts; other options
+// are ignored.
+func TimezoneDistribution(repoPath string, opts *Options) (map[string]int, error) {
+	absRepoPath, err := gitexec.ValidateRepoPath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	if err := gitexec.ValidateExtraArgs(opts.ExtraArgs); err != nil {
+		return nil, err
+	}
+	excludedAuthors, err := gitexec.CompileAuthorPatterns(opts.ExcludeAuthorsMatching)
+	if err != nil {
+		return nil, err
+	}
+	logger := opts.logger()
+
+	args := []string{"log", "--encoding=UTF-8", "--pretty=format:%aN%x00%aE%x00%aI"}
+	if opts.StartDate != nil {
+		args = append(args, "--after="+opts.StartDate.Format(time.RFC3339))
+	}
+	if end := opts.endDate(); end != nil {
+		args = append(args, "--before="+end.Format(time.RFC3339))
+	}
+	if !opts.IncludeMergeCommits {
+		args = append(args, "--no-merges")
+	}
+	args = append(args, opts.ExtraArgs...)
+	args = append(args, "--")
+	args = append(args, opts.pathspecs()...)
+
+	distribution := make(map[string]int)
+	sawOutput, stderrStr, err := gitexec.Stream(absRepoPath, args, '\n', func(line string) {
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) != 3 {
+			logger.Warn("skipping malformed git log output line", "line", line)
+			return
+		}
+		if gitexec.AuthorExcluded(excludedAuthors, parts[0], parts[1]) {
+			return
+		}
+		// Parse without converting to UTC: the offset is what is being measured.
+		commitDate, err := time.Parse(time.RFC3339, strings.TrimSpace(parts[2]))
+		if err != nil {
+			logger.Warn("skipping commit with unparseable date", "date", parts[2], "error", err)
+			return
+		}
+		distribution[commitDate.Format("-07:00")]++
+	})
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("git log command failed (path: %q, args: %v): %w", absRepoPath, args, err)
+		}
+		if refErr := unknownRefError(absRepoPath, stderrStr); refErr != nil {
+			return nil, refErr
+		}
+		if strings.Contains(stderrStr, "does not have any commits") ||
+			strings.Contains(stderrStr, "bad default revision 'HEAD'") ||
+			!sawOutput {
+			return distribution, nil
+		}
+		return nil, fmt.Errorf("git log command failed (path: %q, args: %v): %w\nstderr: %s",
+			absRepoPath, args, err, stderrStr)
+	}
+	return distribution, nil
+}
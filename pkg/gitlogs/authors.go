@@ -0,0 +1,61 @@
+package gitlogs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// authorArgs returns one git --author filter per Authors entry; git keeps commits matching
+// any of them. Entries are matched literally and case-insensitively, see authorRegexp.
+func authorArgs(authors []string) ([]string, error) {
+	args := make([]string, 0, len(authors))
+	for _, author := range authors {
+		if strings.TrimSpace(author) == "" {
+			return nil, fmt.Errorf("author filter cannot be empty")
+		}
+		args = append(args, "--author="+authorRegexp(author))
+	}
+	return args, nil
+}
+
+// authorRegexp returns a pattern matching author literally with ASCII letters in either
+// case, written so that it means the same under git's basic, extended and Perl regex
+// syntaxes (grep.patternType): letters become "[aA]", "^" and "\" are backslash-escaped
+// and other punctuation is bracketed ("[.]"). Using it instead of --regexp-ignore-case
+// keeps Grep case-sensitive.
+func authorRegexp(author string) string {
+	var b strings.Builder
+	for _, r := range author {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			fmt.Fprintf(&b, "[%c%c]", unicode.ToLower(r), unicode.ToUpper(r))
+		case r == '^' || r == '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case strings.ContainsRune("!\"#$%&'()*+,./:;<=>?[]`{|}~", r):
+			if r == ']' {
+				b.WriteString("[]]")
+			} else {
+				fmt.Fprintf(&b, "[%c]", r)
+			}
+		default: // Letters outside ASCII, digits, space, "@", "-", "_"
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// excludeAuthorPatterns compiles ExcludeAuthors into case-insensitive literal patterns
+// for authorExcluded. Git has no negated --author, so exclusion happens while parsing.
+func excludeAuthorPatterns(authors []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(authors))
+	for _, author := range authors {
+		if strings.TrimSpace(author) == "" {
+			return nil, fmt.Errorf("excluded author cannot be empty")
+		}
+		compiled = append(compiled, regexp.MustCompile("(?i)"+regexp.QuoteMeta(author)))
+	}
+	return compiled, nil
+}
@@ -0,0 +1,102 @@
+package gitlogs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// cacheFormat is part of every cache key and is bumped whenever the JSON written by
+// GetLogsJSON gains or changes fields (4: pull request authors), or older versions wrote
+// wrong results (3: a merge printed last was dropped), so entries cached by older
+// versions are not reused.
+const cacheFormat = 4
+
+// cacheFilePath returns the cache file for the given repository state and options.
+// It returns "" (disabling the cache for this call) if the repository refs cannot be read.
+func cacheFilePath(absRepoPath string, opts *Options, logger *slog.Logger) string {
+	// show-ref --head lists HEAD and every branch/tag with its hash, so the key
+	// changes whenever any ref scanned by --all moves.
+	cmd := exec.Command("git", "show-ref", "--head")
+	cmd.Dir = absRepoPath
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		logger.Warn("cannot read repository refs, skipping log cache", "error", err)
+		return ""
+	}
+
+	h := sha256.New()
+	h.Write([]byte(absRepoPath))
+	h.Write(stdout.Bytes())
+	fmt.Fprintf(h, "format=%d\n", cacheFormat)
+	fmt.Fprintf(h, "start=%s\n", formatOptionalTime(opts.StartDate))
+	fmt.Fprintf(h, "end=%s\n", formatOptionalTime(opts.endDate()))
+	fmt.Fprintf(h, "grep=%q all-match=%t\n", opts.Grep, opts.GrepAllMatch)
+	fmt.Fprintf(h, "merged-prs-only=%t include-merge-commits=%t\n", opts.MergedPRsOnly, opts.IncludeMergeCommits)
+	fmt.Fprintf(h, "link-pull-requests=%t\n", opts.LinkPullRequests)
+	fmt.Fprintf(h, "order=%s\n", opts.Order)
+	fmt.Fprintf(h, "include-refs=%t\n", opts.IncludeRefs)
+	fmt.Fprintf(h, "extra-args=%q\n", opts.ExtraArgs)
+	fmt.Fprintf(h, "gap-mode=%s\n", opts.GapMode)
+	fmt.Fprintf(h, "not-on-branch=%s\n", opts.NotOnBranch)
+	fmt.Fprintf(h, "paths=%q exclude-paths=%q\n", opts.Paths, opts.ExcludePaths)
+	fmt.Fprintf(h, "exclude-authors-matching=%q\n", opts.ExcludeAuthorsMatching)
+	fmt.Fprintf(h, "authors=%q exclude-authors=%q\n", opts.Authors, opts.ExcludeAuthors)
+	fmt.Fprintf(h, "redact-emails=%t\n", opts.RedactEmails)
+	return filepath.Join(opts.CacheDir, hex.EncodeToString(h.Sum(nil))+".json")
+}
+
+// formatOptionalTime renders t for use in a cache key; nil becomes "-".
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// readCache returns the cached JSON stored at path, if any.
+func readCache(path string) (string, bool) {
+	if path == "" {
+		return "", false
+	}
+	// #nosec G304 -- path is derived from the caller-provided CacheDir and a hex digest.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// writeCache stores data at path atomically. Failures are logged and otherwise ignored,
+// since the cache is only an optimization.
+func writeCache(path string, data []byte, logger *slog.Logger) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		logger.Warn("cannot create log cache directory", "dir", filepath.Dir(path), "error", err)
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".gitlogs-*.tmp")
+	if err != nil {
+		logger.Warn("cannot create log cache file", "error", err)
+		return
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		logger.Warn("cannot write log cache file", "path", tmp.Name(), "error", err)
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		logger.Warn("cannot write log cache file", "path", tmp.Name(), "error", err)
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		logger.Warn("cannot store log cache file", "path", path, "error", err)
+	}
+}
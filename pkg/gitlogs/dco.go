@@ -0,0 +1,98 @@
+package gitlogs
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Reasons a commit fails the Developer Certificate of Origin check.
+const (
+	DCOMissingSignOff  = "missing Signed-off-by trailer"
+	DCOSignOffMismatch = "no Signed-off-by trailer matches the author"
+)
+
+// DCOViolation is a commit that fails the Developer Certificate of Origin check.
+type DCOViolation struct {
+	// Hash is the full commit hash, empty if the entry had none.
+	Hash           string
+	CommitDateTime time.Time
+	AuthorName     string
+	AuthorEmail    string
+	Subject        string
+	Reason         string // DCOMissingSignOff or DCOSignOffMismatch
+	// SignedOffBy lists the identities the commit was signed off by, as written.
+	SignedOffBy []string
+}
+
+// DCOCheck returns the commits in entries that lack a Signed-off-by trailer from their
+// author, in the order given. A sign-off matches when its email equals the author email,
+// ignoring case. Only trailers in the last paragraph of the message count, as git
+// interpret-trailers reads them.
+func DCOCheck(entries []LogEntry) []DCOViolation {
+	var violations []DCOViolation
+	for _, entry := range entries {
+		var signOffs []string
+		signed := false
+		for _, t := range parseTrailers(entry.Message) {
+			if !strings.EqualFold(t.key, "Signed-off-by") {
+				continue
+			}
+			signOffs = append(signOffs, t.value)
+			if addr, err := mail.ParseAddress(t.value); err == nil && strings.EqualFold(addr.Address, entry.AuthorEmail) {
+				signed = true
+			}
+		}
+		if signed {
+			continue
+		}
+		reason := DCOSignOffMismatch
+		if len(signOffs) == 0 {
+			reason = DCOMissingSignOff
+		}
+		subject, _, _ := strings.Cut(entry.Message, "\n")
+		violations = append(violations, DCOViolation{
+			Hash:           entry.Hash,
+			CommitDateTime: entry.CommitDateTime,
+			AuthorName:     entry.AuthorName,
+			AuthorEmail:    entry.AuthorEmail,
+			Subject:        strings.TrimSpace(subject),
+			Reason:         reason,
+			SignedOffBy:    signOffs,
+		})
+	}
+	return violations
+}
+
+// trailer is one "Key: value" line of a commit message trailer block.
+type trailer struct {
+	key, value string
+}
+
+// trailerPattern matches a trailer line; keys are letters, digits and hyphens.
+var trailerPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*):\s*(.*)$`)
+
+// parseTrailers returns the trailers of message: the lines of its last paragraph, if
+// that paragraph is not the subject and every line in it is a trailer or the
+// indented continuation of one.
+func parseTrailers(message string) []trailer {
+	message = strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n"))
+	idx := strings.LastIndex(message, "\n\n")
+	if idx < 0 {
+		return nil // Subject only, or no blank line before the block
+	}
+	var trailers []trailer
+	for _, line := range strings.Split(message[idx+2:], "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(trailers) > 0 {
+			trailers[len(trailers)-1].value += " " + strings.TrimSpace(line)
+			continue
+		}
+		m := trailerPattern.FindStringSubmatch(line)
+		if m == nil {
+			return nil
+		}
+		trailers = append(trailers, trailer{key: m[1], value: strings.TrimSpace(m[2])})
+	}
+	return trailers
+}
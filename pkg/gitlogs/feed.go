@@ -0,0 +1,158 @@
+package gitlogs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Stone-IT-Cloud/reporting/internal/gitexec"
+)
+
+// WatchCommits polls the repository every interval and calls emit with the JSON log entry
+// of each commit that appears on any branch or tag after the call started, oldest first.
+// Entries have the same shape as in GetLogsJSON and honor the same opts (date, path and
+// merge filters, ...); CacheDir is ignored. Commits that were already reachable when
+// watching started, or at the previous poll, are never emitted, so rewinding a branch
+// emits nothing and force-pushed replacements are emitted as new commits.
+//
+// WatchCommits runs until ctx is done, returning ctx.Err(), or until git or emit fails,
+// returning that error.
+func WatchCommits(ctx context.Context, repoPath string, opts *Options, interval time.Duration, emit func(entry json.RawMessage) error) error {
+	absRepoPath, err := gitexec.ValidateRepoPath(repoPath)
+	if err != nil {
+		return err
+	}
+	if interval <= 0 {
+		return fmt.Errorf("poll interval must be positive, got %s", interval)
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	tips, err := refTips(absRepoPath)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		current, err := refTips(absRepoPath)
+		if err != nil {
+			return err
+		}
+		if slices.Equal(current, tips) {
+			continue
+		}
+		if len(current) == 0 {
+			tips = current // Every ref was deleted: nothing new to emit
+			continue
+		}
+		pollOpts := *opts
+		pollOpts.CacheDir = ""
+		// Log from the tips just read rather than all refs, so that a commit made while
+		// this poll runs is left for the next one instead of being emitted twice.
+		pollOpts.revisions = current
+		if len(tips) > 0 {
+			// Everything reachable from the previous tips has been seen already.
+			pollOpts.ExtraArgs = append(append(slices.Clone(opts.ExtraArgs), "--not"), tips...)
+		}
+		logsJSON, err := GetLogsJSON(absRepoPath, &pollOpts)
+		if err != nil {
+			return err
+		}
+		var entries []json.RawMessage
+		if err := json.Unmarshal([]byte(logsJSON), &entries); err != nil {
+			return fmt.Errorf("failed to parse new log entries: %w", err)
+		}
+		for _, entry := range entries {
+			if err := emit(entry); err != nil {
+				return err
+			}
+		}
+		tips = current
+	}
+}
+
+// refTips returns the sorted, distinct commits (or tag objects) pointed to by HEAD and
+// every ref. An empty repository has no tips.
+func refTips(absRepoPath string) ([]string, error) {
+	cmd := exec.Command("git", "show-ref", "--head", "--hash")
+	cmd.Dir = absRepoPath
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stdout.Len() == 0 && stderr.Len() == 0 {
+			return nil, nil // No refs yet
+		}
+		return nil, fmt.Errorf("git show-ref command failed: %w\nstderr: %s", err, stderr.String())
+	}
+	tips := strings.Fields(stdout.String())
+	slices.Sort(tips)
+	return slices.Compact(tips), nil
+}
+
+// FeedHandler returns an HTTP handler that streams WatchCommits to each client: one JSON
+// entry per line (NDJSON) by default, or server-sent events when the request accepts
+// text/event-stream. Each response stream ends when its client disconnects or the
+// request context is canceled; serve it with an http.Server whose BaseContext is canceled
+// on shutdown so that open streams end too. Failures after the stream started are
+// reported through opts' Logger.
+func FeedHandler(repoPath string, opts *Options, interval time.Duration) http.Handler {
+	if opts == nil {
+		opts = &Options{}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := gitexec.ValidateRepoPath(repoPath); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported by this connection", http.StatusInternalServerError)
+			return
+		}
+
+		sse := strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+		if sse {
+			w.Header().Set("Content-Type", "text/event-stream")
+		} else {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+		}
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		err := WatchCommits(r.Context(), repoPath, opts, interval, func(entry json.RawMessage) error {
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, entry); err != nil {
+				return err
+			}
+			var err error
+			if sse {
+				_, err = fmt.Fprintf(w, "event: commit\ndata: %s\n\n", buf.Bytes())
+			} else {
+				_, err = fmt.Fprintf(w, "%s\n", buf.Bytes())
+			}
+			flusher.Flush()
+			return err
+		})
+		if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			opts.logger().Warn("commit feed stopped", "error", err)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package gitlogs
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/Stone-IT-Cloud/reporting/internal/gitexec"
+)
+
+// FileHistory reports when a path was first and last touched and how many commits
+// modified it. It scans all branches, excludes merge commits like GetLogsJSON, and
+// honors the StartDate/EndDate filters of opts. A directory path covers every file
+// beneath it. If no commit touched the path, zero times and a count of 0 are returned.
+func FileHistory(repoPath, path string, opts *Options) (first, last time.Time, commits int, err error) {
+	absRepoPath, err := gitexec.ValidateRepoPath(repoPath)
+	if err != nil {
+		return time.Time{}, time.Time{}, 0, err
+	}
+	if path == "" {
+		return time.Time{}, time.Time{}, 0, fmt.Errorf("path cannot be empty")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	logger := opts.logger()
+
+	args := []string{"log", "--all", "--no-merges", "--pretty=format:%aI"}
+	if opts.StartDate != nil {
+		args = append(args, "--after="+opts.StartDate.Format(time.RFC3339))
+	}
+	if end := opts.endDate(); end != nil {
+		args = append(args, "--before="+end.Format(time.RFC3339))
+	}
+	args = append(args, "--", path)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = absRepoPath
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		stderrStr := stderr.String()
+		if strings.Contains(stderrStr, "does not have any commits") || stdout.Len() == 0 {
+			return time.Time{}, time.Time{}, 0, nil
+		}
+		return time.Time{}, time.Time{}, 0, fmt.Errorf("git log command failed: %w\nstderr: %s", err, stderrStr)
+	}
+
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		commitDate, err := time.Parse(time.RFC3339, line)
+		if err != nil {
+			logger.Warn("skipping commit with unparseable date", "date", line, "error", err)
+			continue
+		}
+		commitDate = commitDate.UTC()
+		commits++
+		if first.IsZero() || commitDate.Before(first) {
+			first = commitDate
+		}
+		if last.IsZero() || commitDate.After(last) {
+			last = commitDate
+		}
+	}
+	return first, last, commits, nil
+}
@@ -3,13 +3,40 @@ package gitlogs // <-- Nuevo paquete
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"os/exec"
-	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/Stone-IT-Cloud/reporting/internal/gitexec"
+)
+
+// Order controls the sequence of entries in the generated log.
+type Order string
+
+const (
+	// OrderChronological lists commits oldest-first. It is the default.
+	OrderChronological Order = "chronological"
+	// OrderReverseChronological lists commits newest-first, as in a changelog.
+	OrderReverseChronological Order = "reverse-chronological"
+)
+
+// GapMode selects how the time since the previous commit is measured.
+type GapMode string
+
+const (
+	// GapModeGlobal measures the gap to the previous commit by anyone.
+	GapModeGlobal GapMode = "global"
+	// GapModePerAuthor measures the gap to the same author's previous commit
+	// (authors are matched by email, ignoring case).
+	GapModePerAuthor GapMode = "per-author"
 )
 
 // Options defines the filtering options for retrieving git logs.
@@ -20,207 +47,564 @@ type Options struct {
 	// EndDate filters commits to include only those made on or before this date/time (inclusive).
 	// If nil, no end date filter is applied.
 	EndDate *time.Time
+	// InclusiveEndDate treats an EndDate without a time component (midnight in Location)
+	// as a whole day, extending it to the last instant of that day. Without it such an
+	// EndDate excludes everything after midnight, i.e. the whole day.
+	InclusiveEndDate bool
+	// Location is the timezone used by InclusiveEndDate to decide whether EndDate is a bare
+	// date and where its day ends. If nil, EndDate's own location is used.
+	Location *time.Location
+	// Grep limits the log to commits whose message matches any of the given patterns.
+	// Each pattern is passed to git as --grep=<pattern>, so filtering happens inside git
+	// and uses git's POSIX basic regular expressions, not Go's regexp syntax.
+	Grep []string
+	// GrepAllMatch requires a commit message to match all Grep patterns instead of any.
+	GrepAllMatch bool
+	// CacheDir enables an on-disk cache of the generated JSON when non-empty. Entries are
+	// keyed by the repository refs (HEAD and all branches/tags) plus the filtering options,
+	// so any new commit or ref update invalidates them automatically.
+	CacheDir string
+	// MergedPRsOnly switches the log to merge commits created by GitHub's "Merge pull request"
+	// button and enriches each entry with the pull request number, source branch and title
+	// parsed from the merge message. Files are listed relative to the first parent, i.e. the
+	// changes the pull request brought in. Squash and rebase merges create no merge commit
+	// and therefore cannot be detected in this mode.
+	MergedPRsOnly bool
+	// IncludeMergeCommits keeps merge commits, which are skipped by default, so their
+	// messages (e.g. pull request titles) reach the report. Merge commits list no
+	// modified_files: their changes are already listed on the merged commits. Ignored
+	// with MergedPRsOnly.
+	IncludeMergeCommits bool
+	// LinkPullRequests fills each entry's pull_request_number with the GitHub pull request
+	// that brought the commit in, as found by LinkCommitsToPullRequests, so that work can be
+	// grouped by pull request. Commits not linked to a pull request have no number.
+	LinkPullRequests bool
+	// Order selects oldest-first (OrderChronological, the default when empty) or
+	// newest-first (OrderReverseChronological) output. Any other value is an error.
+	Order Order
+	// IncludeRefs records, for each commit, the branches and tags pointing at it
+	// (git's %D decoration) in the entry's refs field, e.g. to mark releases.
+	IncludeRefs bool
+	// ExtraArgs are passed verbatim to the git log command, just before the final "--",
+	// as an escape hatch for flags not covered by these options. They can conflict with
+	// the flags set internally (e.g. --reverse or --no-merges) and change the output in
+	// unexpected ways; use with care. Arguments that change the output format, add diff
+	// output or redirect it (--pretty, --format, -z, --name-status, --stat, -p, --graph,
+	// --output and the like) are rejected.
+	ExtraArgs []string
+	// GapMode, when set, fills each entry's time_since_previous_ns with the time elapsed
+	// since the previous commit in the log (GapModeGlobal) or since the same author's
+	// previous commit (GapModePerAuthor). Only commits in the output are considered, and
+	// the first commit of each sequence has no gap. Empty disables the metric.
+	GapMode GapMode
+	// NotOnBranch, when set, replaces the scan of all branches with the range
+	// <NotOnBranch>..HEAD: only commits reachable from the checked-out branch that are not
+	// on NotOnBranch, i.e. what a pull request from the current branch would add.
+	NotOnBranch string
+	// Paths limits the log to commits touching at least one of these paths (git pathspecs,
+	// e.g. "web/" or "*.go"), and lists only the matching files in modified_files.
+	Paths []string
+	// ExcludePaths drops these paths from consideration: commits touching only excluded
+	// paths are skipped and excluded files are not listed. Combines with Paths.
+	ExcludePaths []string
+	// ExcludeAuthorsMatching drops commits whose author email or name matches any of these
+	// regular expressions (Go syntax, unanchored), e.g. `@mycompany\.com$` to keep only
+	// outside contributions. Prefix a pattern with (?i) to ignore case. Invalid patterns
+	// are an error.
+	ExcludeAuthorsMatching []string
+	// Authors limits the log to commits whose author name or email contains any of these
+	// values, ignoring case (ASCII letters only), e.g. "alice@example.com" or "Alice".
+	// Values are matched literally, not as regular expressions. Passed to git as --author.
+	Authors []string
+	// ExcludeAuthors drops commits whose author name or email contains any of these values,
+	// ignoring case, e.g. "dependabot[bot]". Values are matched literally.
+	ExcludeAuthors []string
+	// RedactEmails masks author_email and any email address in commit messages (e.g.
+	// Signed-off-by trailers) with RedactEmail, so that reports can be published or sent
+	// to third-party services without exposing addresses.
+	RedactEmails bool
+	// Logger receives structured diagnostics about skipped commits and malformed output.
+	// If nil, warnings are written to stderr using a text handler.
+	Logger *slog.Logger
+	// Now returns the current time for timestamps taken alongside the log, such as the
+	// generation time of a reporting.ExportBundle. If nil, time.Now is used. Tests can
+	// inject a fixed clock here.
+	Now func() time.Time
+
+	// revisions, when set, replaces the scan of all branches, e.g. with the ref tips
+	// WatchCommits read, so that refs moving during the call are not seen. NotOnBranch
+	// takes precedence.
+	revisions []string
 }
 
-// logEntry represents the structured data for a single commit before JSON marshalling.
-// JSON tags define the output field names.
-type logEntry struct {
+// logger returns the configured logger or a stderr text logger when none is set.
+func (o *Options) logger() *slog.Logger {
+	if o.Logger != nil {
+		return o.Logger
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, nil))
+}
+
+// pathspecs returns the git pathspecs for Paths and ExcludePaths, or nil for no filter.
+func (o *Options) pathspecs() []string {
+	return gitexec.Pathspecs(o.Paths, o.ExcludePaths)
+}
+
+// endDate returns EndDate, extended to the last instant of its day when
+// InclusiveEndDate is set and EndDate is a bare date (midnight in Location).
+func (o *Options) endDate() *time.Time {
+	if o.EndDate == nil || !o.InclusiveEndDate {
+		return o.EndDate
+	}
+	loc := o.Location
+	if loc == nil {
+		loc = o.EndDate.Location()
+	}
+	local := o.EndDate.In(loc)
+	if local.Hour() != 0 || local.Minute() != 0 || local.Second() != 0 || local.Nanosecond() != 0 {
+		return o.EndDate // Has a time component; already precise
+	}
+	endOfDay := local.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	return &endOfDay
+}
+
+// LogEntry is a single commit as written by GetLogsJSON; JSON tags define the output
+// field names, so the JSON array can be unmarshalled into a []LogEntry.
+type LogEntry struct {
+	Hash           string    `json:"commit_hash"` // Full commit hash
+	ShortHash      string    `json:"short_hash"`  // Abbreviated as by git log's %h, unique in the repository
 	CommitDateTime time.Time `json:"commit_date_time"`
 	AuthorName     string    `json:"author_name"`
 	AuthorEmail    string    `json:"author_email"`
 	Message        string    `json:"commit_message"`
 	ModifiedFiles  []string  `json:"modified_files"`
-	// Internal fields not included in JSON can be added without tags
-	// Hash string `json:"-"`
+	PullRequest    *MergedPR `json:"pull_request,omitempty"`
+	Refs           []string  `json:"refs,omitempty"` // Set only with Options.IncludeRefs
+	// PullRequestNumber is set only with Options.LinkPullRequests.
+	PullRequestNumber int `json:"pull_request_number,omitempty"`
+	// TimeSincePrevious is set only with Options.GapMode; serialized in nanoseconds.
+	TimeSincePrevious time.Duration `json:"time_since_previous_ns,omitempty"`
+	// Internal fields not included in JSON
+	merge bool // Whether the commit has more than one parent
+}
+
+// MergedPR holds the pull request details parsed from a GitHub merge commit message.
+type MergedPR struct {
+	Number       int    `json:"number"`
+	SourceBranch string `json:"source_branch"`
+	Title        string `json:"title"`
+	// AuthorName and AuthorEmail identify who wrote the pull request: the author of the
+	// merged branch tip, or of the merge commit when the tip cannot be read (e.g. in a
+	// shallow clone). The merge commit's own author is who merged it.
+	AuthorName  string `json:"author_name"`
+	AuthorEmail string `json:"author_email"`
+	// Internal fields not included in JSON
+	head string // Second parent of the merge commit: the tip of the merged branch
+}
+
+// mergePRPattern matches the subject GitHub writes for merge-commit pull request merges.
+var mergePRPattern = regexp.MustCompile(`^Merge pull request #(\d+) from (\S+)`)
+
+// parseMergedPR extracts pull request details from a merge commit message.
+// It returns nil if the message is not a GitHub pull request merge.
+func parseMergedPR(message string) *MergedPR {
+	subject, body, _ := strings.Cut(message, "\n")
+	m := mergePRPattern.FindStringSubmatch(subject)
+	if m == nil {
+		return nil
+	}
+	number, err := strconv.Atoi(m[1])
+	if err != nil {
+		return nil
+	}
+	return &MergedPR{Number: number, SourceBranch: m[2], Title: strings.TrimSpace(body)}
+}
+
+// setPullRequestAuthors sets the author of each entry's pull request to the author of the
+// merged branch tip, with a single git call for all of them. Entries keep the merge
+// commit's author when the tips cannot be read.
+func setPullRequestAuthors(absRepoPath string, entries []LogEntry, redact bool, logger *slog.Logger) {
+	heads := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.PullRequest != nil && entry.PullRequest.head != "" {
+			heads = append(heads, entry.PullRequest.head)
+		}
+	}
+	if len(heads) == 0 {
+		return
+	}
+
+	type author struct{ name, email string }
+	authors := make(map[string]author, len(heads))
+	args := append([]string{"log", "--no-walk", "--encoding=UTF-8", "--format=%H%x00%aN%x00%aE"}, heads...)
+	_, stderrStr, err := gitexec.Stream(absRepoPath, args, '\n', func(line string) {
+		if parts := strings.SplitN(line, "\x00", 3); len(parts) == 3 {
+			authors[parts[0]] = author{name: parts[1], email: parts[2]}
+		}
+	})
+	if err != nil {
+		logger.Warn("could not read the merged branch tips; pull request authors are the merge commit authors",
+			"error", err, "stderr", stderrStr)
+		return
+	}
+	for i := range entries {
+		pr := entries[i].PullRequest
+		if pr == nil {
+			continue
+		}
+		if a, ok := authors[pr.head]; ok {
+			pr.AuthorName, pr.AuthorEmail = a.name, a.email
+			if redact {
+				pr.AuthorEmail = RedactEmail(pr.AuthorEmail)
+			}
+		}
+	}
+}
+
+// parseDecoration turns a %D decoration such as "HEAD -> main, tag: v1.0, origin/main"
+// into clean ref names ("main", "v1.0", "origin/main"). A detached bare HEAD is dropped.
+func parseDecoration(decoration string) []string {
+	var refs []string
+	for _, ref := range strings.Split(decoration, ", ") {
+		ref = strings.TrimSpace(ref)
+		ref = strings.TrimPrefix(ref, "HEAD -> ")
+		ref = strings.TrimPrefix(ref, "tag: ")
+		if ref == "" || ref == "HEAD" {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs
 }
 
 // GetLogsJSON retrieves git commit logs from a repository based on options,
-// excluding merge commits, scanning all branches, ordering chronologically,
-// and returns the result as a JSON string.
-// Uses a two-pass approach: first gets commit details, then gets files per commit.
+// excluding merge commits (unless IncludeMergeCommits is set), scanning all branches (or only the commits missing from
+// Options.NotOnBranch), ordering chronologically (or newest-first with
+// OrderReverseChronological), and returns the result as a JSON string. Commits with
+// identical timestamps are ordered by commit hash so the output is reproducible.
+// Commit details and modified files come from a single git log, parsed as it streams.
 func GetLogsJSON(repoPath string, opts *Options) (string, error) {
+	var b strings.Builder
+	if err := writeLogsJSON(repoPath, opts, &b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// writeLogsJSON implements GetLogsJSON, writing the JSON to w entry by entry rather than
+// building it in memory. Nothing is written to w unless the logs were read successfully.
+// With Options.CacheDir, a copy of the JSON is kept in memory for the cache.
+func writeLogsJSON(repoPath string, opts *Options, w io.Writer) error {
 	// --- Input Validation & Path Setup ---
-	absRepoPath, err := validateRepoPath(repoPath)
+	absRepoPath, err := gitexec.ValidateRepoPath(repoPath)
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	// --- Prepare Options ---
 	if opts == nil {
 		opts = &Options{}
 	}
+	logger := opts.logger()
+	if opts.Order != "" && opts.Order != OrderChronological && opts.Order != OrderReverseChronological {
+		return fmt.Errorf("invalid order %q: must be %q or %q", opts.Order, OrderChronological, OrderReverseChronological)
+	}
+	if err := gitexec.ValidateExtraArgs(opts.ExtraArgs); err != nil {
+		return err
+	}
+	if strings.HasPrefix(opts.NotOnBranch, "-") {
+		return fmt.Errorf("invalid branch name %q", opts.NotOnBranch)
+	}
+	if opts.GapMode != "" && opts.GapMode != GapModeGlobal && opts.GapMode != GapModePerAuthor {
+		return fmt.Errorf("invalid gap mode %q: must be %q or %q", opts.GapMode, GapModeGlobal, GapModePerAuthor)
+	}
+	excludedAuthors, err := gitexec.CompileAuthorPatterns(opts.ExcludeAuthorsMatching)
+	if err != nil {
+		return err
+	}
+	excludedLiterals, err := excludeAuthorPatterns(opts.ExcludeAuthors)
+	if err != nil {
+		return err
+	}
+	excludedAuthors = append(excludedAuthors, excludedLiterals...)
+	authorFilters, err := authorArgs(opts.Authors)
+	if err != nil {
+		return err
+	}
 
-	// --- Pass 1: Get Commit Details (Hash, Author, Date, Message) ---
-	const separator = "|||GITLOGSEP|||"
-	const logFormat = "%H" + separator + "%aN" + separator + "%aE" + separator + "%aI" + separator + "%B%x00" // Null byte terminates each entry
-	const endOfCommitMarker = "\x00"
+	// --- Disk Cache Lookup ---
+	var cachePath string
+	if opts.CacheDir != "" {
+		cachePath = cacheFilePath(absRepoPath, opts, logger)
+		if cached, ok := readCache(cachePath); ok {
+			_, err := io.WriteString(w, cached)
+			return err
+		}
+	}
+
+	// --- Get Commit Details and Modified Files in a Single Streamed git log ---
+	// Every commit starts with a NUL and each of its fields is NUL-terminated. Git does
+	// not allow NUL bytes in names, emails, ref names or commit messages, so no field
+	// content can be mistaken for a separator. The --name-only file list follows the
+	// message, one quoted name per line, and runs until the next commit's leading NUL.
+	const logFormat = "%x00%H%x00%h%x00%P%x00%aN%x00%aE%x00%aI%x00%D%x00%B%x00"
+	const fieldsPerCommit = 8 // Hash, Short hash, Parents, Name, Email, Date, Refs, Message
 
-	logArgs := []string{
-		"log",
-		"--all",
-		"--no-merges",
-		"--reverse",
-		"--pretty=format:" + logFormat,
+	mergeFilter := "--no-merges"
+	switch {
+	case opts.MergedPRsOnly:
+		mergeFilter = "--merges"
+	case opts.IncludeMergeCommits:
+		mergeFilter = "--no-min-parents" // Any number of parents: the default, spelled out
+	}
+	revisions := []string{"--all"}
+	switch {
+	case opts.NotOnBranch != "":
+		revisions = []string{opts.NotOnBranch + "..HEAD"}
+	case len(opts.revisions) > 0:
+		revisions = opts.revisions
+	}
+	logArgs := append([]string{"log"}, revisions...)
+	logArgs = append(logArgs,
+		mergeFilter,
+		"--encoding=UTF-8", // Re-encode messages recorded with a legacy i18n.commitEncoding
+		"--pretty=format:"+logFormat,
+		"--name-only",
+	)
+	if opts.MergedPRsOnly {
+		// Merge commits show no files unless diffed against a parent.
+		logArgs = append(logArgs, "--diff-merges=first-parent")
+	}
+	if opts.Order != OrderReverseChronological {
+		logArgs = append(logArgs, "--reverse")
 	}
 	if opts.StartDate != nil {
 		logArgs = append(logArgs, "--after="+opts.StartDate.Format(time.RFC3339))
 	}
-	if opts.EndDate != nil {
-		logArgs = append(logArgs, "--before="+opts.EndDate.Format(time.RFC3339))
+	if end := opts.endDate(); end != nil {
+		logArgs = append(logArgs, "--before="+end.Format(time.RFC3339))
 	}
-	logArgs = append(logArgs, "--")
-
-	cmdLog := exec.Command("git", logArgs...)
-	cmdLog.Dir = absRepoPath
-	var stdoutLog, stderrLog bytes.Buffer
-	cmdLog.Stdout = &stdoutLog
-	cmdLog.Stderr = &stderrLog
-
-	if err := cmdLog.Run(); err != nil {
-		stderrStr := stderrLog.String()
-		if strings.Contains(stderrStr, "does not have any commits") || strings.Contains(stderrStr, "bad default revision 'HEAD'") || stdoutLog.Len() == 0 {
-			return "[]", nil // Empty repo or no matching commits
-		}
-		return "", fmt.Errorf("git log command failed: %w\nstderr: %s", err, stderrStr)
+	for _, pattern := range opts.Grep {
+		logArgs = append(logArgs, "--grep="+pattern)
 	}
-
-	// --- Parse Commit Details Output ---
-	outputLog := strings.TrimSpace(stdoutLog.String())
-	if outputLog == "" {
-		return "[]", nil // No commits found after filtering
+	if opts.GrepAllMatch && len(opts.Grep) > 0 {
+		logArgs = append(logArgs, "--all-match")
 	}
+	logArgs = append(logArgs, authorFilters...)
+	logArgs = append(logArgs, opts.ExtraArgs...)
+	logArgs = append(logArgs, "--")
+	logArgs = append(logArgs, opts.pathspecs()...)
 
-	commitDetailBlocks := strings.Split(outputLog, endOfCommitMarker)
-	logEntriesMap := make(map[string]*logEntry) // Use map for easy lookup by hash
-	commitOrder := []string{}                   // Preserve chronological order
-
-	for _, block := range commitDetailBlocks {
-		trimmedBlock := strings.TrimSpace(block)
-		if trimmedBlock == "" {
-			continue
-		}
-
-		parts := strings.SplitN(trimmedBlock, separator, 5) // Hash, Name, Email, Date, Message
-		if len(parts) != 5 {
-			fmt.Fprintf(os.Stderr, "warning: skipping malformed git log detail line: %q\n", trimmedBlock)
-			continue
+	// parseEntry builds the entry for the fields of one commit, or returns nil for
+	// commits that are filtered out.
+	parseEntry := func(parts []string) *LogEntry {
+		hash := strings.TrimSpace(parts[0])
+		shortHash := strings.TrimSpace(parts[1])
+		parents := strings.Fields(parts[2])
+		authorName := parts[3]
+		authorEmail := parts[4]
+		dateStr := parts[5]
+		decoration := parts[6]
+		message := parts[7]
+		if gitexec.AuthorExcluded(excludedAuthors, authorName, authorEmail) {
+			return nil
 		}
 
-		hash := parts[0]
-		authorName := parts[1]
-		authorEmail := parts[2]
-		dateStr := parts[3]
-		message := parts[4]
-
 		commitDate, err := time.Parse(time.RFC3339, dateStr)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "warning: skipping commit %s with unparseable date %q: %v\n", hash, dateStr, err)
-			continue
+			logger.Warn("skipping commit with unparseable date", "hash", hash, "date", dateStr, "error", err)
+			return nil
 		}
 
-		entry := &logEntry{ // Store as pointer in map
+		entry := &LogEntry{
+			Hash:           hash,
+			ShortHash:      shortHash,
+			merge:          len(parents) > 1,
 			CommitDateTime: commitDate.UTC(),
 			AuthorName:     authorName,
 			AuthorEmail:    authorEmail,
 			Message:        strings.TrimSpace(message),
-			ModifiedFiles:  make([]string, 0), // Initialize empty slice, files added in pass 2
-		}
-		logEntriesMap[hash] = entry
-		commitOrder = append(commitOrder, hash) // Add hash to maintain order
-	}
-
-	// --- Pass 2: Get Modified Files for Each Commit ---
-	finalLogEntries := make([]logEntry, 0, len(commitOrder))
-	for _, hash := range commitOrder {
-		showArgs := []string{
-			"show",
-			hash,          // Specify the commit hash
-			"--pretty=",   // No commit header info needed
-			"--name-only", // Only show names of modified files
-			"--no-merges", // Ensure consistency
-			// REMOVED: "--oneline",   // Avoid showing diffstat or other noise <-- This was incorrect for show --name-only
-			"--",
-		}
-		cmdShow := exec.Command("git", showArgs...) // #nosec G204
-		cmdShow.Dir = absRepoPath
-		var stdoutShow, stderrShow bytes.Buffer
-		cmdShow.Stdout = &stdoutShow
-		cmdShow.Stderr = &stderrShow
-
-		if err := cmdShow.Run(); err != nil {
-			fmt.Fprintf(os.Stderr, "warning: git show for commit %s failed: %v\nstderr: %s\n", hash, err, stderrShow.String())
-			continue // Skip this commit entirely if show fails
-		}
-
-		// Parse file list output
-		fileListStr := strings.TrimSpace(stdoutShow.String())
-		modifiedFiles := make([]string, 0)
-		if fileListStr != "" {
-			files := strings.Split(fileListStr, "\n")
-			for _, f := range files {
-				trimmedFile := strings.TrimSpace(f)
-				if trimmedFile != "" {
-					modifiedFiles = append(modifiedFiles, trimmedFile)
-				}
+			ModifiedFiles:  make([]string, 0), // Filled from the file list that follows
+		}
+		if opts.RedactEmails {
+			entry.AuthorEmail = RedactEmail(entry.AuthorEmail)
+			entry.Message = RedactEmailsIn(entry.Message)
+		}
+		if opts.IncludeRefs {
+			entry.Refs = parseDecoration(decoration)
+		}
+		if opts.MergedPRsOnly {
+			entry.PullRequest = parseMergedPR(entry.Message)
+			if entry.PullRequest == nil {
+				return nil // A merge, but not one created from a GitHub pull request
+			}
+			entry.PullRequest.AuthorName, entry.PullRequest.AuthorEmail = entry.AuthorName, entry.AuthorEmail
+			if len(parents) > 1 {
+				entry.PullRequest.head = parents[1]
 			}
 		}
+		return entry
+	}
 
-		// --- ★★★ Filter out commits with no modified files ★★★ ---
-		// This effectively skips the initial empty commit created by test setup.
-		if len(modifiedFiles) == 0 {
-			continue // Skip adding this commit to the final list
+	// --- Parse the Output (streamed, NUL-delimited) ---
+	finalLogEntries := make([]LogEntry, 0)
+	var pending *LogEntry // Last commit read, waiting for its file list
+	addFiles := func(fileList string) {
+		entry := pending
+		pending = nil
+		if entry == nil {
+			return
 		}
-
-		// If we have files, retrieve the original entry and add the files
-		if entry, ok := logEntriesMap[hash]; ok {
-			entry.ModifiedFiles = modifiedFiles // Assign the parsed files
-			// Optional: Sort files here if needed
-			sort.Strings(entry.ModifiedFiles)
-			finalLogEntries = append(finalLogEntries, *entry) // Append the completed entry
-		} else {
-			// This case should ideally not happen if the hash came from commitOrder
-			fmt.Fprintf(os.Stderr, "warning: commit hash %s found in show but not in initial log map\n", hash)
+		for _, f := range strings.Split(fileList, "\n") {
+			if trimmedFile := strings.TrimSpace(f); trimmedFile != "" {
+				entry.ModifiedFiles = append(entry.ModifiedFiles, trimmedFile)
+			}
 		}
+		// Skip commits with no modified files, such as empty commits or commits that
+		// only touch paths outside the pathspecs. Merge commits list no files unless
+		// MergedPRsOnly diffs them, so they are kept when explicitly included.
+		if len(entry.ModifiedFiles) == 0 && !(opts.IncludeMergeCommits && entry.merge) {
+			return
+		}
+		sort.Strings(entry.ModifiedFiles)
+		finalLogEntries = append(finalLogEntries, *entry)
 	}
 
-	// --- Assemble Final Ordered List (Now done within Pass 2) ---
-	// The finalLogEntries slice is already built in the correct order.
-
-	// --- Marshal to JSON ---
-	jsonData, err := json.MarshalIndent(finalLogEntries, "", "  ")
+	var parts []string // Fields of the commit being read; nil while reading a file list
+	sawOutput, stderrStr, err := gitexec.Stream(absRepoPath, logArgs, 0, func(token string) {
+		if parts == nil {
+			// The file list of the previous commit, or the empty output before the first.
+			addFiles(token)
+			parts = make([]string, 0, fieldsPerCommit)
+			return
+		}
+		parts = append(parts, token)
+		if len(parts) < fieldsPerCommit {
+			return
+		}
+		pending = parseEntry(parts)
+		parts = nil
+	})
+	if parts == nil {
+		addFiles("") // The last commit printed had no file list, e.g. a merge
+	}
+	if len(parts) > 0 {
+		logger.Warn("skipping truncated git log entry", "fields", parts)
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal log entries to JSON: %w", err)
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return fmt.Errorf("git log command failed: %w", err)
+		}
+		if refErr := unknownRefError(absRepoPath, stderrStr); refErr != nil {
+			return refErr
+		}
+		if strings.Contains(stderrStr, "does not have any commits") || strings.Contains(stderrStr, "bad default revision 'HEAD'") || !sawOutput {
+			_, err := io.WriteString(w, "[]") // Empty repo or no matching commits
+			return err
+		}
+		return fmt.Errorf("git log command failed: %w\nstderr: %s", err, stderrStr)
+	}
+	if len(finalLogEntries) == 0 && !sawOutput {
+		_, err := io.WriteString(w, "[]") // No commits found after filtering
+		return err
+	}
+	if opts.MergedPRsOnly {
+		setPullRequestAuthors(absRepoPath, finalLogEntries, opts.RedactEmails, logger)
+	}
+	if opts.LinkPullRequests {
+		links, err := pullRequestLinks(absRepoPath, opts)
+		if err != nil {
+			return fmt.Errorf("failed to link commits to pull requests: %w", err)
+		}
+		for i := range finalLogEntries {
+			finalLogEntries[i].PullRequestNumber = links[finalLogEntries[i].Hash]
+		}
+	}
+
+	// --- Assemble Final Ordered List ---
+	// git orders commits sharing a timestamp arbitrarily, so sort explicitly by
+	// date with the commit hash as tie-breaker to make the output reproducible.
+	sortLogEntries(finalLogEntries, opts.Order == OrderReverseChronological)
+	if opts.GapMode != "" {
+		setTimeSincePrevious(finalLogEntries, opts.GapMode, opts.Order == OrderReverseChronological)
 	}
 
-	return string(jsonData), nil
+	// --- Write JSON ---
+	out := w
+	var cacheData bytes.Buffer
+	if cachePath != "" {
+		out = io.MultiWriter(w, &cacheData)
+	}
+	if err := encodeLogEntries(out, finalLogEntries); err != nil {
+		return fmt.Errorf("failed to write log entries as JSON: %w", err)
+	}
+	if cachePath != "" {
+		writeCache(cachePath, cacheData.Bytes(), logger)
+	}
+	return nil
 }
 
-// validateRepoPath checks if the path is valid and returns the absolute path.
-// Duplicated here for simplicity, could be moved to shared internal package.
-func validateRepoPath(repoPath string) (string, error) {
-	if repoPath == "" {
-		return "", fmt.Errorf("repository path cannot be empty")
+// encodeLogEntries writes entries to w one at a time, byte for byte as
+// json.MarshalIndent(entries, "", "  ") would return them.
+func encodeLogEntries(w io.Writer, entries []LogEntry) error {
+	if len(entries) == 0 {
+		_, err := io.WriteString(w, "[]")
+		return err
 	}
-	absRepoPath, err := filepath.Abs(repoPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to get absolute path for %q: %w", repoPath, err)
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
 	}
-	info, err := os.Stat(absRepoPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return "", fmt.Errorf("repository path %q does not exist", absRepoPath)
+	for i := range entries {
+		data, err := json.MarshalIndent(&entries[i], "  ", "  ")
+		if err != nil {
+			return err
+		}
+		separator := ",\n  "
+		if i == 0 {
+			separator = "\n  "
+		}
+		if _, err := io.WriteString(w, separator); err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
 		}
-		return "", fmt.Errorf("failed to stat repository path %q: %w", absRepoPath, err)
-	}
-	if !info.IsDir() {
-		return "", fmt.Errorf("repository path %q is not a directory", absRepoPath)
 	}
-	gitDirPath := filepath.Join(absRepoPath, ".git")
-	if _, err := os.Stat(gitDirPath); err != nil {
-		if os.IsNotExist(err) {
-			return "", fmt.Errorf("path %q is not a git repository (missing .git directory)", absRepoPath)
+	_, err := io.WriteString(w, "\n]")
+	return err
+}
+
+// sortLogEntries orders entries by commit date, oldest first (newest first when
+// reverse is set), breaking ties by commit hash so that runs are deterministic.
+func sortLogEntries(entries []LogEntry, reverse bool) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if reverse {
+			a, b = b, a
+		}
+		if !a.CommitDateTime.Equal(b.CommitDateTime) {
+			return a.CommitDateTime.Before(b.CommitDateTime)
+		}
+		return a.Hash < b.Hash
+	})
+}
+
+// setTimeSincePrevious fills TimeSincePrevious on entries already sorted by sortLogEntries.
+func setTimeSincePrevious(entries []LogEntry, mode GapMode, reverse bool) {
+	previous := make(map[string]time.Time) // Last commit time per sequence key
+	for k := range entries {
+		i := k
+		if reverse {
+			i = len(entries) - 1 - k // Walk oldest-first regardless of output order
+		}
+		key := ""
+		if mode == GapModePerAuthor {
+			key = strings.ToLower(entries[i].AuthorEmail)
+		}
+		if last, ok := previous[key]; ok {
+			entries[i].TimeSincePrevious = entries[i].CommitDateTime.Sub(last)
 		}
-		return "", fmt.Errorf("failed to stat .git directory in %q: %w", absRepoPath, err)
+		previous[key] = entries[i].CommitDateTime
 	}
-	return absRepoPath, nil
 }
@@ -1,14 +1,24 @@
 package gitlogs_test
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -29,8 +39,10 @@ const (
 )
 
 // expectedLogEntry defines the structure we expect after unmarshalling the JSON result.
-// Used for comparison in tests. Field names match JSON tags in gitlogs.logEntry.
+// Used for comparison in tests. Field names match JSON tags in gitlogs.LogEntry.
 type expectedLogEntry struct {
+	CommitHash     string   `json:"commit_hash"` // Differs per run; checked for shape, then cleared
+	ShortHash      string   `json:"short_hash"`
 	CommitDateTime string   `json:"commit_date_time"` // Compare as RFC3339 string
 	AuthorName     string   `json:"author_name"`
 	AuthorEmail    string   `json:"author_email"`
@@ -40,7 +52,7 @@ type expectedLogEntry struct {
 
 // --- Test Helpers (Idénticos a los de contributors_test) ---
 
-func setupGitRepo(t *testing.T) string {
+func setupGitRepo(t testing.TB) string {
 	t.Helper()
 	repoPath := t.TempDir()
 	runGitCommand(t, repoPath, "init", "-b", "main")
@@ -51,7 +63,7 @@ func setupGitRepo(t *testing.T) string {
 	return repoPath
 }
 
-func runGitCommand(t *testing.T, dir string, args ...string) {
+func runGitCommand(t testing.TB, dir string, args ...string) {
 	t.Helper()
 	cmd := exec.Command("git", args...)
 	cmd.Dir = dir
@@ -88,8 +100,10 @@ func gitCommit(t *testing.T, repoPath, message, authorName, authorEmail string,
 	}
 
 	// Use environment variables to set author and date precisely
-	cmd := exec.Command("git", "commit", "-m", message)
+	// Pass the message on stdin: command-line arguments are too small for very long messages
+	cmd := exec.Command("git", "commit", "-F", "-")
 	cmd.Dir = repoPath
+	cmd.Stdin = strings.NewReader(message)
 	isoDate := commitDate.Format(time.RFC3339) // Git log %aI format matches RFC3339
 
 	cmd.Env = append(os.Environ(),
@@ -132,6 +146,34 @@ func sortFiles(data []expectedLogEntry) {
 	}
 }
 
+// setupMergeRepo commits C1 on main, C2 on a feat branch, merges feat into main with
+// a merge commit (C3, by the merger) and commits C4 on main.
+func setupMergeRepo(t *testing.T, repoPath string) {
+	t.Helper()
+	// main: C1(A)
+	// branch feat: C2(B)
+	// main: Merge feat -> C3(Merger)
+	// main: C4(A)
+	gitCommit(t, repoPath, "C1 main", author1Name, author1Email, testTime(2023, 4, 1, 10, 0, 0), map[string]string{"main.txt": "m1"}) // C1
+	runGitCommand(t, repoPath, "checkout", "-b", "feat")
+	gitCommit(t, repoPath, "C2 feat", author2Name, author2Email, testTime(2023, 4, 2, 11, 0, 0), map[string]string{"feat.txt": "f1"}) // C2
+	runGitCommand(t, repoPath, "checkout", "main")
+	// Create merge commit explicitly setting committer/author
+	mergeDate := testTime(2023, 4, 3, 12, 0, 0)
+	cmd := exec.Command("git", "merge", "--no-ff", "-m", "Merge branch 'feat'", "feat")
+	cmd.Dir = repoPath
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+mergerName, "GIT_AUTHOR_EMAIL="+mergerEmail, "GIT_AUTHOR_DATE="+mergeDate.Format(time.RFC3339),
+		"GIT_COMMITTER_NAME="+mergerName, "GIT_COMMITTER_EMAIL="+mergerEmail, "GIT_COMMITTER_DATE="+mergeDate.Format(time.RFC3339),
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git merge failed: %v\nOutput: %s", err, string(output))
+	}
+	// Commit after merge
+	gitCommit(t, repoPath, "C4 main", author1Name, author1Email, testTime(2023, 4, 4, 13, 0, 0), map[string]string{"main.txt": "m2"}) // C4
+}
+
 // --- Test Cases ---
 
 func TestGetLogsJSON(t *testing.T) {
@@ -248,32 +290,9 @@ func TestGetLogsJSON(t *testing.T) {
 			expectedError: false,
 		},
 		{
-			name: "Success: Merge commit excluded",
-			setupRepo: func(t *testing.T, repoPath string) {
-				// main: C1(A)
-				// branch feat: C2(B)
-				// main: Merge feat -> C3(Merger) - Should be excluded
-				// main: C4(A) - Should be included
-				gitCommit(t, repoPath, "C1 main", author1Name, author1Email, testTime(2023, 4, 1, 10, 0, 0), map[string]string{"main.txt": "m1"}) // C1
-				runGitCommand(t, repoPath, "checkout", "-b", "feat")
-				gitCommit(t, repoPath, "C2 feat", author2Name, author2Email, testTime(2023, 4, 2, 11, 0, 0), map[string]string{"feat.txt": "f1"}) // C2
-				runGitCommand(t, repoPath, "checkout", "main")
-				// Create merge commit explicitly setting committer/author
-				mergeDate := testTime(2023, 4, 3, 12, 0, 0)
-				cmd := exec.Command("git", "merge", "--no-ff", "-m", "Merge branch 'feat'", "feat")
-				cmd.Dir = repoPath
-				cmd.Env = append(os.Environ(),
-					"GIT_AUTHOR_NAME="+mergerName, "GIT_AUTHOR_EMAIL="+mergerEmail, "GIT_AUTHOR_DATE="+mergeDate.Format(time.RFC3339),
-					"GIT_COMMITTER_NAME="+mergerName, "GIT_COMMITTER_EMAIL="+mergerEmail, "GIT_COMMITTER_DATE="+mergeDate.Format(time.RFC3339),
-				)
-				output, err := cmd.CombinedOutput()
-				if err != nil {
-					t.Fatalf("git merge failed: %v\nOutput: %s", err, string(output))
-				}
-				// Commit after merge
-				gitCommit(t, repoPath, "C4 main", author1Name, author1Email, testTime(2023, 4, 4, 13, 0, 0), map[string]string{"main.txt": "m2"}) // C4
-			},
-			opts: nil, // No filters, relies on --no-merges default in GetLogsJSON
+			name:      "Success: Merge commit excluded",
+			setupRepo: setupMergeRepo,
+			opts:      nil, // No filters, relies on --no-merges default in GetLogsJSON
 			expectedData: []expectedLogEntry{
 				{ // C1
 					CommitDateTime: testTime(2023, 4, 1, 10, 0, 0).Format(time.RFC3339),
@@ -291,6 +310,30 @@ func TestGetLogsJSON(t *testing.T) {
 			},
 			expectedError: false,
 		},
+		{
+			name:      "Success: Merge commit included with IncludeMergeCommits",
+			setupRepo: setupMergeRepo,
+			opts:      &gitlogs.Options{IncludeMergeCommits: true},
+			expectedData: []expectedLogEntry{
+				{ // C1
+					CommitDateTime: testTime(2023, 4, 1, 10, 0, 0).Format(time.RFC3339),
+					AuthorName:     author1Name, AuthorEmail: author1Email, Message: "C1 main", ModifiedFiles: []string{"main.txt"},
+				},
+				{ // C2
+					CommitDateTime: testTime(2023, 4, 2, 11, 0, 0).Format(time.RFC3339),
+					AuthorName:     author2Name, AuthorEmail: author2Email, Message: "C2 feat", ModifiedFiles: []string{"feat.txt"},
+				},
+				{ // C3: kept although merges list no files
+					CommitDateTime: testTime(2023, 4, 3, 12, 0, 0).Format(time.RFC3339),
+					AuthorName:     mergerName, AuthorEmail: mergerEmail, Message: "Merge branch 'feat'", ModifiedFiles: []string{},
+				},
+				{ // C4
+					CommitDateTime: testTime(2023, 4, 4, 13, 0, 0).Format(time.RFC3339),
+					AuthorName:     author1Name, AuthorEmail: author1Email, Message: "C4 main", ModifiedFiles: []string{"main.txt"},
+				},
+			},
+			expectedError: false,
+		},
 		{
 			name: "Success: All branches included",
 			setupRepo: func(t *testing.T, repoPath string) {
@@ -312,6 +355,41 @@ func TestGetLogsJSON(t *testing.T) {
 			},
 			expectedError: false,
 		},
+		{
+			name: "Success: Grep filters by commit message",
+			setupRepo: func(t *testing.T, repoPath string) {
+				gitCommit(t, repoPath, "feat: add login", author1Name, author1Email, testTime(2023, 6, 1, 10, 0, 0), map[string]string{"login.go": "l"})
+				gitCommit(t, repoPath, "fix: login typo", author2Name, author2Email, testTime(2023, 6, 2, 10, 0, 0), map[string]string{"login.go": "l2"})
+				gitCommit(t, repoPath, "docs: readme", author1Name, author1Email, testTime(2023, 6, 3, 10, 0, 0), map[string]string{"README.md": "r"})
+			},
+			opts: &gitlogs.Options{Grep: []string{"^feat", "^fix"}},
+			expectedData: []expectedLogEntry{
+				{
+					CommitDateTime: testTime(2023, 6, 1, 10, 0, 0).Format(time.RFC3339),
+					AuthorName:     author1Name, AuthorEmail: author1Email, Message: "feat: add login", ModifiedFiles: []string{"login.go"},
+				},
+				{
+					CommitDateTime: testTime(2023, 6, 2, 10, 0, 0).Format(time.RFC3339),
+					AuthorName:     author2Name, AuthorEmail: author2Email, Message: "fix: login typo", ModifiedFiles: []string{"login.go"},
+				},
+			},
+			expectedError: false,
+		},
+		{
+			name: "Success: Grep with all-match",
+			setupRepo: func(t *testing.T, repoPath string) {
+				gitCommit(t, repoPath, "feat: add login", author1Name, author1Email, testTime(2023, 6, 1, 10, 0, 0), map[string]string{"login.go": "l"})
+				gitCommit(t, repoPath, "fix: login typo", author2Name, author2Email, testTime(2023, 6, 2, 10, 0, 0), map[string]string{"login.go": "l2"})
+			},
+			opts: &gitlogs.Options{Grep: []string{"^fix", "login"}, GrepAllMatch: true},
+			expectedData: []expectedLogEntry{
+				{
+					CommitDateTime: testTime(2023, 6, 2, 10, 0, 0).Format(time.RFC3339),
+					AuthorName:     author2Name, AuthorEmail: author2Email, Message: "fix: login typo", ModifiedFiles: []string{"login.go"},
+				},
+			},
+			expectedError: false,
+		},
 	}
 
 	// --- Run Test Cases ---
@@ -353,6 +431,15 @@ func TestGetLogsJSON(t *testing.T) {
 				t.Fatalf("Failed to unmarshal actual JSON response: %v\nJSON was:\n%s", err, actualJSONString)
 			}
 
+			// Hashes change with every run, so only their shape is checked
+			for i := range actualData {
+				hash, short := actualData[i].CommitHash, actualData[i].ShortHash
+				if len(hash) != 40 || short == "" || !strings.HasPrefix(hash, short) {
+					t.Errorf("Entry %d: expected a full commit_hash prefixed by short_hash, got %q and %q", i, hash, short)
+				}
+				actualData[i].CommitHash, actualData[i].ShortHash = "", ""
+			}
+
 			// Special handling for nil vs empty slice comparison
 			isEmptyExpected := len(tc.expectedData) == 0
 			isEmptyActual := len(actualData) == 0
@@ -398,3 +485,1490 @@ func TestGetLogsJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectHistoryRewrites(t *testing.T) {
+	upstream := setupGitRepo(t)
+	gitCommit(t, upstream, "Commit 1", author1Name, author1Email, testTime(2023, 7, 1, 10, 0, 0), map[string]string{"a.txt": "a"})
+	gitCommit(t, upstream, "Commit 2", author1Name, author1Email, testTime(2023, 7, 2, 10, 0, 0), map[string]string{"a.txt": "b"})
+	repoPath := filepath.Join(t.TempDir(), "clone")
+	runGitCommand(t, upstream, "clone", "-q", upstream, repoPath)
+
+	rewrites, err := gitlogs.DetectHistoryRewrites(repoPath, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(rewrites) != 0 {
+		t.Fatalf("Expected no rewrites before the force-push, got %+v", rewrites)
+	}
+
+	// Rewrite the upstream branch, as a force-push would, and fetch it.
+	runGitCommand(t, upstream, "reset", "-q", "--hard", "HEAD~1")
+	gitCommit(t, upstream, "Commit 2 (reworded)", author1Name, author1Email, testTime(2023, 7, 2, 10, 0, 0), map[string]string{"a.txt": "c"})
+	runGitCommand(t, repoPath, "fetch", "-q", "origin")
+
+	rewrites, err = gitlogs.DetectHistoryRewrites(repoPath, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(rewrites) != 1 {
+		t.Fatalf("Expected 1 rewrite after the forced fetch, got %d: %+v", len(rewrites), rewrites)
+	}
+	if rewrites[0].Ref != "refs/remotes/origin/main" || !strings.Contains(rewrites[0].Action, "forced-update") {
+		t.Errorf("Unexpected rewrite entry: %+v", rewrites[0])
+	}
+
+	// A window that ends before the fetch must not report it.
+	rewrites, err = gitlogs.DetectHistoryRewrites(repoPath, &gitlogs.Options{EndDate: PtrTime(testTime(2000, 1, 1, 0, 0, 0))})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(rewrites) != 0 {
+		t.Errorf("Expected rewrites outside the window to be ignored, got %+v", rewrites)
+	}
+}
+
+func TestDetectHistoryRewritesIgnoresLocalRewrites(t *testing.T) {
+	testCases := []struct {
+		name    string
+		rewrite func(t *testing.T, repoPath string)
+	}{
+		{"amend", func(t *testing.T, repoPath string) {
+			runGitCommand(t, repoPath, "commit", "-q", "--amend", "-m", "Commit 2 (amended)")
+		}},
+		{"reset", func(t *testing.T, repoPath string) {
+			runGitCommand(t, repoPath, "reset", "-q", "--hard", "HEAD~1")
+		}},
+		{"checkout", func(t *testing.T, repoPath string) {
+			runGitCommand(t, repoPath, "checkout", "-q", "-b", "topic", "HEAD~1")
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repoPath := setupGitRepo(t)
+			gitCommit(t, repoPath, "Commit 1", author1Name, author1Email, testTime(2023, 7, 1, 10, 0, 0), map[string]string{"a.txt": "a"})
+			gitCommit(t, repoPath, "Commit 2", author1Name, author1Email, testTime(2023, 7, 2, 10, 0, 0), map[string]string{"a.txt": "b"})
+			tc.rewrite(t, repoPath)
+
+			rewrites, err := gitlogs.DetectHistoryRewrites(repoPath, nil)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if len(rewrites) != 0 {
+				t.Errorf("Expected a local %s not to be reported, got %+v", tc.name, rewrites)
+			}
+		})
+	}
+}
+
+func TestFileHistory(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "Add module", author1Name, author1Email, testTime(2023, 8, 1, 10, 0, 0), map[string]string{"module/a.go": "a"})
+	gitCommit(t, repoPath, "Unrelated", author2Name, author2Email, testTime(2023, 8, 5, 10, 0, 0), map[string]string{"README.md": "r"})
+	gitCommit(t, repoPath, "Update module", author2Name, author2Email, testTime(2023, 8, 10, 10, 0, 0), map[string]string{"module/b.go": "b"})
+
+	first, last, commits, err := gitlogs.FileHistory(repoPath, "module", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if commits != 2 || !first.Equal(testTime(2023, 8, 1, 10, 0, 0)) || !last.Equal(testTime(2023, 8, 10, 10, 0, 0)) {
+		t.Errorf("Unexpected history: first=%v last=%v commits=%d", first, last, commits)
+	}
+
+	first, last, commits, err = gitlogs.FileHistory(repoPath, "missing.txt", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if commits != 0 || !first.IsZero() || !last.IsZero() {
+		t.Errorf("Expected empty history for untouched path, got first=%v last=%v commits=%d", first, last, commits)
+	}
+}
+
+func TestGetLogsJSONCache(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	cacheDir := t.TempDir()
+	gitCommit(t, repoPath, "Commit 1", author1Name, author1Email, testTime(2023, 9, 1, 10, 0, 0), map[string]string{"a.txt": "a"})
+	opts := &gitlogs.Options{CacheDir: cacheDir}
+
+	first, err := gitlogs.GetLogsJSON(repoPath, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Expected exactly one cache file, got %v (err: %v)", entries, err)
+	}
+
+	// Tamper with the cache file to prove the next call is served from it.
+	cacheFile := filepath.Join(cacheDir, entries[0].Name())
+	if err := os.WriteFile(cacheFile, []byte("[]"), 0o600); err != nil {
+		t.Fatalf("Failed to overwrite cache file: %v", err)
+	}
+	cached, err := gitlogs.GetLogsJSON(repoPath, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if cached != "[]" {
+		t.Errorf("Expected cached result, got:\n%s", cached)
+	}
+
+	// A new commit moves HEAD and must invalidate the cache.
+	gitCommit(t, repoPath, "Commit 2", author2Name, author2Email, testTime(2023, 9, 2, 10, 0, 0), map[string]string{"b.txt": "b"})
+	fresh, err := gitlogs.GetLogsJSON(repoPath, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if fresh == first || !strings.Contains(fresh, "Commit 2") {
+		t.Errorf("Expected fresh result after new commit, got:\n%s", fresh)
+	}
+}
+
+func TestGetLogsJSONMergedPRsOnly(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "C1 main", author1Name, author1Email, testTime(2023, 10, 1, 10, 0, 0), map[string]string{"main.txt": "m1"})
+
+	merge := func(branch, message string, when time.Time) {
+		t.Helper()
+		cmd := exec.Command("git", "merge", "--no-ff", "-m", message, branch)
+		cmd.Dir = repoPath
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME="+mergerName, "GIT_AUTHOR_EMAIL="+mergerEmail, "GIT_AUTHOR_DATE="+when.Format(time.RFC3339),
+			"GIT_COMMITTER_NAME="+mergerName, "GIT_COMMITTER_EMAIL="+mergerEmail, "GIT_COMMITTER_DATE="+when.Format(time.RFC3339),
+		)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git merge failed: %v\nOutput: %s", err, string(output))
+		}
+	}
+
+	runGitCommand(t, repoPath, "checkout", "-b", "feature")
+	gitCommit(t, repoPath, "Add feature", author2Name, author2Email, testTime(2023, 10, 2, 10, 0, 0), map[string]string{"feature.txt": "f"})
+	runGitCommand(t, repoPath, "checkout", "main")
+	merge("feature", "Merge pull request #42 from bob/feature\n\nAdd the shiny feature", testTime(2023, 10, 3, 10, 0, 0))
+
+	runGitCommand(t, repoPath, "checkout", "-b", "local")
+	gitCommit(t, repoPath, "Local work", author1Name, author1Email, testTime(2023, 10, 4, 10, 0, 0), map[string]string{"local.txt": "l"})
+	runGitCommand(t, repoPath, "checkout", "main")
+	merge("local", "Merge branch 'local'", testTime(2023, 10, 5, 10, 0, 0))
+
+	actualJSON, err := gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{MergedPRsOnly: true})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	type pullRequest struct {
+		Number       int    `json:"number"`
+		SourceBranch string `json:"source_branch"`
+		Title        string `json:"title"`
+		AuthorName   string `json:"author_name"`
+		AuthorEmail  string `json:"author_email"`
+	}
+	var actual []struct {
+		AuthorEmail   string       `json:"author_email"`
+		ModifiedFiles []string     `json:"modified_files"`
+		PullRequest   *pullRequest `json:"pull_request"`
+	}
+	if err := json.Unmarshal([]byte(actualJSON), &actual); err != nil {
+		t.Fatalf("Failed to unmarshal JSON: %v\n%s", err, actualJSON)
+	}
+	if len(actual) != 1 {
+		t.Fatalf("Expected only the pull request merge, got %d entries:\n%s", len(actual), actualJSON)
+	}
+	// The pull request author is the feature branch's author, not the merger.
+	expectedPR := &pullRequest{Number: 42, SourceBranch: "bob/feature", Title: "Add the shiny feature", AuthorName: author2Name, AuthorEmail: author2Email}
+	if !reflect.DeepEqual(actual[0].PullRequest, expectedPR) {
+		t.Errorf("Pull request mismatch: expected %+v, got %+v", expectedPR, actual[0].PullRequest)
+	}
+	if actual[0].AuthorEmail != mergerEmail || !reflect.DeepEqual(actual[0].ModifiedFiles, []string{"feature.txt"}) {
+		t.Errorf("Unexpected merge entry: %+v", actual[0])
+	}
+
+	redactedJSON, err := gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{MergedPRsOnly: true, RedactEmails: true})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := json.Unmarshal([]byte(redactedJSON), &actual); err != nil {
+		t.Fatalf("Failed to unmarshal JSON: %v\n%s", err, redactedJSON)
+	}
+	if len(actual) != 1 || actual[0].PullRequest == nil || actual[0].PullRequest.AuthorEmail != gitlogs.RedactEmail(author2Email) {
+		t.Errorf("Expected the pull request author's email redacted, got:\n%s", redactedJSON)
+	}
+}
+
+func TestRepositoryName(t *testing.T) {
+	repoPath := setupGitRepo(t)
+
+	name, err := gitlogs.RepositoryName(repoPath)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if name != filepath.Base(repoPath) {
+		t.Errorf("Expected directory name %q without a remote, got %q", filepath.Base(repoPath), name)
+	}
+
+	remotes := map[string]string{
+		"https://github.com/Stone-IT-Cloud/reporting.git": "Stone-IT-Cloud/reporting",
+		"git@github.com:Stone-IT-Cloud/reporting.git":     "Stone-IT-Cloud/reporting",
+		"ssh://git@example.com:2222/team/project":         "team/project",
+	}
+	runGitCommand(t, repoPath, "remote", "add", "origin", "placeholder")
+	for remoteURL, expected := range remotes {
+		runGitCommand(t, repoPath, "remote", "set-url", "origin", remoteURL)
+		name, err := gitlogs.RepositoryName(repoPath)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if name != expected {
+			t.Errorf("RepositoryName for remote %q: expected %q, got %q", remoteURL, expected, name)
+		}
+	}
+}
+
+func TestGetLogsJSONGzip(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "Commit 1", author1Name, author1Email, testTime(2023, 9, 1, 10, 0, 0), map[string]string{"a.txt": "a"})
+	gitCommit(t, repoPath, "Commit <2> & \"quotes\"", author2Name, author2Email, testTime(2023, 9, 2, 10, 0, 0), map[string]string{"b.txt": "b", "c.txt": "c"})
+	gitCommit(t, repoPath, "Commit 3", author1Name, author1Email, testTime(2023, 9, 3, 10, 0, 0), map[string]string{"a.txt": "a2"})
+
+	expected, err := gitlogs.GetLogsJSON(repoPath, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// The entries are encoded one at a time; the result must match encoding them at once.
+	var entries []gitlogs.LogEntry
+	if err := json.Unmarshal([]byte(expected), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal JSON result: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	marshalled, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to marshal entries: %v", err)
+	}
+	if string(marshalled) != expected {
+		t.Errorf("Streamed JSON differs from json.MarshalIndent:\nExpected:\n%s\nActual:\n%s", marshalled, expected)
+	}
+
+	var compressed bytes.Buffer
+	if err := gitlogs.GetLogsJSONGzip(repoPath, nil, &compressed); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	zr, err := gzip.NewReader(&compressed)
+	if err != nil {
+		t.Fatalf("Output is not a valid gzip stream: %v", err)
+	}
+	decompressed, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("Failed to decompress output: %v", err)
+	}
+	if string(decompressed) != expected {
+		t.Errorf("Round-tripped JSON mismatch:\nExpected:\n%s\nActual:\n%s", expected, decompressed)
+	}
+}
+
+// failingWriter fails every write after the first n bytes.
+type failingWriter struct {
+	n int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		written := w.n
+		w.n = 0
+		return written, errors.New("disk full")
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestGetLogsJSONGzipErrors(t *testing.T) {
+	var out bytes.Buffer
+	if err := gitlogs.GetLogsJSONGzip(filepath.Join(t.TempDir(), "missing"), nil, &out); err == nil {
+		t.Fatal("Expected an error for a missing repository, got nil")
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected nothing written on error, got %d bytes", out.Len())
+	}
+
+	repoPath := setupGitRepo(t)
+	for i := 0; i < 20; i++ {
+		gitCommit(t, repoPath, fmt.Sprintf("Commit %d", i), author1Name, author1Email, testTime(2023, 9, 1, i, 0, 0), map[string]string{"a.txt": fmt.Sprint(i)})
+	}
+	if err := gitlogs.GetLogsJSONGzip(repoPath, nil, &failingWriter{n: 10}); err == nil {
+		t.Fatal("Expected the write error to be returned, got nil")
+	}
+}
+
+func TestGetLogsJSONEmptyResult(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "Commit 1", author1Name, author1Email, testTime(2023, 9, 1, 10, 0, 0), map[string]string{"a.txt": "a"})
+	for _, opts := range []*gitlogs.Options{
+		{StartDate: PtrTime(testTime(2024, 1, 1, 0, 0, 0))},
+		{Authors: []string{"nobody@example.com"}},
+	} {
+		got, err := gitlogs.GetLogsJSON(repoPath, opts)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if got != "[]" {
+			t.Errorf("Expected [], got %q", got)
+		}
+	}
+}
+
+func TestGetLogsJSONMergeAtHead(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	setupMergeRepo(t, repoPath)
+	runGitCommand(t, repoPath, "reset", "--hard", "HEAD~1") // Drop C4: the merge is HEAD, printed last
+
+	for _, order := range []gitlogs.Order{gitlogs.OrderChronological, gitlogs.OrderReverseChronological} {
+		t.Run(string(order), func(t *testing.T) {
+			jsonResult, err := gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{
+				IncludeMergeCommits: true,
+				StartDate:           PtrTime(testTime(2023, 4, 1, 0, 0, 0)),
+				Order:               order,
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			var entries []expectedLogEntry
+			if err := json.Unmarshal([]byte(jsonResult), &entries); err != nil {
+				t.Fatalf("Failed to unmarshal JSON result: %v", err)
+			}
+			var messages []string
+			for _, e := range entries {
+				messages = append(messages, e.Message)
+			}
+			expected := []string{"C1 main", "C2 feat", "Merge branch 'feat'"}
+			if order == gitlogs.OrderReverseChronological {
+				expected = []string{"Merge branch 'feat'", "C2 feat", "C1 main"}
+			}
+			if !reflect.DeepEqual(messages, expected) {
+				t.Errorf("Expected %v, got %v", expected, messages)
+			}
+		})
+	}
+}
+
+func TestGetLogsJSONOrder(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "Commit 1", author1Name, author1Email, testTime(2023, 9, 1, 10, 0, 0), map[string]string{"a.txt": "a"})
+	gitCommit(t, repoPath, "Commit 2", author2Name, author2Email, testTime(2023, 9, 2, 10, 0, 0), map[string]string{"b.txt": "b"})
+	gitCommit(t, repoPath, "Commit 3", author1Name, author1Email, testTime(2023, 9, 3, 10, 0, 0), map[string]string{"c.txt": "c"})
+
+	testCases := []struct {
+		name     string
+		order    gitlogs.Order
+		expected []string
+	}{
+		{name: "Default", order: "", expected: []string{"Commit 1", "Commit 2", "Commit 3"}},
+		{name: "Chronological", order: gitlogs.OrderChronological, expected: []string{"Commit 1", "Commit 2", "Commit 3"}},
+		{name: "Reverse chronological", order: gitlogs.OrderReverseChronological, expected: []string{"Commit 3", "Commit 2", "Commit 1"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			jsonResult, err := gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{Order: tc.order})
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			var entries []expectedLogEntry
+			if err := json.Unmarshal([]byte(jsonResult), &entries); err != nil {
+				t.Fatalf("Failed to unmarshal JSON result: %v", err)
+			}
+			messages := make([]string, len(entries))
+			for i, e := range entries {
+				messages[i] = e.Message
+			}
+			if !reflect.DeepEqual(messages, tc.expected) {
+				t.Errorf("Order mismatch:\nExpected: %v\nActual:   %v", tc.expected, messages)
+			}
+		})
+	}
+
+	if _, err := gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{Order: "sideways"}); err == nil {
+		t.Error("Expected an error for an invalid order, but got nil")
+	}
+}
+
+func TestGetLogsJSONIncludeRefs(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "Commit 1", author1Name, author1Email, testTime(2023, 9, 1, 10, 0, 0), map[string]string{"a.txt": "a"})
+	runGitCommand(t, repoPath, "tag", "v1.0")
+	gitCommit(t, repoPath, "Commit 2", author2Name, author2Email, testTime(2023, 9, 2, 10, 0, 0), map[string]string{"b.txt": "b"})
+
+	type refsEntry struct {
+		Message string   `json:"commit_message"`
+		Refs    []string `json:"refs"`
+	}
+	decode := func(opts *gitlogs.Options) []refsEntry {
+		t.Helper()
+		jsonResult, err := gitlogs.GetLogsJSON(repoPath, opts)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		var entries []refsEntry
+		if err := json.Unmarshal([]byte(jsonResult), &entries); err != nil {
+			t.Fatalf("Failed to unmarshal JSON result: %v", err)
+		}
+		return entries
+	}
+
+	expected := []refsEntry{
+		{Message: "Commit 1", Refs: []string{"v1.0"}},
+		{Message: "Commit 2", Refs: []string{"main"}},
+	}
+	if actual := decode(&gitlogs.Options{IncludeRefs: true}); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Refs mismatch:\nExpected: %+v\nActual:   %+v", expected, actual)
+	}
+	for _, e := range decode(nil) {
+		if e.Refs != nil {
+			t.Errorf("Expected no refs without IncludeRefs, got %v for %q", e.Refs, e.Message)
+		}
+	}
+}
+
+func TestGetLogsJSONExtraArgs(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "Commit 1", author1Name, author1Email, testTime(2023, 9, 1, 10, 0, 0), map[string]string{"a.txt": "a"})
+	gitCommit(t, repoPath, "Commit 2", author2Name, author2Email, testTime(2023, 9, 2, 10, 0, 0), map[string]string{"b.txt": "b"})
+
+	jsonResult, err := gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{ExtraArgs: []string{"--author=" + author2Email}})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var entries []expectedLogEntry
+	if err := json.Unmarshal([]byte(jsonResult), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal JSON result: %v", err)
+	}
+	if len(entries) != 1 || entries[0].AuthorEmail != author2Email {
+		t.Errorf("Expected only %s's commit, got %+v", author2Email, entries)
+	}
+
+	for _, arg := range []string{
+		"--pretty=oneline", "--format=%H", "--oneline", "--output=/tmp/x", "-z", "--name-status", "--raw",
+		"--stat", "--stat=80", "--numstat", "--shortstat", "-p", "--patch", "--graph",
+	} {
+		if _, err := gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{ExtraArgs: []string{arg}}); err == nil {
+			t.Errorf("Expected an error for extra argument %q, but got nil", arg)
+		}
+	}
+}
+
+func TestGetLogsJSONTieBreak(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	sameTime := testTime(2023, 9, 1, 10, 0, 0)
+	for i := 1; i <= 4; i++ {
+		name := fmt.Sprintf("f%d.txt", i)
+		gitCommit(t, repoPath, fmt.Sprintf("Commit %d", i), author1Name, author1Email, sameTime, map[string]string{name: name})
+	}
+
+	cmd := exec.Command("git", "log", "--no-merges", "--format=%H%x09%s", "--author="+author1Email)
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git log failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	sort.Strings(lines) // By hash
+	expected := make([]string, len(lines))
+	for i, line := range lines {
+		expected[i] = strings.SplitN(line, "\t", 2)[1]
+	}
+
+	for _, order := range []gitlogs.Order{gitlogs.OrderChronological, gitlogs.OrderReverseChronological} {
+		jsonResult, err := gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{Order: order})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		var entries []expectedLogEntry
+		if err := json.Unmarshal([]byte(jsonResult), &entries); err != nil {
+			t.Fatalf("Failed to unmarshal JSON result: %v", err)
+		}
+		messages := make([]string, len(entries))
+		for i, e := range entries {
+			messages[i] = e.Message
+		}
+		want := append([]string(nil), expected...)
+		if order == gitlogs.OrderReverseChronological {
+			for i, j := 0, len(want)-1; i < j; i, j = i+1, j-1 {
+				want[i], want[j] = want[j], want[i]
+			}
+		}
+		if !reflect.DeepEqual(messages, want) {
+			t.Errorf("Order %s mismatch:\nExpected: %v\nActual:   %v", order, want, messages)
+		}
+	}
+}
+
+func TestGetLogsJSONCommitHash(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "Commit 1", author1Name, author1Email, testTime(2023, 9, 1, 10, 0, 0), map[string]string{"a.txt": "a"})
+	gitCommit(t, repoPath, "Commit 2", author2Name, author2Email, testTime(2023, 9, 2, 10, 0, 0), map[string]string{"b.txt": "b"})
+
+	cmd := exec.Command("git", "log", "--no-merges", "--format=%H %h", "-2")
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git log failed: %v", err)
+	}
+	var expected []string // "hash short" per commit, oldest first
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		expected = append([]string{line}, expected...)
+	}
+
+	jsonResult, err := gitlogs.GetLogsJSON(repoPath, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var entries []expectedLogEntry
+	if err := json.Unmarshal([]byte(jsonResult), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal JSON result: %v", err)
+	}
+	actual := make([]string, len(entries))
+	for i, e := range entries {
+		actual[i] = e.CommitHash + " " + e.ShortHash
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Hash mismatch:\nExpected: %v\nActual:   %v", expected, actual)
+	}
+
+	var logEntries []gitlogs.LogEntry
+	if err := json.Unmarshal([]byte(jsonResult), &logEntries); err != nil {
+		t.Fatalf("Failed to unmarshal JSON result: %v", err)
+	}
+	if violations := gitlogs.DCOCheck(logEntries); len(violations) != 2 || violations[0].Hash != entries[0].CommitHash {
+		t.Errorf("Expected DCO violations to carry the commit hash %s, got %+v", entries[0].CommitHash, violations)
+	}
+}
+
+func TestGetLogsJSONGapMode(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "A1", author1Name, author1Email, testTime(2023, 9, 1, 10, 0, 0), map[string]string{"a1.txt": "a"})
+	gitCommit(t, repoPath, "B1", author2Name, author2Email, testTime(2023, 9, 1, 12, 0, 0), map[string]string{"b1.txt": "b"})
+	gitCommit(t, repoPath, "A2", author1Name, author1Email, testTime(2023, 9, 2, 10, 0, 0), map[string]string{"a2.txt": "a"})
+
+	type gapEntry struct {
+		Message           string        `json:"commit_message"`
+		TimeSincePrevious time.Duration `json:"time_since_previous_ns"`
+	}
+	testCases := []struct {
+		name     string
+		opts     *gitlogs.Options
+		expected []gapEntry
+	}{
+		{
+			name:     "Disabled",
+			opts:     &gitlogs.Options{},
+			expected: []gapEntry{{Message: "A1"}, {Message: "B1"}, {Message: "A2"}},
+		},
+		{
+			name:     "Global",
+			opts:     &gitlogs.Options{GapMode: gitlogs.GapModeGlobal},
+			expected: []gapEntry{{Message: "A1"}, {Message: "B1", TimeSincePrevious: 2 * time.Hour}, {Message: "A2", TimeSincePrevious: 22 * time.Hour}},
+		},
+		{
+			name:     "Per author",
+			opts:     &gitlogs.Options{GapMode: gitlogs.GapModePerAuthor},
+			expected: []gapEntry{{Message: "A1"}, {Message: "B1"}, {Message: "A2", TimeSincePrevious: 24 * time.Hour}},
+		},
+		{
+			name:     "Per author newest first",
+			opts:     &gitlogs.Options{GapMode: gitlogs.GapModePerAuthor, Order: gitlogs.OrderReverseChronological},
+			expected: []gapEntry{{Message: "A2", TimeSincePrevious: 24 * time.Hour}, {Message: "B1"}, {Message: "A1"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			jsonResult, err := gitlogs.GetLogsJSON(repoPath, tc.opts)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			var entries []gapEntry
+			if err := json.Unmarshal([]byte(jsonResult), &entries); err != nil {
+				t.Fatalf("Failed to unmarshal JSON result: %v", err)
+			}
+			if !reflect.DeepEqual(entries, tc.expected) {
+				t.Errorf("Gap mismatch:\nExpected: %+v\nActual:   %+v", tc.expected, entries)
+			}
+		})
+	}
+
+	if _, err := gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{GapMode: "weekly"}); err == nil {
+		t.Error("Expected an error for an invalid gap mode, but got nil")
+	}
+}
+
+func TestGetLogsJSONNotOnBranch(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "C1 main", author1Name, author1Email, testTime(2023, 10, 1, 10, 0, 0), map[string]string{"main.txt": "m1"})
+	runGitCommand(t, repoPath, "checkout", "-b", "feature")
+	gitCommit(t, repoPath, "F1 feature", author2Name, author2Email, testTime(2023, 10, 2, 10, 0, 0), map[string]string{"feature.txt": "f1"})
+	gitCommit(t, repoPath, "F2 feature", author2Name, author2Email, testTime(2023, 10, 3, 10, 0, 0), map[string]string{"feature.txt": "f2"})
+	runGitCommand(t, repoPath, "checkout", "main")
+	gitCommit(t, repoPath, "C2 main", author1Name, author1Email, testTime(2023, 10, 4, 10, 0, 0), map[string]string{"main.txt": "m2"})
+	runGitCommand(t, repoPath, "checkout", "feature")
+
+	jsonResult, err := gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{NotOnBranch: "main"})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var entries []expectedLogEntry
+	if err := json.Unmarshal([]byte(jsonResult), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal JSON result: %v", err)
+	}
+	messages := make([]string, len(entries))
+	for i, e := range entries {
+		messages[i] = e.Message
+	}
+	expected := []string{"F1 feature", "F2 feature"}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+
+	_, err = gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{NotOnBranch: "no-such-branch"})
+	var unknownRef gitlogs.ErrUnknownRef
+	if !errors.As(err, &unknownRef) || unknownRef.Ref != "no-such-branch" {
+		t.Errorf("Expected ErrUnknownRef for no-such-branch, got %v", err)
+	}
+}
+
+func TestGetLogsJSONLegacyEncoding(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	// Record the message in Latin-1 and make git's default log output Latin-1 as well.
+	runGitCommand(t, repoPath, "config", "i18n.commitEncoding", "ISO-8859-1")
+	gitCommit(t, repoPath, "Caf\xe9 d\xe9j\xe0 vu", author1Name, author1Email, testTime(2023, 9, 1, 10, 0, 0), map[string]string{"a.txt": "a"})
+
+	jsonResult, err := gitlogs.GetLogsJSON(repoPath, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var entries []expectedLogEntry
+	if err := json.Unmarshal([]byte(jsonResult), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal JSON result: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Message != "Café déjà vu" {
+		t.Errorf("Expected the message transcoded to UTF-8, got %+v", entries)
+	}
+}
+
+func TestGetLogsJSONPaths(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "Web only", author1Name, author1Email, testTime(2023, 9, 1, 10, 0, 0), map[string]string{"web/app.js": "a"})
+	gitCommit(t, repoPath, "API only", author2Name, author2Email, testTime(2023, 9, 2, 10, 0, 0), map[string]string{"api/main.go": "b"})
+	gitCommit(t, repoPath, "Both", author1Name, author1Email, testTime(2023, 9, 3, 10, 0, 0), map[string]string{"web/app.js": "c", "api/main.go": "c", "web/gen/bundle.js": "c"})
+
+	testCases := []struct {
+		name     string
+		opts     *gitlogs.Options
+		expected map[string][]string // Message -> modified files
+	}{
+		{
+			name:     "Paths",
+			opts:     &gitlogs.Options{Paths: []string{"web/"}},
+			expected: map[string][]string{"Web only": {"web/app.js"}, "Both": {"web/app.js", "web/gen/bundle.js"}},
+		},
+		{
+			name:     "Paths with exclusion",
+			opts:     &gitlogs.Options{Paths: []string{"web/"}, ExcludePaths: []string{"web/gen/"}},
+			expected: map[string][]string{"Web only": {"web/app.js"}, "Both": {"web/app.js"}},
+		},
+		{
+			name:     "Exclusion only",
+			opts:     &gitlogs.Options{ExcludePaths: []string{"web/"}},
+			expected: map[string][]string{"API only": {"api/main.go"}, "Both": {"api/main.go"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			jsonResult, err := gitlogs.GetLogsJSON(repoPath, tc.opts)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			var entries []expectedLogEntry
+			if err := json.Unmarshal([]byte(jsonResult), &entries); err != nil {
+				t.Fatalf("Failed to unmarshal JSON result: %v", err)
+			}
+			actual := make(map[string][]string, len(entries))
+			for _, e := range entries {
+				actual[e.Message] = e.ModifiedFiles
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("Mismatch:\nExpected: %v\nActual:   %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+// recordingHandler is a slog.Handler that keeps every record it handles.
+type recordingHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r.Clone())
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *recordingHandler) WithGroup(string) slog.Handler { return h }
+
+// warnings returns the message and attributes of each record at warning level.
+func (h *recordingHandler) warnings() map[string]map[string]string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	warnings := make(map[string]map[string]string)
+	for _, r := range h.records {
+		if r.Level != slog.LevelWarn {
+			continue
+		}
+		attrs := make(map[string]string)
+		r.Attrs(func(a slog.Attr) bool {
+			attrs[a.Key] = a.Value.String()
+			return true
+		})
+		warnings[r.Message] = attrs
+	}
+	return warnings
+}
+
+// gitCommitUnparseableDate commits an empty change whose author date carries an
+// out-of-range time zone, which git prints but time.Parse rejects, and returns its hash.
+func gitCommitUnparseableDate(t *testing.T, repoPath string, commitDate time.Time) string {
+	t.Helper()
+	output := func(stdin string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoPath
+		cmd.Stdin = strings.NewReader(stdin)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git command failed (args: %v): %v", args, err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	object := fmt.Sprintf("tree %s\nparent %s\nauthor %s <%s> %d +9999\ncommitter %s <%s> %d +0000\n\nUnparseable date\n",
+		output("", "rev-parse", "HEAD^{tree}"), output("", "rev-parse", "HEAD"),
+		author2Name, author2Email, commitDate.Unix(), author2Name, author2Email, commitDate.Unix())
+	hash := output(object, "hash-object", "-t", "commit", "-w", "--literally", "--stdin")
+	runGitCommand(t, repoPath, "update-ref", "HEAD", hash)
+	return hash
+}
+
+func TestGetLogsJSONLoggerWarnings(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "Commit 1", author1Name, author1Email, testTime(2023, 9, 1, 10, 0, 0), map[string]string{"a.txt": "a"})
+	badHash := gitCommitUnparseableDate(t, repoPath, testTime(2023, 9, 2, 10, 0, 0))
+	// A regular file where the cache directory should go makes every cache write fail.
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	if err := os.WriteFile(cacheDir, []byte("x"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	handler := &recordingHandler{}
+	jsonResult, err := gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{CacheDir: cacheDir, Logger: slog.New(handler)})
+	if err != nil {
+		t.Fatalf("Expected warnings rather than an error, got: %v", err)
+	}
+	if !strings.Contains(jsonResult, "Commit 1") || strings.Contains(jsonResult, "Unparseable date") {
+		t.Errorf("Expected only the commit with a valid date, got:\n%s", jsonResult)
+	}
+
+	warnings := handler.warnings()
+	skipped, ok := warnings["skipping commit with unparseable date"]
+	if !ok {
+		t.Fatalf("Expected a warning about the skipped commit, got %v", warnings)
+	}
+	if skipped["hash"] != badHash || !strings.HasSuffix(skipped["date"], "+99:99") || skipped["error"] == "" {
+		t.Errorf("Expected the hash, date and error of the skipped commit, got %v", skipped)
+	}
+	cache, ok := warnings["cannot create log cache directory"]
+	if !ok {
+		t.Fatalf("Expected a warning about the cache directory, got %v", warnings)
+	}
+	if cache["dir"] != cacheDir || cache["error"] == "" {
+		t.Errorf("Expected the cache directory and error, got %v", cache)
+	}
+}
+
+func TestGetLogsJSONInclusiveEndDate(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "Morning", author1Name, author1Email, testTime(2023, 9, 1, 9, 0, 0), map[string]string{"a.txt": "a"})
+	gitCommit(t, repoPath, "Afternoon", author1Name, author1Email, testTime(2023, 9, 2, 15, 0, 0), map[string]string{"b.txt": "b"})
+	gitCommit(t, repoPath, "Next day", author1Name, author1Email, testTime(2023, 9, 3, 1, 0, 0), map[string]string{"c.txt": "c"})
+
+	bareDate := testTime(2023, 9, 2, 0, 0, 0)
+	testCases := []struct {
+		name     string
+		opts     *gitlogs.Options
+		expected []string
+	}{
+		{name: "Exclusive by default", opts: &gitlogs.Options{EndDate: &bareDate}, expected: []string{"Morning"}},
+		{name: "Inclusive", opts: &gitlogs.Options{EndDate: &bareDate, InclusiveEndDate: true}, expected: []string{"Morning", "Afternoon"}},
+		{
+			name:     "Not a bare date in Location",
+			opts:     &gitlogs.Options{EndDate: &bareDate, InclusiveEndDate: true, Location: time.FixedZone("UTC+10", 10*3600)},
+			expected: []string{"Morning"},
+		},
+		{
+			name:     "Time component is kept",
+			opts:     &gitlogs.Options{EndDate: PtrTime(testTime(2023, 9, 2, 12, 0, 0)), InclusiveEndDate: true},
+			expected: []string{"Morning"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			jsonResult, err := gitlogs.GetLogsJSON(repoPath, tc.opts)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			var entries []expectedLogEntry
+			if err := json.Unmarshal([]byte(jsonResult), &entries); err != nil {
+				t.Fatalf("Failed to unmarshal JSON result: %v", err)
+			}
+			messages := make([]string, len(entries))
+			for i, e := range entries {
+				messages[i] = e.Message
+			}
+			if !reflect.DeepEqual(messages, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, messages)
+			}
+		})
+	}
+}
+
+func TestGetLogsJSONHugeMessage(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	hugeMessage := "Huge\n\n" + strings.Repeat("All work and no play makes Jack a dull boy.\n", 80_000) // ~3.5 MB
+	gitCommit(t, repoPath, "Before", author1Name, author1Email, testTime(2023, 9, 1, 10, 0, 0), map[string]string{"a.txt": "a"})
+	gitCommit(t, repoPath, hugeMessage, author2Name, author2Email, testTime(2023, 9, 2, 10, 0, 0), map[string]string{"b.txt": "b"})
+	gitCommit(t, repoPath, "After", author1Name, author1Email, testTime(2023, 9, 3, 10, 0, 0), map[string]string{"c.txt": "c"})
+
+	jsonResult, err := gitlogs.GetLogsJSON(repoPath, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var entries []expectedLogEntry
+	if err := json.Unmarshal([]byte(jsonResult), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal JSON result: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].Message != "Before" || entries[2].Message != "After" {
+		t.Errorf("Expected surrounding commits to be kept in order, got %q and %q", entries[0].Message, entries[2].Message)
+	}
+	if entries[1].Message != strings.TrimSpace(hugeMessage) {
+		t.Errorf("Expected the huge message intact (%d bytes), got %d bytes", len(strings.TrimSpace(hugeMessage)), len(entries[1].Message))
+	}
+	if !reflect.DeepEqual(entries[1].ModifiedFiles, []string{"b.txt"}) {
+		t.Errorf("Expected modified files [b.txt], got %v", entries[1].ModifiedFiles)
+	}
+}
+
+func TestGetLogsJSONLinkPullRequests(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "C1 main", author1Name, author1Email, testTime(2023, 10, 1, 10, 0, 0), map[string]string{"main.txt": "m1"})
+
+	runGitCommand(t, repoPath, "checkout", "-b", "feature")
+	gitCommit(t, repoPath, "Start feature", author2Name, author2Email, testTime(2023, 10, 2, 10, 0, 0), map[string]string{"feature.txt": "f1"})
+	gitCommit(t, repoPath, "Finish feature", author2Name, author2Email, testTime(2023, 10, 2, 11, 0, 0), map[string]string{"feature.txt": "f2"})
+	runGitCommand(t, repoPath, "checkout", "main")
+	cmd := exec.Command("git", "merge", "--no-ff", "-m", "Merge pull request #42 from bob/feature", "feature")
+	cmd.Dir = repoPath
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE=2023-10-03T10:00:00Z", "GIT_COMMITTER_DATE=2023-10-03T10:00:00Z")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git merge failed: %v\nOutput: %s", err, string(output))
+	}
+	gitCommit(t, repoPath, "Tweak docs (#7)", author1Name, author1Email, testTime(2023, 10, 4, 10, 0, 0), map[string]string{"docs.txt": "d"})
+
+	links, err := gitlogs.LinkCommitsToPullRequests(repoPath, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(links) != 4 { // Two feature commits, the merge and the squash merge
+		t.Errorf("Expected 4 linked commits, got %d: %v", len(links), links)
+	}
+
+	jsonResult, err := gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{StartDate: PtrTime(testTime(2023, 10, 1, 0, 0, 0)), LinkPullRequests: true})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var entries []struct {
+		Message           string `json:"commit_message"`
+		PullRequestNumber int    `json:"pull_request_number"`
+	}
+	if err := json.Unmarshal([]byte(jsonResult), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal JSON result: %v", err)
+	}
+	actual := make(map[string]int, len(entries))
+	for _, e := range entries {
+		actual[e.Message] = e.PullRequestNumber
+	}
+	expected := map[string]int{"C1 main": 0, "Start feature": 42, "Finish feature": 42, "Tweak docs (#7)": 7}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Mismatch:\nExpected: %v\nActual:   %v", expected, actual)
+	}
+}
+
+func TestLinkCommitsToPullRequestsStackedMerges(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	merge := func(branch, message, date string) {
+		t.Helper()
+		cmd := exec.Command("git", "merge", "--no-ff", "-m", message, branch)
+		cmd.Dir = repoPath
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git merge failed: %v\nOutput: %s", err, string(output))
+		}
+	}
+	gitCommit(t, repoPath, "C1 main", author1Name, author1Email, testTime(2023, 10, 1, 10, 0, 0), map[string]string{"main.txt": "m1"})
+	runGitCommand(t, repoPath, "checkout", "-b", "base")
+	gitCommit(t, repoPath, "Base work", author2Name, author2Email, testTime(2023, 10, 2, 10, 0, 0), map[string]string{"base.txt": "b1"})
+	runGitCommand(t, repoPath, "checkout", "-b", "top")
+	gitCommit(t, repoPath, "Top work", author2Name, author2Email, testTime(2023, 10, 3, 10, 0, 0), map[string]string{"top.txt": "t1"})
+	gitCommit(t, repoPath, "Port fix (#3)", author2Name, author2Email, testTime(2023, 10, 3, 11, 0, 0), map[string]string{"top.txt": "t2"})
+	runGitCommand(t, repoPath, "checkout", "main")
+	gitCommit(t, repoPath, "C2 main", author1Name, author1Email, testTime(2023, 10, 4, 10, 0, 0), map[string]string{"main.txt": "m2"})
+	runGitCommand(t, repoPath, "checkout", "base")
+	merge("top", "Merge pull request #11 from bob/top", "2023-10-05T10:00:00Z")
+	runGitCommand(t, repoPath, "checkout", "main")
+	merge("base", "Merge pull request #12 from bob/base", "2023-10-06T10:00:00Z")
+
+	links, err := gitlogs.LinkCommitsToPullRequests(repoPath, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	jsonResult, err := gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{StartDate: PtrTime(testTime(2023, 10, 1, 0, 0, 0))})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var entries []struct {
+		Message string `json:"commit_message"`
+		Hash    string `json:"commit_hash"`
+	}
+	if err := json.Unmarshal([]byte(jsonResult), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal JSON result: %v", err)
+	}
+	actual := make(map[string]int, len(entries))
+	for _, e := range entries {
+		actual[strings.SplitN(e.Message, "\n", 2)[0]] = links[e.Hash]
+	}
+	expected := map[string]int{
+		"C1 main":       0,
+		"C2 main":       0,  // On main while the branches were open
+		"Base work":     12, // Only merged by the second pull request
+		"Top work":      11, // The earliest merge wins
+		"Port fix (#3)": 3,  // The commit's own subject wins
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Mismatch:\nExpected: %v\nActual:   %v", expected, actual)
+	}
+}
+
+// feedTestInterval is the poll interval used by the WatchCommits and FeedHandler tests.
+const feedTestInterval = 10 * time.Millisecond
+
+// syncWatcher commits until a watcher reports one of these commits on messages, so that
+// later commits are known to be made after it recorded the initial refs. Sync commits
+// reported afterwards are discarded.
+func syncWatcher(t *testing.T, repoPath string, messages <-chan string) {
+	t.Helper()
+	base := testTime(2023, 8, 1, 0, 0, 0)
+	for i := 0; i < 100; i++ {
+		gitCommit(t, repoPath, fmt.Sprintf("Sync %d", i), author1Name, author1Email, base.Add(time.Duration(i)*time.Second), map[string]string{"sync.txt": fmt.Sprint(i)})
+		select {
+		case <-messages:
+			drainMessages(messages)
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	t.Fatal("Timed out waiting for the watcher to start")
+}
+
+// drainMessages returns the messages received until none arrives for many poll intervals.
+func drainMessages(messages <-chan string) []string {
+	var received []string
+	for {
+		select {
+		case m := <-messages:
+			received = append(received, m)
+		case <-time.After(30 * feedTestInterval):
+			return received
+		}
+	}
+}
+
+// receiveMessages waits for n messages and returns them in the order received.
+func receiveMessages(t *testing.T, messages <-chan string, n int) []string {
+	t.Helper()
+	var received []string
+	timeout := time.After(5 * time.Second)
+	for len(received) < n {
+		select {
+		case m := <-messages:
+			received = append(received, m)
+		case <-timeout:
+			t.Fatalf("Timed out waiting for %d messages, got %v", n, received)
+		}
+	}
+	return received
+}
+
+func TestWatchCommits(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "Existing", author1Name, author1Email, testTime(2023, 9, 1, 10, 0, 0), map[string]string{"a.txt": "a"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	messages := make(chan string, 100)
+	done := make(chan error, 1)
+	go func() {
+		done <- gitlogs.WatchCommits(ctx, repoPath, nil, feedTestInterval, func(entry json.RawMessage) error {
+			var e expectedLogEntry
+			if err := json.Unmarshal(entry, &e); err != nil {
+				return err
+			}
+			messages <- e.Message
+			return nil
+		})
+	}()
+	syncWatcher(t, repoPath, messages)
+
+	gitCommit(t, repoPath, "New one", author2Name, author2Email, testTime(2023, 9, 2, 10, 0, 0), map[string]string{"b.txt": "b"})
+	gitCommit(t, repoPath, "New two", author2Name, author2Email, testTime(2023, 9, 3, 10, 0, 0), map[string]string{"c.txt": "c"})
+	if received := receiveMessages(t, messages, 2); !reflect.DeepEqual(received, []string{"New one", "New two"}) {
+		t.Errorf("Expected only the new commits in order, got %v", received)
+	}
+	if extra := drainMessages(messages); len(extra) != 0 {
+		t.Errorf("Expected each commit to be emitted once, got %v again", extra)
+	}
+
+	runGitCommand(t, repoPath, "reset", "--hard", "HEAD~2")
+	if extra := drainMessages(messages); len(extra) != 0 {
+		t.Errorf("Expected a rewound branch to emit nothing, got %v", extra)
+	}
+	gitCommit(t, repoPath, "After rewind", author1Name, author1Email, testTime(2023, 9, 4, 10, 0, 0), map[string]string{"d.txt": "d"})
+	if received := receiveMessages(t, messages, 1); received[0] != "After rewind" {
+		t.Errorf("Expected the commit made after the rewind, got %v", received)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled after cancel, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchCommits did not stop after cancel")
+	}
+}
+
+func TestFeedHandler(t *testing.T) {
+	testCases := []struct {
+		name            string
+		accept          string
+		wantContentType string
+		sse             bool
+	}{
+		{"ndjson", "", "application/x-ndjson", false},
+		{"server-sent events", "text/event-stream", "text/event-stream", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repoPath := setupGitRepo(t)
+			gitCommit(t, repoPath, "Existing", author1Name, author1Email, testTime(2023, 9, 1, 10, 0, 0), map[string]string{"a.txt": "a"})
+
+			handlerDone := make(chan struct{})
+			handler := gitlogs.FeedHandler(repoPath, nil, feedTestInterval)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer close(handlerDone)
+				handler.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			resp, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if got := resp.Header.Get("Content-Type"); got != tc.wantContentType {
+				t.Errorf("Expected Content-Type %q, got %q", tc.wantContentType, got)
+			}
+
+			// Read frames until the body ends, checking their framing and passing on
+			// each entry's message.
+			messages := make(chan string, 100)
+			readerDone := make(chan struct{})
+			go func() {
+				defer close(readerDone)
+				reader := bufio.NewReader(resp.Body)
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					data := strings.TrimSuffix(line, "\n")
+					if tc.sse {
+						dataLine, _ := reader.ReadString('\n')
+						blank, _ := reader.ReadString('\n')
+						if data != "event: commit" || !strings.HasPrefix(dataLine, "data: ") || blank != "\n" {
+							t.Errorf("Malformed event: %q %q %q", line, dataLine, blank)
+							return
+						}
+						data = strings.TrimSuffix(strings.TrimPrefix(dataLine, "data: "), "\n")
+					}
+					var e expectedLogEntry
+					if err := json.Unmarshal([]byte(data), &e); err != nil {
+						t.Errorf("Expected one JSON entry per frame, got %q: %v", data, err)
+						return
+					}
+					messages <- e.Message
+				}
+			}()
+			syncWatcher(t, repoPath, messages)
+
+			gitCommit(t, repoPath, "Streamed", author2Name, author2Email, testTime(2023, 9, 2, 10, 0, 0), map[string]string{"b.txt": "b"})
+			if received := receiveMessages(t, messages, 1); received[0] != "Streamed" {
+				t.Errorf("Expected the new commit, got %v", received)
+			}
+			if extra := drainMessages(messages); len(extra) != 0 {
+				t.Errorf("Expected each commit to be streamed once, got %v again", extra)
+			}
+
+			cancel()
+			select {
+			case <-handlerDone:
+			case <-time.After(5 * time.Second):
+				t.Fatal("FeedHandler did not return after the request was canceled")
+			}
+			<-readerDone
+		})
+	}
+}
+
+func TestGetLogsJSONExcludeAuthorsMatching(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "Inside", "Mia Maintainer", "mia@MyCompany.com", testTime(2023, 9, 1, 10, 0, 0), map[string]string{"a.txt": "a"})
+	gitCommit(t, repoPath, "Outside", author2Name, author2Email, testTime(2023, 9, 2, 10, 0, 0), map[string]string{"b.txt": "b"})
+
+	jsonResult, err := gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{
+		StartDate:              PtrTime(testTime(2023, 8, 1, 0, 0, 0)),
+		ExcludeAuthorsMatching: []string{`(?i)@mycompany\.com$`},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var entries []expectedLogEntry
+	if err := json.Unmarshal([]byte(jsonResult), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal JSON result: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Message != "Outside" {
+		t.Errorf("Expected only the outside commit, got %+v", entries)
+	}
+
+	if _, err := gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{ExcludeAuthorsMatching: []string{"[a-"}}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
+
+func TestGetLogsJSONAuthors(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "Alice work", author1Name, author1Email, testTime(2023, 9, 1, 10, 0, 0), map[string]string{"a.txt": "a"})
+	gitCommit(t, repoPath, "Bob work", author2Name, author2Email, testTime(2023, 9, 2, 10, 0, 0), map[string]string{"b.txt": "b"})
+	gitCommit(t, repoPath, "Bump deps", "dependabot[bot]", "49699333+dependabot[bot]@users.noreply.github.com", testTime(2023, 9, 3, 10, 0, 0), map[string]string{"go.sum": "x"})
+
+	testCases := []struct {
+		name     string
+		opts     gitlogs.Options
+		expected []string // Messages
+	}{
+		{"single author by email", gitlogs.Options{Authors: []string{"ALICE@example.com"}}, []string{"Alice work"}},
+		{"authors by name", gitlogs.Options{Authors: []string{"alice alpha", "Bob Bravo"}}, []string{"Alice work", "Bob work"}},
+		{"literal brackets", gitlogs.Options{Authors: []string{"dependabot[bot]"}}, []string{"Bump deps"}},
+		{"exclude bot", gitlogs.Options{ExcludeAuthors: []string{"Dependabot[bot]"}}, []string{"Alice work", "Bob work"}},
+		{"include and exclude", gitlogs.Options{Authors: []string{"example.com"}, ExcludeAuthors: []string{"bob@"}}, []string{"Alice work"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.opts.StartDate = PtrTime(testTime(2023, 8, 1, 0, 0, 0))
+			jsonResult, err := gitlogs.GetLogsJSON(repoPath, &tc.opts)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			var entries []expectedLogEntry
+			if err := json.Unmarshal([]byte(jsonResult), &entries); err != nil {
+				t.Fatalf("Failed to unmarshal JSON result: %v", err)
+			}
+			var messages []string
+			for _, e := range entries {
+				messages = append(messages, e.Message)
+			}
+			if !reflect.DeepEqual(messages, tc.expected) {
+				t.Errorf("Expected %q, got %q", tc.expected, messages)
+			}
+		})
+	}
+
+	if _, err := gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{Authors: []string{" "}}); err == nil {
+		t.Error("Expected an error for an empty author filter")
+	}
+}
+
+func TestHotspots(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	gitCommit(t, repoPath, "C1", author1Name, author1Email, testTime(2023, 9, 1, 10, 0, 0), map[string]string{"core.go": "1", "README.md": "1"})
+	gitCommit(t, repoPath, "C2", author2Name, author2Email, testTime(2023, 9, 2, 10, 0, 0), map[string]string{"core.go": "2", "docs.md": "1"})
+	gitCommit(t, repoPath, "C3", author1Name, author1Email, testTime(2023, 9, 3, 10, 0, 0), map[string]string{"core.go": "3", "docs.md": "2"})
+	gitCommit(t, repoPath, "C4", author1Name, author1Email, testTime(2023, 9, 4, 10, 0, 0), map[string]string{"README.md": "2"})
+
+	opts := &gitlogs.Options{StartDate: PtrTime(testTime(2023, 8, 1, 0, 0, 0))}
+	hotspots, err := gitlogs.Hotspots(repoPath, opts, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := []gitlogs.FileHotspot{
+		{Path: "core.go", Changes: 3, Authors: 2},
+		{Path: "docs.md", Changes: 2, Authors: 2}, // Ahead of README.md: more authors
+	}
+	if !reflect.DeepEqual(hotspots, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, hotspots)
+	}
+
+	hotspots, err = gitlogs.Hotspots(repoPath, opts, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(hotspots) != 3 {
+		t.Errorf("Expected all 3 files without a limit, got %+v", hotspots)
+	}
+}
+
+func TestDetectCommitLanguage(t *testing.T) {
+	entries := func(subjects ...string) []gitlogs.LogEntry {
+		var result []gitlogs.LogEntry
+		for _, s := range subjects {
+			result = append(result, gitlogs.LogEntry{Message: s + "\n\nBody text is ignored."})
+		}
+		return result
+	}
+	tests := []struct {
+		name     string
+		entries  []gitlogs.LogEntry
+		expected string
+	}{
+		{"english", entries("Add retry to the uploader", "Fix crash when config is empty", "v1.2.3"), "en"},
+		{"spanish", entries("Corrige el error de login", "Agrega validación para los usuarios", "Fix typo"), "es"},
+		{"portuguese", entries("Corrige o erro do login", "Adiciona validação para os usuários"), "pt"},
+		{"german", entries("Fehler beim Login behoben", "Neue Option für den Export hinzugefügt"), "de"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lang, err := gitlogs.DetectCommitLanguage(tt.entries)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if lang != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, lang)
+			}
+		})
+	}
+
+	if _, err := gitlogs.DetectCommitLanguage(entries("v1.2.3", "WIP")); !errors.Is(err, gitlogs.ErrLanguageUndetected) {
+		t.Errorf("Expected ErrLanguageUndetected without evidence, got %v", err)
+	}
+	if _, err := gitlogs.DetectCommitLanguage(nil); !errors.Is(err, gitlogs.ErrLanguageUndetected) {
+		t.Errorf("Expected ErrLanguageUndetected for no entries, got %v", err)
+	}
+}
+
+func TestDCOCheck(t *testing.T) {
+	entry := func(email, message string) gitlogs.LogEntry {
+		return gitlogs.LogEntry{AuthorName: "Alice", AuthorEmail: email, Message: message}
+	}
+	entries := []gitlogs.LogEntry{
+		entry("alice@example.com", "Signed\n\nSigned-off-by: Alice <Alice@Example.com>"),
+		entry("alice@example.com", "Unsigned\n\nJust a body."),
+		entry("alice@example.com", "Signed by someone else\n\nReviewed-by: Bob <bob@example.com>\nSigned-off-by: Bob <bob@example.com>"),
+		entry("alice@example.com", "Sign-off in the body\n\nSigned-off-by: Alice <alice@example.com>\n\nMore text after it."),
+		entry("alice@example.com", "Signed-off-by: Alice <alice@example.com>"),
+	}
+	violations := gitlogs.DCOCheck(entries)
+	expected := []struct{ subject, reason string }{
+		{"Unsigned", gitlogs.DCOMissingSignOff},
+		{"Signed by someone else", gitlogs.DCOSignOffMismatch},
+		{"Sign-off in the body", gitlogs.DCOMissingSignOff},
+		{"Signed-off-by: Alice <alice@example.com>", gitlogs.DCOMissingSignOff}, // A subject is not a trailer
+	}
+	if len(violations) != len(expected) {
+		t.Fatalf("Expected %d violations, got %d: %+v", len(expected), len(violations), violations)
+	}
+	for i, v := range violations {
+		if v.Subject != expected[i].subject || v.Reason != expected[i].reason {
+			t.Errorf("Violation %d: expected %q (%s), got %q (%s)", i, expected[i].subject, expected[i].reason, v.Subject, v.Reason)
+		}
+	}
+	if got := violations[1].SignedOffBy; !reflect.DeepEqual(got, []string{"Bob <bob@example.com>"}) {
+		t.Errorf("Expected Bob's sign-off to be reported, got %q", got)
+	}
+}
+
+func TestGetLogsJSONSeparatorCollision(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	message := "Parse |||GITLOGSEP||| tokens\n\nFields like a|||GITLOGSEP|||b|||GITLOGSEP|||c must survive."
+	gitCommit(t, repoPath, message, "Ann |||GITLOGSEP||| Dev", author1Email, testTime(2023, 9, 1, 10, 0, 0), map[string]string{"a.txt": "a"})
+	gitCommit(t, repoPath, "Next", author2Name, author2Email, testTime(2023, 9, 2, 10, 0, 0), map[string]string{"b.txt": "b"})
+
+	jsonResult, err := gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{StartDate: PtrTime(testTime(2023, 8, 1, 0, 0, 0))})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var entries []expectedLogEntry
+	if err := json.Unmarshal([]byte(jsonResult), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal JSON result: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d: %+v", len(entries), entries)
+	}
+	if entries[0].Message != message || entries[0].AuthorName != "Ann |||GITLOGSEP||| Dev" || entries[0].AuthorEmail != author1Email {
+		t.Errorf("Fields containing the old separator were corrupted: %+v", entries[0])
+	}
+	if entries[1].Message != "Next" || entries[1].AuthorEmail != author2Email {
+		t.Errorf("Following commit was misparsed: %+v", entries[1])
+	}
+}
+
+func TestGetLogsJSONRedactEmails(t *testing.T) {
+	repoPath := setupGitRepo(t)
+	message := "Add feature\n\nSigned-off-by: Alice Alpha <alice@example.com>\nCo-authored-by: Bob <bob.b@corp.example.org>"
+	gitCommit(t, repoPath, message, author1Name, author1Email, testTime(2023, 9, 1, 10, 0, 0), map[string]string{"a.txt": "a"})
+
+	jsonResult, err := gitlogs.GetLogsJSON(repoPath, &gitlogs.Options{
+		StartDate:    PtrTime(testTime(2023, 8, 1, 0, 0, 0)),
+		RedactEmails: true,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var entries []expectedLogEntry
+	if err := json.Unmarshal([]byte(jsonResult), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal JSON result: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %+v", entries)
+	}
+	if entries[0].AuthorEmail != "a***@example.com" {
+		t.Errorf("Expected redacted author email, got %q", entries[0].AuthorEmail)
+	}
+	expectedMessage := "Add feature\n\nSigned-off-by: Alice Alpha <a***@example.com>\nCo-authored-by: Bob <b***@corp.example.org>"
+	if entries[0].Message != expectedMessage {
+		t.Errorf("Expected emails in the message to be redacted, got %q", entries[0].Message)
+	}
+
+	for email, expected := range map[string]string{"": "", "no-at-sign": "***", "@host": "***@host", "élodie@example.fr": "é***@example.fr"} {
+		if got := gitlogs.RedactEmail(email); got != expected {
+			t.Errorf("RedactEmail(%q) = %q, expected %q", email, got, expected)
+		}
+	}
+}
+
+// setupBenchmarkRepo returns a repository of 1000 commits touching two files each,
+// built with git fast-import.
+func setupBenchmarkRepo(b *testing.B) string {
+	b.Helper()
+	repoPath := setupGitRepo(b)
+	var stream strings.Builder
+	start := testTime(2023, 1, 1, 0, 0, 0).Unix()
+	for i := 0; i < 1000; i++ {
+		message := fmt.Sprintf("Commit %d\n\nBody of commit %d.\n", i, i)
+		fmt.Fprintf(&stream, "commit refs/heads/main\n")
+		fmt.Fprintf(&stream, "committer %s <%s> %d +0000\n", author1Name, author1Email, start+int64(i)*3600)
+		fmt.Fprintf(&stream, "data %d\n%s", len(message), message)
+		if i == 0 {
+			fmt.Fprintf(&stream, "from refs/heads/main^0\n")
+		}
+		for _, file := range []string{fmt.Sprintf("dir%d/file%d.go", i%10, i), "CHANGELOG.md"} {
+			content := fmt.Sprintf("content %d\n", i)
+			fmt.Fprintf(&stream, "M 100644 inline %s\ndata %d\n%s", file, len(content), content)
+		}
+		stream.WriteString("\n")
+	}
+	cmd := exec.Command("git", "fast-import", "--quiet")
+	cmd.Dir = repoPath
+	cmd.Stdin = strings.NewReader(stream.String())
+	if output, err := cmd.CombinedOutput(); err != nil {
+		b.Fatalf("git fast-import failed: %v\nOutput: %s", err, output)
+	}
+	return repoPath
+}
+
+// BenchmarkGetLogsJSON measures GetLogsJSON on the repository of setupBenchmarkRepo.
+// Compare with BenchmarkGitShowPerCommit.
+func BenchmarkGetLogsJSON(b *testing.B) {
+	repoPath := setupBenchmarkRepo(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := gitlogs.GetLogsJSON(repoPath, nil); err != nil {
+			b.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+}
+
+// BenchmarkGitShowPerCommit is the baseline for BenchmarkGetLogsJSON: it reads the same
+// commits the way GetLogsJSON did before it streamed --name-only output, with one git log
+// for the commit details and one git show per commit for its modified files.
+func BenchmarkGitShowPerCommit(b *testing.B) {
+	repoPath := setupBenchmarkRepo(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cmd := exec.Command("git", "log", "--all", "--no-merges", "--reverse", "--format=%H%x00%aN%x00%aE%x00%aI%x00%D%x00%B%x00")
+		cmd.Dir = repoPath
+		output, err := cmd.Output()
+		if err != nil {
+			b.Fatalf("git log failed: %v", err)
+		}
+		var entries []gitlogs.LogEntry
+		fields := strings.Split(string(output), "\x00")
+		for j := 0; j+6 <= len(fields); j += 6 {
+			hash := strings.TrimLeft(fields[j], "\n")
+			show := exec.Command("git", "show", hash, "--pretty=", "--name-only", "--no-merges", "--")
+			show.Dir = repoPath
+			files, err := show.Output()
+			if err != nil {
+				b.Fatalf("git show failed: %v", err)
+			}
+			if len(strings.TrimSpace(string(files))) == 0 {
+				continue // Commits without files were left out, as GetLogsJSON does
+			}
+			entries = append(entries, gitlogs.LogEntry{
+				Hash:          hash,
+				AuthorName:    fields[j+1],
+				AuthorEmail:   fields[j+2],
+				Message:       strings.TrimSpace(fields[j+5]),
+				ModifiedFiles: strings.Fields(string(files)),
+			})
+		}
+		if len(entries) != 1000 {
+			b.Fatalf("Expected 1000 commits, got %d", len(entries))
+		}
+		if _, err := json.MarshalIndent(entries, "", "  "); err != nil {
+			b.Fatalf("Failed to marshal: %v", err)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package gitlogs
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// GetLogsJSONGzip writes the same JSON produced by GetLogsJSON to w as a gzip stream.
+// Decompressing the output yields exactly the GetLogsJSON result. The JSON is compressed
+// as it is encoded, one commit at a time, so it is never held in memory as a whole.
+// Nothing is written to w if the logs cannot be read.
+func GetLogsJSONGzip(repoPath string, opts *Options, w io.Writer) error {
+	zw := gzip.NewWriter(w) // Writes nothing until the first Write or Close
+	if err := writeLogsJSON(repoPath, opts, zw); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish compressed log JSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package gitlogs
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FileHotspot is a file ranked by how often it changed.
+type FileHotspot struct {
+	Path    string `json:"path"`
+	Changes int    `json:"changes"` // Commits that modified the file
+	Authors int    `json:"authors"` // Distinct author emails among those commits, ignoring case
+}
+
+// Hotspots returns the topN files changed by the most commits selected by opts, from the
+// same per-commit file lists as GetLogsJSON (so Paths, ExcludePaths and the other filters
+// apply). Files changed often by many authors tend to be maintenance risks. A topN of
+// zero or less returns every file. See RankHotspots for the ordering.
+func Hotspots(repoPath string, opts *Options, topN int) ([]FileHotspot, error) {
+	logsJSON, err := GetLogsJSON(repoPath, opts)
+	if err != nil {
+		return nil, err
+	}
+	var entries []LogEntry
+	if err := json.Unmarshal([]byte(logsJSON), &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse git logs: %w", err)
+	}
+	return RankHotspots(entries, topN), nil
+}
+
+// RankHotspots aggregates the ModifiedFiles of entries by path and returns the topN
+// files, most changes first, then most authors, then by path. A topN of zero or less
+// returns every file.
+func RankHotspots(entries []LogEntry, topN int) []FileHotspot {
+	changes := make(map[string]int)
+	authors := make(map[string]map[string]struct{})
+	for _, entry := range entries {
+		email := strings.ToLower(entry.AuthorEmail)
+		for _, path := range entry.ModifiedFiles {
+			changes[path]++
+			if authors[path] == nil {
+				authors[path] = make(map[string]struct{})
+			}
+			authors[path][email] = struct{}{}
+		}
+	}
+
+	hotspots := make([]FileHotspot, 0, len(changes))
+	for path, n := range changes {
+		hotspots = append(hotspots, FileHotspot{Path: path, Changes: n, Authors: len(authors[path])})
+	}
+	sort.Slice(hotspots, func(i, j int) bool {
+		a, b := hotspots[i], hotspots[j]
+		if a.Changes != b.Changes {
+			return a.Changes > b.Changes
+		}
+		if a.Authors != b.Authors {
+			return a.Authors > b.Authors
+		}
+		return a.Path < b.Path
+	})
+	if topN > 0 && len(hotspots) > topN {
+		hotspots = hotspots[:topN]
+	}
+	return hotspots
+}
@@ -0,0 +1,110 @@
+package gitlogs
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ErrLanguageUndetected is returned by DetectCommitLanguage when no commit subject
+// gives enough evidence for any known language.
+var ErrLanguageUndetected = errors.New("could not detect the language of commit messages")
+
+// languageSampleSize caps the number of commit subjects DetectCommitLanguage reads.
+const languageSampleSize = 500
+
+// languageWords lists, per ISO 639-1 code, frequent function words and verbs typical of
+// commit subjects in that language. Words shared by several languages ("de", "a", "en")
+// count for each of them; the others break the tie.
+var languageWords = map[string][]string{
+	"en": {"the", "and", "for", "to", "of", "in", "with", "from", "on", "when", "not", "is",
+		"add", "added", "adds", "fix", "fixed", "fixes", "update", "updated", "remove",
+		"removed", "use", "make", "allow", "support", "change", "improve", "refactor", "bump"},
+	"es": {"el", "la", "los", "las", "de", "del", "y", "para", "con", "en", "por", "que",
+		"se", "una", "un", "al", "agrega", "agregar", "añade", "añadir", "corrige", "corregir",
+		"arregla", "actualiza", "actualizar", "elimina", "eliminar", "cambia", "mejora",
+		"nuevo", "nueva", "ajusta", "ajustes"},
+	"pt": {"o", "os", "as", "de", "do", "da", "dos", "das", "e", "para", "com", "em", "no",
+		"na", "por", "que", "um", "uma", "adiciona", "adicionar", "corrige", "corrigir",
+		"atualiza", "atualizar", "remove", "remover", "ajusta", "melhoria", "não"},
+	"fr": {"le", "la", "les", "de", "du", "des", "et", "pour", "avec", "dans", "sur", "un",
+		"une", "au", "aux", "ajout", "ajoute", "ajouter", "corrige", "corriger", "correction",
+		"mise", "jour", "supprime", "supprimer", "modifie", "amélioration", "pas"},
+	"de": {"der", "die", "das", "den", "dem", "und", "für", "mit", "von", "im", "auf", "zu",
+		"nicht", "ein", "eine", "hinzufügen", "hinzugefügt", "behebt", "behoben", "fehler",
+		"aktualisiert", "entfernt", "geändert", "verbessert"},
+	"it": {"il", "lo", "la", "gli", "le", "di", "del", "della", "e", "per", "con", "in",
+		"un", "una", "aggiunge", "aggiunto", "aggiunta", "corregge", "corretto", "aggiorna",
+		"aggiornato", "rimuove", "rimosso", "modifica", "migliora"},
+	"nl": {"de", "het", "een", "en", "voor", "met", "van", "in", "op", "niet", "toegevoegd",
+		"toevoegen", "opgelost", "bijgewerkt", "verwijderd", "aangepast", "verbeterd"},
+}
+
+// languageIndex maps each word of languageWords to the languages it belongs to.
+var languageIndex = func() map[string][]string {
+	index := make(map[string][]string)
+	for lang, words := range languageWords {
+		for _, w := range words {
+			index[w] = append(index[w], lang)
+		}
+	}
+	return index
+}()
+
+// DetectCommitLanguage returns the ISO 639-1 code ("en", "es", "pt", "fr", "de", "it"
+// or "nl") of the language most commit subjects in entries are written in. It samples
+// up to 500 subjects spread evenly over entries; each subject votes for the language
+// whose common words it contains most, and subjects without a clear winner (e.g. only
+// identifiers or version numbers) are ignored. Ties between languages go to English.
+// ErrLanguageUndetected is returned when no subject votes.
+func DetectCommitLanguage(entries []LogEntry) (string, error) {
+	votes := make(map[string]int)
+	step := 1
+	if len(entries) > languageSampleSize {
+		step = len(entries) / languageSampleSize
+	}
+	for i := 0; i < len(entries); i += step {
+		if lang := subjectLanguage(entries[i].Message); lang != "" {
+			votes[lang]++
+		}
+	}
+
+	best, bestVotes := "", 0
+	for lang, n := range votes {
+		if n > bestVotes || (n == bestVotes && (lang == "en" || (best != "en" && lang < best))) {
+			best, bestVotes = lang, n
+		}
+	}
+	if best == "" {
+		return "", ErrLanguageUndetected
+	}
+	return best, nil
+}
+
+// subjectLanguage returns the language whose words appear most often in the first line
+// of message, or "" when none does or several tie.
+func subjectLanguage(message string) string {
+	subject, _, _ := strings.Cut(message, "\n")
+	words := strings.FieldsFunc(strings.ToLower(subject), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	scores := make(map[string]int)
+	for _, w := range words {
+		for _, lang := range languageIndex[w] {
+			scores[lang]++
+		}
+	}
+	best, bestScore, tied := "", 0, false
+	for lang, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = lang, score, false
+		case score == bestScore:
+			tied = true
+		}
+	}
+	if tied {
+		return ""
+	}
+	return best
+}
@@ -0,0 +1,219 @@
+package gitlogs
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Stone-IT-Cloud/reporting/internal/gitexec"
+)
+
+// squashPRPattern matches the " (#123)" suffix GitHub appends to the subject of squash
+// and rebase merges.
+var squashPRPattern = regexp.MustCompile(`\(#(\d+)\)\s*$`)
+
+// LinkCommitsToPullRequests maps commit hashes to the number of the GitHub pull request
+// that brought them in, using only the local history:
+//   - a "Merge pull request #N" merge commit links itself and every commit it merged
+//     (reachable from its second parent but not its first) to N;
+//   - a commit whose subject ends in "(#N)", as written by squash and rebase merges,
+//     is linked to N.
+//
+// A commit whose own subject carries "(#N)" is linked to N. Otherwise, when it was merged
+// by several pull requests (e.g. a branch merged into another branch first), the earliest
+// merge wins. Commits not linked to any pull request are absent from the map. The whole
+// history of all branches is scanned, with a single git log, so that commits are linked
+// even if their pull request was merged outside a date window; only Logger is honored
+// from opts.
+func LinkCommitsToPullRequests(repoPath string, opts *Options) (map[string]int, error) {
+	absRepoPath, err := gitexec.ValidateRepoPath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	return pullRequestLinks(absRepoPath, opts)
+}
+
+// pullRequestLinks implements LinkCommitsToPullRequests for a validated repository path.
+// It reads the commit graph once and finds the commits of each pull request merge in
+// memory with mergedCommits.
+func pullRequestLinks(absRepoPath string, opts *Options) (map[string]int, error) {
+	logger := opts.logger()
+	links := make(map[string]int)
+	graph := newCommitGraph()
+
+	type prMerge struct {
+		node   int
+		number int
+	}
+	var merges []prMerge
+
+	// Parents before children, so that generations can be computed as commits are read
+	// and the earliest pull request claims a commit.
+	args := []string{"log", "--all", "--topo-order", "--reverse", "--encoding=UTF-8", "--format=%H%x00%P%x00%s"}
+	sawOutput, stderrStr, err := gitexec.Stream(absRepoPath, args, '\n', func(line string) {
+		if line == "" {
+			return
+		}
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) != 3 {
+			logger.Warn("skipping malformed git log line", "line", line)
+			return
+		}
+		hash, parents, subject := parts[0], strings.Fields(parts[1]), parts[2]
+		node := graph.add(hash, parents)
+		if m := squashPRPattern.FindStringSubmatch(subject); m != nil {
+			if number, err := strconv.Atoi(m[1]); err == nil {
+				links[hash] = number // The commit's own subject takes precedence
+				return
+			}
+		}
+		if pr := parseMergedPR(subject); pr != nil && len(parents) >= 2 {
+			merges = append(merges, prMerge{node: node, number: pr.Number})
+		}
+	})
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && (strings.Contains(stderrStr, "does not have any commits") || !sawOutput) {
+			return links, nil // Empty repository
+		}
+		return nil, fmt.Errorf("git log command failed: %w\nstderr: %s", err, stderrStr)
+	}
+
+	for _, merge := range merges {
+		if _, linked := links[graph.hashes[merge.node]]; !linked {
+			links[graph.hashes[merge.node]] = merge.number
+		}
+		for _, node := range graph.mergedCommits(merge.node) {
+			if _, linked := links[graph.hashes[node]]; !linked {
+				links[graph.hashes[node]] = merge.number
+			}
+		}
+	}
+	return links, nil
+}
+
+// commitGraph is the commit history read by pullRequestLinks. Commits are numbered in the
+// order they were added, which must list parents before children.
+type commitGraph struct {
+	index       map[string]int
+	hashes      []string
+	parents     [][]int // Parents present in the graph, first parent first
+	generations []int   // 1 for root commits, else one more than the highest parent
+}
+
+func newCommitGraph() *commitGraph {
+	return &commitGraph{index: make(map[string]int)}
+}
+
+// add records a commit whose parents were added before it and returns its number.
+// Parents missing from the graph, as in shallow clones, are ignored.
+func (g *commitGraph) add(hash string, parentHashes []string) int {
+	node := len(g.hashes)
+	g.index[hash] = node
+	g.hashes = append(g.hashes, hash)
+	var parents []int
+	generation := 1
+	for _, parentHash := range parentHashes {
+		parent, ok := g.index[parentHash]
+		if !ok {
+			parents = append(parents, -1) // Keeps the first parent in position 0
+			continue
+		}
+		parents = append(parents, parent)
+		if g.generations[parent] >= generation {
+			generation = g.generations[parent] + 1
+		}
+	}
+	g.parents = append(g.parents, parents)
+	g.generations = append(g.generations, generation)
+	return node
+}
+
+// Paint flags used by mergedCommits.
+const (
+	paintOurs   = 1 << iota // Reachable from the merge's first parent
+	paintTheirs             // Reachable from one of the merged parents
+)
+
+// mergedCommits returns the commits a merge brought in: those reachable from its other
+// parents but not from its first parent, like git rev-list P2 ^P1. Commits are visited
+// from the highest generation down, so a commit's flags are final when it is visited,
+// and the walk stops once no commit reachable only from the merged side is left.
+func (g *commitGraph) mergedCommits(merge int) []int {
+	parents := g.parents[merge]
+	if len(parents) < 2 {
+		return nil
+	}
+	flags := make(map[int]int)
+	queue := &generationQueue{graph: g}
+	theirsOnly := 0 // Queued commits currently painted theirs only
+	paint := func(node, flag int) {
+		if node < 0 {
+			return
+		}
+		old := flags[node]
+		if old|flag == old {
+			return
+		}
+		flags[node] = old | flag
+		if _, queued := queue.queued[node]; !queued {
+			if queue.queued == nil {
+				queue.queued = make(map[int]struct{})
+			}
+			queue.queued[node] = struct{}{}
+			heap.Push(queue, node)
+			if flags[node] == paintTheirs {
+				theirsOnly++
+			}
+			return
+		}
+		if old == paintTheirs {
+			theirsOnly-- // Now reachable from the first parent too
+		}
+	}
+	paint(parents[0], paintOurs)
+	for _, parent := range parents[1:] {
+		paint(parent, paintTheirs)
+	}
+
+	var merged []int
+	for theirsOnly > 0 {
+		node := heap.Pop(queue).(int)
+		delete(queue.queued, node)
+		flag := flags[node]
+		if flag == paintTheirs {
+			theirsOnly--
+			merged = append(merged, node)
+		}
+		for _, parent := range g.parents[node] {
+			paint(parent, flag)
+		}
+	}
+	return merged
+}
+
+// generationQueue is a container/heap of commit numbers, highest generation first.
+type generationQueue struct {
+	graph  *commitGraph
+	nodes  []int
+	queued map[int]struct{}
+}
+
+func (q *generationQueue) Len() int { return len(q.nodes) }
+func (q *generationQueue) Less(i, j int) bool {
+	return q.graph.generations[q.nodes[i]] > q.graph.generations[q.nodes[j]]
+}
+func (q *generationQueue) Swap(i, j int)      { q.nodes[i], q.nodes[j] = q.nodes[j], q.nodes[i] }
+func (q *generationQueue) Push(x interface{}) { q.nodes = append(q.nodes, x.(int)) }
+func (q *generationQueue) Pop() interface{} {
+	node := q.nodes[len(q.nodes)-1]
+	q.nodes = q.nodes[:len(q.nodes)-1]
+	return node
+}
@@ -0,0 +1,35 @@
+package gitlogs
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// emailPattern matches email addresses in free text, such as Signed-off-by and
+// Co-authored-by trailers.
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)
+
+// RedactEmail masks the local part of email, keeping its first character and the domain:
+// "alice@example.com" becomes "a***@example.com". The same input always gives the same
+// output, so redacted emails still tell authors apart in most cases. Values without an
+// "@" are masked entirely; an empty email stays empty.
+func RedactEmail(email string) string {
+	if email == "" {
+		return ""
+	}
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return "***"
+	}
+	if local == "" {
+		return "***@" + domain
+	}
+	_, size := utf8.DecodeRuneInString(local) // Keep a whole character, even if multi-byte
+	return local[:size] + "***@" + domain
+}
+
+// RedactEmailsIn masks, with RedactEmail, every email address found in text.
+func RedactEmailsIn(text string) string {
+	return emailPattern.ReplaceAllStringFunc(text, RedactEmail)
+}
@@ -0,0 +1,26 @@
+package gitlogs
+
+import (
+	"fmt"
+
+	"github.com/Stone-IT-Cloud/reporting/internal/gitexec"
+)
+
+// ErrUnknownRef is returned when a branch, tag or other revision given to git (e.g. via
+// ExtraArgs) does not exist in the repository. Use errors.As to retrieve the Ref.
+type ErrUnknownRef struct {
+	Ref string
+}
+
+func (e ErrUnknownRef) Error() string {
+	return fmt.Sprintf("reference '%s' not found in repository", e.Ref)
+}
+
+// unknownRefError returns an ErrUnknownRef when stderr reports an unresolvable revision,
+// or nil otherwise. For a range, the endpoint that does not resolve is reported.
+func unknownRefError(absRepoPath, stderr string) error {
+	if ref, ok := gitexec.UnknownRef(absRepoPath, stderr); ok {
+		return ErrUnknownRef{Ref: ref}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package gitlogs
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/Stone-IT-Cloud/reporting/internal/gitexec"
+)
+
+// RepositoryName returns a display name for the repository at repoPath. It prefers
+// "owner/repo" parsed from the URL of the "origin" remote (HTTPS, SSH and scp-like
+// forms are supported) and falls back to the repository directory's base name.
+func RepositoryName(repoPath string) (string, error) {
+	absRepoPath, err := gitexec.ValidateRepoPath(repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command("git", "remote", "get-url", "origin")
+	cmd.Dir = absRepoPath
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err == nil {
+		if name := nameFromRemoteURL(strings.TrimSpace(stdout.String())); name != "" {
+			return name, nil
+		}
+	}
+	return filepath.Base(absRepoPath), nil
+}
+
+// nameFromRemoteURL extracts "owner/repo" from a git remote URL such as
+// https://github.com/owner/repo.git or git@github.com:owner/repo.git.
+// It returns "" if the URL does not contain at least two path segments.
+func nameFromRemoteURL(remoteURL string) string {
+	trimmed := strings.TrimSuffix(strings.TrimSuffix(remoteURL, "/"), ".git")
+	if i := strings.Index(trimmed, "://"); i >= 0 {
+		trimmed = trimmed[i+3:]
+		if slash := strings.Index(trimmed, "/"); slash >= 0 {
+			trimmed = trimmed[slash+1:] // Drop the host
+		} else {
+			return ""
+		}
+	} else if colon := strings.Index(trimmed, ":"); colon >= 0 {
+		trimmed = trimmed[colon+1:] // scp-like syntax: user@host:owner/repo
+	}
+	segments := strings.Split(trimmed, "/")
+	if len(segments) < 2 || segments[len(segments)-2] == "" || segments[len(segments)-1] == "" {
+		return ""
+	}
+	return segments[len(segments)-2] + "/" + segments[len(segments)-1]
+}
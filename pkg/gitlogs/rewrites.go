@@ -0,0 +1,115 @@
+package gitlogs
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Stone-IT-Cloud/reporting/internal/gitexec"
+)
+
+// HistoryRewrite describes a reflog entry recording that a fetch force-updated a ref,
+// i.e. the upstream history was rewritten by a force-push.
+type HistoryRewrite struct {
+	Ref    string    // Ref whose history was rewritten, e.g. "refs/heads/main"
+	Hash   string    // Commit the ref pointed to after the rewrite
+	Time   time.Time // When the reflog entry was recorded (UTC)
+	Action string    // Reflog subject, e.g. "fetch: forced-update"
+}
+
+// rewriteMarkers are reflog subject fragments that signal rewritten history. Local
+// resets, rebases and amends are left out: they are routine on unpushed work and only
+// change what a report sees once they are force-pushed, which fetch records as
+// "forced-update" in the clones that notice it.
+var rewriteMarkers = []string{
+	"forced-update",
+}
+
+// DetectHistoryRewrites performs a best-effort scan of the repository's reflog for
+// entries that rewrote history within the Options date window. The reflog is local
+// to the clone, so the result is advisory: an empty slice does not prove that no
+// force-push happened upstream. Only StartDate, EndDate and Logger are honored.
+func DetectHistoryRewrites(repoPath string, opts *Options) ([]HistoryRewrite, error) {
+	absRepoPath, err := gitexec.ValidateRepoPath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	logger := opts.logger()
+
+	// %gD with --date=unix renders the selector as "<ref>@{<unix-seconds>}".
+	cmd := exec.Command("git", "reflog", "--all", "--date=unix", "--format=%H%x00%gD%x00%gs")
+	cmd.Dir = absRepoPath
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if stdout.Len() == 0 {
+			return []HistoryRewrite{}, nil // No reflog (e.g. fresh clone or empty repo)
+		}
+		return nil, fmt.Errorf("git reflog command failed: %w\nstderr: %s", err, stderr.String())
+	}
+
+	rewrites := make([]HistoryRewrite, 0)
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) != 3 {
+			logger.Warn("skipping malformed reflog line", "line", line)
+			continue
+		}
+		hash, selector, subject := parts[0], parts[1], parts[2]
+
+		if !isRewriteSubject(subject) {
+			continue
+		}
+
+		ref, when, ok := parseReflogSelector(selector)
+		if !ok {
+			logger.Warn("skipping reflog entry with unparseable selector", "selector", selector)
+			continue
+		}
+		if ref == "HEAD" {
+			continue // HEAD mirrors the branch reflogs; avoid reporting each rewrite twice
+		}
+		if opts.StartDate != nil && when.Before(*opts.StartDate) {
+			continue
+		}
+		if end := opts.endDate(); end != nil && when.After(*end) {
+			continue
+		}
+
+		rewrites = append(rewrites, HistoryRewrite{Ref: ref, Hash: hash, Time: when.UTC(), Action: subject})
+	}
+	return rewrites, nil
+}
+
+// isRewriteSubject reports whether a reflog subject matches one of the rewriteMarkers.
+func isRewriteSubject(subject string) bool {
+	for _, marker := range rewriteMarkers {
+		if strings.Contains(subject, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseReflogSelector splits "<ref>@{<unix-seconds>}" into its ref and time parts.
+func parseReflogSelector(selector string) (string, time.Time, bool) {
+	at := strings.LastIndex(selector, "@{")
+	if at < 0 || !strings.HasSuffix(selector, "}") {
+		return "", time.Time{}, false
+	}
+	seconds, err := strconv.ParseInt(selector[at+2:len(selector)-1], 10, 64)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return selector[:at], time.Unix(seconds, 0), true
+}
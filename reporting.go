@@ -10,16 +10,53 @@ import (
 	"github.com/Stone-IT-Cloud/reporting/pkg/gitlogs"             // Correct path
 )
 
+// AIReportOptions holds optional settings for GenerateAIActivityReport.
+type AIReportOptions struct {
+	// Paths scopes the report to commits touching these paths (git pathspecs, e.g. "web/").
+	Paths []string
+	// ExcludePaths leaves these paths out of the report.
+	ExcludePaths []string
+	// Authors scopes the report to commits by these authors (names or emails, ignoring case).
+	Authors []string
+	// ExcludeAuthors leaves commits by these authors, e.g. bots, out of the report.
+	ExcludeAuthors []string
+	// IncludeMergeCommits keeps merge commits, e.g. for their pull request titles.
+	IncludeMergeCommits bool
+	// RedactEmails masks email addresses in the logs before they reach the model.
+	RedactEmails bool
+	// SkipPullRequestLinks leaves out the pull request each commit was merged with,
+	// which is otherwise added so the model can group accomplishments by pull request.
+	SkipPullRequestLinks bool
+}
+
 // GenerateAIActivityReport orchestates the process of getting logs and generating the AI report.
 // This is the main function exposed by the 'reporting' package for this task.
+// It reports on the whole repository; see GenerateAIActivityReportWithOptions to narrow it.
 func GenerateAIActivityReport(ctx context.Context, repoPath, configPath string, startDate, endDate *time.Time, reportPath string) error {
+	return GenerateAIActivityReportWithOptions(ctx, repoPath, configPath, startDate, endDate, reportPath, nil)
+}
+
+// GenerateAIActivityReportWithOptions is GenerateAIActivityReport with optional settings.
+// opts may be nil to report on the whole repository.
+func GenerateAIActivityReportWithOptions(ctx context.Context, repoPath, configPath string, startDate, endDate *time.Time, reportPath string, opts *AIReportOptions) error {
+	if opts == nil {
+		opts = &AIReportOptions{}
+	}
 	fmt.Println("Orchestration: Starting AI Activity Report Generation")
 
 	// Step 1: Get Git Logs as JSON using the gitlogs sub-package
 	fmt.Println("Orchestration: Fetching git logs...")
 	logOpts := &gitlogs.Options{
-		StartDate: startDate,
-		EndDate:   endDate,
+		StartDate:           startDate,
+		EndDate:             endDate,
+		Paths:               opts.Paths,
+		ExcludePaths:        opts.ExcludePaths,
+		Authors:             opts.Authors,
+		ExcludeAuthors:      opts.ExcludeAuthors,
+		IncludeMergeCommits: opts.IncludeMergeCommits,
+		RedactEmails:        opts.RedactEmails,
+		// Lets the model group accomplishments by pull request instead of by commit.
+		LinkPullRequests: !opts.SkipPullRequestLinks,
 	}
 	gitLogsJSON, err := gitlogs.GetLogsJSON(repoPath, logOpts)
 	if err != nil {
@@ -27,12 +64,20 @@ func GenerateAIActivityReport(ctx context.Context, repoPath, configPath string,
 	}
 	fmt.Println("Orchestration: Git logs fetched successfully.")
 
+	// Advisory only: rewritten history can explain commit counts changing between runs.
+	if rewrites, err := gitlogs.DetectHistoryRewrites(repoPath, logOpts); err == nil {
+		for _, rw := range rewrites {
+			fmt.Printf("Orchestration: warning: history of %s was rewritten at %s (%s)\n", rw.Ref, rw.Time.Format(time.RFC3339), rw.Action)
+		}
+	}
+
 	// Step 2: Generate the report using the activityreport sub-package
 	fmt.Println("Orchestration: Generating AI report...")
-	err = activityreport.GenerateReport(ctx, gitLogsJSON, configPath, repoPath)
+	result, err := activityreport.GenerateReport(ctx, gitLogsJSON, configPath, reportPath, repoPath)
 	if err != nil {
 		return fmt.Errorf("orchestration failed during AI report generation: %w", err)
 	}
+	fmt.Printf("Orchestration: %d of %d commits sent to the model.\n", result.SentCommits, result.TotalCommits)
 
 	fmt.Println("Orchestration: AI Activity Report Generation Finished Successfully.")
 	return nil